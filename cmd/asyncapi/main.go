@@ -0,0 +1,32 @@
+// Command asyncapi writes the AsyncAPI document of the real-time events.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"playmatch/backend/internal/asyncapi"
+	"playmatch/backend/internal/handler"
+	"playmatch/backend/internal/hub"
+)
+
+func main() {
+	output := flag.String("o", "docs/asyncapi.json", "file to write the document to")
+	flag.Parse()
+
+	document, err := json.MarshalIndent(asyncapi.Document(hub.EventVersion, handler.EventCatalog), "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode the AsyncAPI document: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*output), 0o755); err != nil {
+		log.Fatalf("Failed to create the output directory: %v", err)
+	}
+	if err := os.WriteFile(*output, append(document, '\n'), 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *output, err)
+	}
+	log.Printf("AsyncAPI document written to %s", *output)
+}
@@ -9,6 +9,7 @@ import (
 	"playmatch/backend/internal/config"
 	"playmatch/backend/internal/database"
 	"playmatch/backend/internal/handler"
+	"playmatch/backend/internal/hub"
 
 	"github.com/gin-gonic/gin"
 
@@ -35,6 +36,32 @@ func main() {
 	// Connect to the database
 	database.Connect(config.AppConfig.DatabaseURL)
 
+	hub.GlobalHub.Configure(hub.Options{
+		ClientBuffer:      config.AppConfig.HubClientBuffer,
+		SlowClientTimeout: config.AppConfig.HubSlowClientTimeout,
+		HeartbeatInterval: config.AppConfig.HubHeartbeatInterval,
+	})
+
+	// Share hub events between server instances
+	if config.AppConfig.HubBackend == "postgres" {
+		sqlDB, err := database.DB.DB()
+		if err != nil {
+			log.Fatalf("Failed to get database handle: %v", err)
+		}
+		backend, err := hub.NewPostgresBackend(config.AppConfig.DatabaseURL, sqlDB)
+		if err != nil {
+			log.Fatalf("Failed to start postgres hub backend: %v", err)
+		}
+		hub.GlobalHub.UseBackend(backend)
+	}
+
+	// Background workers
+	handler.StartWaitlistWorker()
+	handler.StartMatchmakingWorker()
+	handler.StartReputationWorker()
+	handler.StartLeaderboardWorker()
+	handler.StartPresenceWorker()
+
 	router := gin.Default()
 
 	// Swagger route
@@ -49,7 +76,12 @@ func main() {
 
 	// API v1 routes
 	apiV1 := router.Group("/api/v1")
+	// Presence of authenticated users is derived from their requests
+	apiV1.Use(handler.TrackActivity())
 	{
+		// Real-time event schema
+		apiV1.GET("/events/schema", handler.GetEventSchema)
+
 		// Auth routes
 		authRoutes := apiV1.Group("/auth")
 		{
@@ -63,6 +95,8 @@ func main() {
 		        		{
 		        			userRoutes.GET("", handler.SearchUsers) // Must be before /:id
 		        			userRoutes.GET("/:id", handler.GetUserByID)
+		        			userRoutes.GET("/:id/played-with", handler.GetPlayedWith)
+		        			userRoutes.GET("/:id/ratings", handler.GetUserRatings)
 		        
 		        			// Protected user routes
 		        			protectedUserRoutes := userRoutes.Group("")
@@ -70,13 +104,40 @@ func main() {
 		        			{
 		        				protectedUserRoutes.GET("/me", handler.GetMe)
 		        				protectedUserRoutes.GET("/me/relations", handler.GetRelations)
+		        				protectedUserRoutes.GET("/me/events", handler.SubscribeToUserEvents)
+		        				protectedUserRoutes.GET("/me/history", handler.GetMyHistory)
+		        				protectedUserRoutes.POST("/me/history/:sessionID/rehost", handler.RehostSession)
+		        				protectedUserRoutes.GET("/me/history/:sessionID/feedback", handler.GetSessionFeedback)
+		        				protectedUserRoutes.POST("/me/history/:sessionID/endorsements", handler.EndorseTeammate)
+		        				protectedUserRoutes.POST("/me/history/:sessionID/reports", handler.ReportTeammate)
+		        				protectedUserRoutes.GET("/me/invites", handler.GetMyInvites)
+		        				protectedUserRoutes.POST("/me/invites/:inviteID/accept", handler.AcceptInvite)
+		        				protectedUserRoutes.POST("/me/invites/:inviteID/decline", handler.DeclineInvite)
+		        				protectedUserRoutes.GET("/me/groups", handler.GetMyGroups)
+		        				protectedUserRoutes.GET("/me/reputation", handler.GetMyReputation)
+		        				protectedUserRoutes.GET("/me/ranks", handler.GetMyRanks)
+		        				protectedUserRoutes.GET("/me/mentions", handler.GetMyMentions)
+		        				protectedUserRoutes.POST("/me/mentions/read", handler.MarkMentionsRead)
+		        				protectedUserRoutes.GET("/me/notifications", handler.GetMyNotifications)
+		        				protectedUserRoutes.POST("/me/notifications/read", handler.MarkNotificationsRead)
+		        				protectedUserRoutes.PUT("/me/dm-policy", handler.UpdateDMPolicy)
+		        				protectedUserRoutes.PUT("/me/presence-visibility", handler.UpdatePresenceVisibility)
+		        
+		        				// Lobby template routes
+		        				protectedUserRoutes.GET("/me/lobby-templates", handler.GetMyLobbyTemplates)
+		        				protectedUserRoutes.POST("/me/lobby-templates", handler.CreateLobbyTemplate)
+		        				protectedUserRoutes.PUT("/me/lobby-templates/:templateID", handler.UpdateLobbyTemplate)
+		        				protectedUserRoutes.DELETE("/me/lobby-templates/:templateID", handler.DeleteLobbyTemplate)
 		        				protectedUserRoutes.GET("/:id/relations", handler.GetUserRelationsByID)
+		        				protectedUserRoutes.PUT("/:id/ratings/:scaleID", handler.RateUser)
 		        
 		        				// Friendship routes
 		        				protectedUserRoutes.POST("/:id/request", handler.SendRequest)
 		        				protectedUserRoutes.POST("/:id/accept", handler.AcceptRequest)
 		        				protectedUserRoutes.POST("/:id/decline", handler.DeclineRequest)
 		        				protectedUserRoutes.POST("/:id/remove", handler.RemoveRelation)
+		        				protectedUserRoutes.POST("/:id/block", handler.BlockUser)
+		        				protectedUserRoutes.POST("/:id/unblock", handler.UnblockUser)
 		        			}
 		        		}
 		        
@@ -124,18 +185,38 @@ func main() {
 		        						meLobbyRoutes.POST("/leave", handler.LeaveLobby)
 		        
 		        						meLobbyRoutes.DELETE("/members/:userID", handler.KickMember)
+
+										meLobbyRoutes.POST("/host", handler.TransferHost)
+										meLobbyRoutes.POST("/co-hosts/:userID", handler.PromoteCoHost)
+										meLobbyRoutes.DELETE("/co-hosts/:userID", handler.DemoteCoHost)
+										meLobbyRoutes.GET("/bans", handler.GetLobbyBans)
+										meLobbyRoutes.DELETE("/bans/:userID", handler.UnbanUser)
+										meLobbyRoutes.POST("/invites", handler.InviteToLobby)
 		        
 		        		
 		        
 		        						// Chat and Events
 		        
 		        						meLobbyRoutes.GET("/events", handler.SubscribeToLobbyEvents)
+		        						meLobbyRoutes.GET("/ws", handler.SubscribeToLobbyWebSocket)
 		        
 		        						meLobbyRoutes.POST("/messages", handler.PostMessage)
 		        
 		        						meLobbyRoutes.GET("/messages", handler.GetMessages)
 
+										meLobbyRoutes.PUT("/messages/:messageID", handler.EditLobbyMessage)
+										meLobbyRoutes.DELETE("/messages/:messageID", handler.DeleteLobbyMessage)
+										meLobbyRoutes.GET("/messages/:messageID/edits", handler.GetLobbyMessageEdits)
+										meLobbyRoutes.POST("/messages/:messageID/pin", handler.PinLobbyMessage)
+										meLobbyRoutes.DELETE("/messages/:messageID/pin", handler.UnpinLobbyMessage)
+										meLobbyRoutes.GET("/pins", handler.GetPinnedMessages)
+										meLobbyRoutes.POST("/messages/:messageID/reactions", handler.AddLobbyMessageReaction)
+										meLobbyRoutes.DELETE("/messages/:messageID/reactions/:emoji", handler.RemoveLobbyMessageReaction)
+
 										meLobbyRoutes.POST("/typing", handler.PostUserTyping)
+										meLobbyRoutes.PUT("/ready", handler.SetLobbyReady)
+										meLobbyRoutes.PUT("/slow-mode", handler.SetLobbySlowMode)
+										meLobbyRoutes.PUT("/announcement", handler.SetLobbyAnnouncement)
 		        
 		        					}
 		        
@@ -152,10 +233,95 @@ func main() {
 		        						protectedLobbyRoutes.POST("", handler.CreateLobby)
 		        
 		        						protectedLobbyRoutes.POST("/:id/join", handler.JoinLobby)
+
+										protectedLobbyRoutes.POST("/:id/waitlist", handler.JoinWaitlist)
+										protectedLobbyRoutes.GET("/:id/waitlist", handler.GetWaitlistEntry)
+										protectedLobbyRoutes.DELETE("/:id/waitlist", handler.LeaveWaitlist)
+										protectedLobbyRoutes.POST("/:id/waitlist/accept", handler.AcceptWaitlistOffer)
+										protectedLobbyRoutes.POST("/from-template/:templateID", handler.CreateLobbyFromTemplate)
 		        
 		        					}
 		        
-		        				}		// Admin routes (protected by auth and admin check)
+		        				}
+
+		// Rating scale routes
+		apiV1.GET("/rating-scales", handler.GetRatingScales)
+
+		// Leaderboard routes
+		leaderboardRoutes := apiV1.Group("/leaderboards")
+		leaderboardRoutes.Use(auth.OptionalAuthMiddleware()) // Signed-in users also get their own entry
+		{
+			leaderboardRoutes.GET("/reputation", handler.GetReputationLeaderboard)
+			leaderboardRoutes.GET("/hosted-month", handler.GetHostedLeaderboard)
+			leaderboardRoutes.GET("/scales/:id", handler.GetScaleLeaderboard)
+			leaderboardRoutes.GET("/tags/:id", handler.GetTagLeaderboard)
+			leaderboardRoutes.GET("/games/:id", handler.GetGameLeaderboard)
+		}
+
+		// Group routes
+		groupRoutes := apiV1.Group("/groups")
+		groupRoutes.Use(auth.OptionalAuthMiddleware()) // Use optional auth for public group data
+		{
+			groupRoutes.GET("", handler.SearchGroups)
+			groupRoutes.GET("/:id", handler.GetGroupByID)
+
+			// Protected group routes
+			protectedGroupRoutes := groupRoutes.Group("")
+			protectedGroupRoutes.Use(auth.AuthMiddleware())
+			{
+				protectedGroupRoutes.POST("", handler.CreateGroup)
+				protectedGroupRoutes.PUT("/:id", handler.UpdateGroup)
+				protectedGroupRoutes.DELETE("/:id", handler.DeleteGroup)
+				protectedGroupRoutes.POST("/:id/join", handler.JoinGroup)
+				protectedGroupRoutes.POST("/:id/leave", handler.LeaveGroup)
+				protectedGroupRoutes.POST("/:id/members", handler.AddGroupMember)
+				protectedGroupRoutes.DELETE("/:id/members/:userID", handler.RemoveGroupMember)
+				protectedGroupRoutes.PUT("/:id/members/:userID/role", handler.SetGroupMemberRole)
+				protectedGroupRoutes.POST("/:id/favorite-games/:gameID", handler.ToggleGroupFavoriteGame)
+				protectedGroupRoutes.GET("/:id/messages", handler.GetGroupMessages)
+				protectedGroupRoutes.POST("/:id/messages", handler.PostGroupMessage)
+				protectedGroupRoutes.PUT("/:id/messages/:messageID", handler.EditGroupMessage)
+				protectedGroupRoutes.DELETE("/:id/messages/:messageID", handler.DeleteGroupMessage)
+				protectedGroupRoutes.GET("/:id/messages/:messageID/edits", handler.GetGroupMessageEdits)
+				protectedGroupRoutes.POST("/:id/messages/:messageID/reactions", handler.AddGroupMessageReaction)
+				protectedGroupRoutes.DELETE("/:id/messages/:messageID/reactions/:emoji", handler.RemoveGroupMessageReaction)
+				protectedGroupRoutes.POST("/:id/sessions", handler.CreateGroupSession)
+				protectedGroupRoutes.DELETE("/:id/sessions/:sessionID", handler.DeleteGroupSession)
+				protectedGroupRoutes.GET("/:id/lobbies", handler.GetGroupLobbies)
+				protectedGroupRoutes.POST("/:id/lobbies", handler.CreateGroupLobby)
+			}
+		}
+
+		// Direct message routes
+		conversationRoutes := apiV1.Group("/conversations")
+		conversationRoutes.Use(auth.AuthMiddleware())
+		{
+			conversationRoutes.GET("", handler.GetConversations)
+			conversationRoutes.POST("", handler.OpenConversation)
+			conversationRoutes.GET("/:id/messages", handler.GetConversationMessages)
+			conversationRoutes.POST("/:id/messages", handler.PostConversationMessage)
+			conversationRoutes.PUT("/:id/messages/:messageID", handler.EditConversationMessage)
+			conversationRoutes.DELETE("/:id/messages/:messageID", handler.DeleteConversationMessage)
+			conversationRoutes.POST("/:id/read", handler.MarkConversationRead)
+		}
+
+		// Report routes
+		reportRoutes := apiV1.Group("/reports")
+		reportRoutes.Use(auth.AuthMiddleware())
+		{
+			reportRoutes.POST("", handler.CreateReport)
+		}
+
+		// Matchmaking routes
+		matchmakingRoutes := apiV1.Group("/matchmaking")
+		matchmakingRoutes.Use(auth.AuthMiddleware())
+		{
+			matchmakingRoutes.POST("/queue", handler.EnqueueMatchmaking)
+			matchmakingRoutes.GET("/queue", handler.GetMatchmakingStatus)
+			matchmakingRoutes.DELETE("/queue", handler.LeaveMatchmaking)
+		}
+
+		// Admin routes (protected by auth and admin check)
 		adminRoutes := apiV1.Group("/admin")
 		adminRoutes.Use(auth.AuthMiddleware(), auth.AdminMiddleware())
 		{
@@ -168,6 +334,14 @@ func main() {
 				tags.DELETE("/:id", handler.DeleteTag)
 			}
 
+			// Rating scales CRUD
+			ratingScales := adminRoutes.Group("/rating-scales")
+			{
+				ratingScales.POST("", handler.CreateRatingScale)
+				ratingScales.PUT("/:id", handler.UpdateRatingScale)
+				ratingScales.DELETE("/:id", handler.DeleteRatingScale)
+			}
+
 			// Games CRUD (admin-only parts)
 			adminGameRoutes := adminRoutes.Group("/games")
 			{
@@ -175,6 +349,24 @@ func main() {
 				adminGameRoutes.PUT("/:id", handler.UpdateGame)
 				adminGameRoutes.DELETE("/:id", handler.DeleteGame)
 			}
+
+			adminRoutes.GET("/hub/stats", handler.GetHubStats)
+
+			// Chat word filter
+			adminRoutes.GET("/blocked-words", handler.GetBlockedWords)
+			adminRoutes.POST("/blocked-words", handler.CreateBlockedWord)
+			adminRoutes.DELETE("/blocked-words/:id", handler.DeleteBlockedWord)
+
+			// Moderation queue
+			adminReportRoutes := adminRoutes.Group("/reports")
+			{
+				adminReportRoutes.GET("", handler.GetReports)
+				adminReportRoutes.GET("/:id", handler.GetReport)
+				adminReportRoutes.POST("/:id/assign", handler.AssignReport)
+				adminReportRoutes.POST("/:id/resolve", handler.ResolveReport)
+				adminReportRoutes.POST("/:id/dismiss", handler.DismissReport)
+			}
+			adminRoutes.DELETE("/users/:id/ban", handler.LiftUserBan)
 		}
 	}
 
@@ -0,0 +1,205 @@
+// Package asyncapi describes the real-time events of the API as an AsyncAPI document.
+package asyncapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Channel is a stream events can be delivered on.
+type Channel string
+
+const (
+	ChannelLobby     Channel = "lobby"     // Lobby events, over SSE and WebSocket
+	ChannelUser      Channel = "user"      // Personal events of the current user
+	ChannelWebSocket Channel = "websocket" // Replies that only the lobby WebSocket sends
+)
+
+// channelAddresses maps channels to the endpoints that stream them, relative to the API base path.
+var channelAddresses = map[Channel][]string{
+	ChannelLobby:     {"/lobbies/me/events", "/lobbies/me/ws"},
+	ChannelUser:      {"/users/me/events"},
+	ChannelWebSocket: {"/lobbies/me/ws"},
+}
+
+var channelDescriptions = map[string]string{
+	"/lobbies/me/events": "Server-Sent Events of the current user's lobby. Lobby events are numbered and can be resumed with Last-Event-ID.",
+	"/lobbies/me/ws":     "WebSocket carrying the lobby events and the replies to client commands.",
+	"/users/me/events":   "Server-Sent Events addressed to the current user.",
+}
+
+// Message documents one event type.
+type Message struct {
+	Type     string
+	Channels []Channel
+	Summary  string
+	Payload  interface{} // A value of the payload type, usually its zero value
+}
+
+// Document builds the AsyncAPI 2.6 document describing the messages.
+func Document(version int, messages []Message) map[string]interface{} {
+	b := builder{schemas: make(map[string]interface{})}
+
+	componentMessages := make(map[string]interface{})
+	channelMessages := make(map[string][]interface{})
+	for _, message := range messages {
+		componentMessages[message.Type] = map[string]interface{}{
+			"name":    message.Type,
+			"summary": message.Summary,
+			"payload": envelopeSchema(message.Type, b.schema(reflect.TypeOf(message.Payload))),
+		}
+		ref := map[string]interface{}{"$ref": "#/components/messages/" + message.Type}
+		for _, channel := range message.Channels {
+			for _, address := range channelAddresses[channel] {
+				channelMessages[address] = append(channelMessages[address], ref)
+			}
+		}
+	}
+
+	channels := make(map[string]interface{})
+	for address, refs := range channelMessages {
+		channels[address] = map[string]interface{}{
+			"description": channelDescriptions[address],
+			"subscribe": map[string]interface{}{
+				"operationId": operationID(address),
+				"message":     map[string]interface{}{"oneOf": refs},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"asyncapi": "2.6.0",
+		"info": map[string]interface{}{
+			"title":       "Playmatch real-time events",
+			"version":     strconv.Itoa(version),
+			"description": "Events delivered over the Playmatch event streams. Every event is wrapped in the same envelope; `type` selects the payload.",
+		},
+		"defaultContentType": "application/json",
+		"channels":           channels,
+		"components": map[string]interface{}{
+			"messages": componentMessages,
+			"schemas":  b.schemas,
+		},
+	}
+}
+
+// envelopeSchema describes the event envelope around a payload.
+func envelopeSchema(eventType string, payload interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"version", "type", "timestamp", "payload"},
+		"properties": map[string]interface{}{
+			"id":        map[string]interface{}{"type": "integer", "description": "Position in the lobby's event sequence, only set for lobby events"},
+			"version":   map[string]interface{}{"type": "integer", "description": "Version of the event schema"},
+			"type":      map[string]interface{}{"type": "string", "const": eventType},
+			"timestamp": map[string]interface{}{"type": "string", "format": "date-time"},
+			"lobby_id":  map[string]interface{}{"type": "integer", "description": "Lobby the event belongs to, only set for lobby events"},
+			"payload":   payload,
+		},
+	}
+}
+
+// builder converts Go types to JSON schemas, collecting named structs as reusable components.
+type builder struct {
+	schemas map[string]interface{}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (b *builder) schema(t reflect.Type) interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]interface{}{"type": "object"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		if _, ok := b.schemas[t.Name()]; !ok {
+			b.schemas[t.Name()] = nil // Reserve the name, the struct may refer to itself
+			b.schemas[t.Name()] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema describes the JSON object a struct encodes to.
+func (b *builder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	b.addFields(t, properties, &required)
+	sort.Strings(required)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (b *builder) addFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		// Embedded structs without a name are flattened, like encoding/json does
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.addFields(field.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = b.schema(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// operationID turns a channel address into an identifier, e.g. /lobbies/me/events becomes lobbiesMeEvents.
+func operationID(address string) string {
+	var id strings.Builder
+	for i, part := range strings.Split(strings.Trim(address, "/"), "/") {
+		if i > 0 && part != "" {
+			part = strings.ToUpper(part[:1]) + part[1:]
+		}
+		id.WriteString(part)
+	}
+	return id.String()
+}
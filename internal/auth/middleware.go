@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"playmatch/backend/internal/config"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/models"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	gojwt "github.com/golang-jwt/jwt/v5"
@@ -14,6 +17,10 @@ import (
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		// Browsers cannot set headers on WebSocket handshakes, so those may pass the token as a query parameter
+		if authHeader == "" && c.IsWebsocket() && c.Query("access_token") != "" {
+			authHeader = "Bearer " + c.Query("access_token")
+		}
 		if authHeader == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is missing"})
 			return
@@ -47,6 +54,13 @@ func AuthMiddleware() gin.HandlerFunc {
 				return
 			}
 			c.Set("userID", uint(userIDFloat))
+
+			// Tokens issued before a ban stop working once it is in place
+			var user models.User
+			if err := database.DB.Select("id", "banned_at", "banned_until").First(&user, uint(userIDFloat)).Error; err == nil && user.IsBanned(time.Now()) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Account is banned"})
+				return
+			}
 		} else {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
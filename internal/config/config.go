@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -10,6 +11,15 @@ import (
 type Config struct {
 	DatabaseURL string `mapstructure:"DATABASE_URL"`
 	JWTSecret   string `mapstructure:"JWT_SECRET"`
+	HubBackend  string `mapstructure:"HUB_BACKEND"` // "memory" or "postgres"
+
+	HubClientBuffer      int           `mapstructure:"HUB_CLIENT_BUFFER"`       // Events queued per stream connection
+	HubSlowClientTimeout time.Duration `mapstructure:"HUB_SLOW_CLIENT_TIMEOUT"` // How long a full connection is kept before eviction
+	HubHeartbeatInterval time.Duration `mapstructure:"HUB_HEARTBEAT_INTERVAL"`
+
+	ChatFilterMode string `mapstructure:"CHAT_FILTER_MODE"` // "mask" replaces blocked words, "reject" refuses the message
+
+	WSAllowedOrigins []string `mapstructure:"WS_ALLOWED_ORIGINS"` // Comma-separated browser origins allowed to open WebSockets besides the server's own
 }
 
 var AppConfig *Config
@@ -20,6 +30,13 @@ func LoadConfig() {
 	viper.SetConfigName(".env")
 	viper.SetConfigType("env")
 
+	viper.SetDefault("HUB_BACKEND", "memory")
+	viper.SetDefault("HUB_CLIENT_BUFFER", 64)
+	viper.SetDefault("HUB_SLOW_CLIENT_TIMEOUT", "10s")
+	viper.SetDefault("HUB_HEARTBEAT_INTERVAL", "25s")
+	viper.SetDefault("CHAT_FILTER_MODE", "mask")
+	viper.SetDefault("WS_ALLOWED_ORIGINS", "")
+
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
@@ -38,7 +38,7 @@ func Connect(dsn string) {
 	log.Println("Database connection established.")
 
 	// Run migrations
-	err = DB.AutoMigrate(&models.User{}, &models.UserRelation{}, &models.Game{}, &models.Tag{}, &models.Lobby{}, &models.Message{})
+	err = DB.AutoMigrate(&models.User{}, &models.UserRelation{}, &models.Game{}, &models.Tag{}, &models.Lobby{}, &models.Message{}, &models.LobbyBan{}, &models.LobbyWaitlistEntry{}, &models.MatchmakingTicket{}, &models.LobbySession{}, &models.LobbySessionParticipant{}, &models.LobbyTemplate{}, &models.LobbyInvite{}, &models.Group{}, &models.GroupMember{}, &models.GroupSession{}, &models.RatingScale{}, &models.UserRating{}, &models.ReputationEvent{}, &models.Endorsement{}, &models.Report{}, &models.LeaderboardEntry{}, &models.MessageEdit{}, &models.MessageReaction{}, &models.MessageMention{}, &models.Conversation{}, &models.ConversationMember{}, &models.Notification{}, &models.BlockedWord{})
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
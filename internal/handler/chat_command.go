@@ -0,0 +1,380 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxDiceCount    = 20
+	maxDiceSides    = 1000
+	maxDiceModifier = 1000
+	maxPickItems    = 50
+)
+
+var dicePattern = regexp.MustCompile(`^(\d*)d(\d+)([+-]\d+)?$`)
+
+// chatCommandError is a command failure whose message can be shown to the user.
+type chatCommandError string
+
+func (e chatCommandError) Error() string { return string(e) }
+
+// chatCommandContext is what a chat command runs with.
+type chatCommandContext struct {
+	User  models.User
+	Lobby *models.Lobby
+	Scope chatScope
+	Args  string // Everything after the command name, trimmed
+}
+
+// chatCommand is a slash command of the lobby chat.
+type chatCommand struct {
+	Name        string
+	Usage       string
+	Description string
+	HostOnly    bool // Only the host and co-hosts may run it
+	Private     bool // The result is only returned to the caller instead of being posted
+	// Run executes the command and returns the text to post as a system message, or "" when the command posts nothing.
+	Run func(ctx chatCommandContext) (string, error)
+}
+
+// chatCommands holds the registered commands by name.
+var chatCommands = map[string]chatCommand{}
+
+// registerChatCommand makes a command available in the lobby chat.
+func registerChatCommand(command chatCommand) {
+	chatCommands[command.Name] = command
+}
+
+func init() {
+	registerChatCommand(chatCommand{
+		Name:        "help",
+		Usage:       "/help",
+		Description: "List the available commands.",
+		Private:     true,
+		Run:         runHelpCommand,
+	})
+	registerChatCommand(chatCommand{
+		Name:        "me",
+		Usage:       "/me <action>",
+		Description: "Describe what you are doing.",
+		Run:         runMeCommand,
+	})
+	registerChatCommand(chatCommand{
+		Name:        "roll",
+		Usage:       "/roll [N]d<sides>[+modifier]",
+		Description: "Roll dice, 1d6 by default.",
+		Run:         runRollCommand,
+	})
+	registerChatCommand(chatCommand{
+		Name:        "coinflip",
+		Usage:       "/coinflip",
+		Description: "Flip a coin.",
+		Run:         runCoinflipCommand,
+	})
+	registerChatCommand(chatCommand{
+		Name:        "pick",
+		Usage:       "/pick <option>, <option>, ...",
+		Description: "Pick one of the options at random.",
+		Run:         runPickCommand,
+	})
+	registerChatCommand(chatCommand{
+		Name:        "ready",
+		Usage:       "/ready [on|off]",
+		Description: "Mark yourself ready, or not ready with off.",
+		Run:         runReadyCommand,
+	})
+	registerChatCommand(chatCommand{
+		Name:        "slowmode",
+		Usage:       "/slowmode <seconds|off>",
+		Description: "Set the minimum delay between messages of regular members.",
+		HostOnly:    true,
+		Run:         runSlowModeCommand,
+	})
+	registerChatCommand(chatCommand{
+		Name:        "announce",
+		Usage:       "/announce [text]",
+		Description: "Set the lobby announcement, or remove it without text.",
+		HostOnly:    true,
+		Run:         runAnnounceCommand,
+	})
+	registerChatCommand(chatCommand{
+		Name:        "kick",
+		Usage:       "/kick <nickname> [reason]",
+		Description: "Kick a member from the lobby.",
+		HostOnly:    true,
+		Run:         runKickCommand,
+	})
+}
+
+// region --- DTOs ---
+
+// ChatCommandResponse is returned when a lobby chat message was a slash command.
+type ChatCommandResponse struct {
+	Command string           `json:"command" example:"roll"`
+	Message *MessageResponse `json:"message,omitempty"` // The system message with the result, if the command posted one
+	Text    string           `json:"text,omitempty"`    // The result of a command that is only shown to the caller, like /help
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// isChatCommand reports whether a lobby chat message is a slash command rather than text.
+// Messages starting with "//" are sent as text with a single leading slash.
+func isChatCommand(content string) bool {
+	return strings.HasPrefix(content, "/") && !strings.HasPrefix(content, "//")
+}
+
+// unescapeChatText turns a "//" prefix, used to send text that starts with a slash, back into a single slash.
+func unescapeChatText(content string) string {
+	if strings.HasPrefix(content, "//") {
+		return content[1:]
+	}
+	return content
+}
+
+// runChatCommand runs a slash command in the lobby chat and posts its result as a system message.
+func runChatCommand(scope chatScope, content string) (ChatCommandResponse, error) {
+	name, args, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(content), "/"), " ")
+	name = strings.ToLower(name)
+	command, ok := chatCommands[name]
+	if !ok {
+		return ChatCommandResponse{}, chatCommandError(fmt.Sprintf("Unknown command /%s, see /help", name))
+	}
+
+	var user models.User
+	if err := database.DB.Preload("CurrentLobby").First(&user, scope.UserID).Error; err != nil || user.CurrentLobby == nil {
+		return ChatCommandResponse{}, errNotInLobby
+	}
+	if command.HostOnly && !canManageLobby(user.CurrentLobby, user) {
+		return ChatCommandResponse{}, errNotLobbyManager
+	}
+
+	// Commands count towards the rate limits and their arguments go through the word filter
+	args, err := guardChatCommand(scope, user.CurrentLobby, args)
+	if err != nil {
+		return ChatCommandResponse{}, err
+	}
+
+	result, err := command.Run(chatCommandContext{User: user, Lobby: user.CurrentLobby, Scope: scope, Args: strings.TrimSpace(args)})
+	if err != nil {
+		return ChatCommandResponse{}, err
+	}
+
+	response := ChatCommandResponse{Command: name}
+	if command.Private {
+		response.Text = result
+	} else if result != "" {
+		message, err := publishSystemMessage(*scope.LobbyID, result)
+		if err != nil {
+			return ChatCommandResponse{}, err
+		}
+		messageResponse := newMessageResponse(message)
+		response.Message = &messageResponse
+	}
+	return response, nil
+}
+
+// publishSystemMessage stores a system message in the lobby chat and broadcasts it like a new message.
+func publishSystemMessage(lobbyID uint, content string) (models.Message, error) {
+	message := models.Message{
+		LobbyID: &lobbyID,
+		Type:    models.MessageTypeSystem,
+		Content: content,
+	}
+	if err := database.DB.Create(&message).Error; err != nil {
+		return models.Message{}, err
+	}
+
+	hub.GlobalHub.Broadcast(lobbyID, hub.Event{
+		Type:    EventNewMessage,
+		Payload: newMessageResponse(message),
+	})
+	return message, nil
+}
+
+func runHelpCommand(ctx chatCommandContext) (string, error) {
+	names := make([]string, 0, len(chatCommands))
+	for name := range chatCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{"Available commands:"}
+	for _, name := range names {
+		command := chatCommands[name]
+		if command.HostOnly && !canManageLobby(ctx.Lobby, ctx.User) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s - %s", command.Usage, command.Description))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func runMeCommand(ctx chatCommandContext) (string, error) {
+	if ctx.Args == "" {
+		return "", chatCommandError("Usage: /me <action>")
+	}
+	return fmt.Sprintf("* %s %s", ctx.User.Nickname, ctx.Args), nil
+}
+
+// parseDiceNotation parses [N]d<sides>[+modifier] and checks it against the dice limits.
+func parseDiceNotation(notation string) (count, sides, modifier int, err error) {
+	match := dicePattern.FindStringSubmatch(notation)
+	if match == nil {
+		return 0, 0, 0, chatCommandError("Usage: /roll [N]d<sides>[+modifier], e.g. /roll 2d6")
+	}
+
+	count = 1
+	if match[1] != "" {
+		if count, err = strconv.Atoi(match[1]); err != nil {
+			count = maxDiceCount + 1
+		}
+	}
+	if sides, err = strconv.Atoi(match[2]); err != nil {
+		sides = maxDiceSides + 1
+	}
+	if count < 1 || count > maxDiceCount || sides < 2 || sides > maxDiceSides {
+		return 0, 0, 0, chatCommandError(fmt.Sprintf("Roll 1 to %d dice with 2 to %d sides", maxDiceCount, maxDiceSides))
+	}
+
+	if match[3] != "" {
+		if modifier, err = strconv.Atoi(match[3]); err != nil || modifier < -maxDiceModifier || modifier > maxDiceModifier {
+			return 0, 0, 0, chatCommandError(fmt.Sprintf("The modifier must be between -%d and +%d", maxDiceModifier, maxDiceModifier))
+		}
+	}
+	return count, sides, modifier, nil
+}
+
+func runRollCommand(ctx chatCommandContext) (string, error) {
+	notation := strings.ToLower(strings.ReplaceAll(ctx.Args, " ", ""))
+	if notation == "" {
+		notation = "1d6"
+	}
+	count, sides, modifier, err := parseDiceNotation(notation)
+	if err != nil {
+		return "", err
+	}
+
+	rolls := make([]string, count)
+	total := modifier
+	for i := range rolls {
+		roll := rand.IntN(sides) + 1
+		rolls[i] = strconv.Itoa(roll)
+		total += roll
+	}
+	return fmt.Sprintf("%s rolled %s: %s (total %d)", ctx.User.Nickname, notation, strings.Join(rolls, ", "), total), nil
+}
+
+func runCoinflipCommand(ctx chatCommandContext) (string, error) {
+	side := "heads"
+	if rand.IntN(2) == 1 {
+		side = "tails"
+	}
+	return fmt.Sprintf("%s flipped a coin: %s", ctx.User.Nickname, side), nil
+}
+
+func runPickCommand(ctx chatCommandContext) (string, error) {
+	// Options are separated by commas, or by spaces when there are no commas
+	var options []string
+	if strings.Contains(ctx.Args, ",") {
+		for _, option := range strings.Split(ctx.Args, ",") {
+			if option = strings.TrimSpace(option); option != "" {
+				options = append(options, option)
+			}
+		}
+	} else {
+		options = strings.Fields(ctx.Args)
+	}
+	if len(options) < 2 {
+		return "", chatCommandError("Usage: /pick <option>, <option>, ...")
+	}
+	if len(options) > maxPickItems {
+		return "", chatCommandError(fmt.Sprintf("Pick from at most %d options", maxPickItems))
+	}
+
+	return fmt.Sprintf("%s picked %s (from %s)", ctx.User.Nickname, options[rand.IntN(len(options))], strings.Join(options, ", ")), nil
+}
+
+func runReadyCommand(ctx chatCommandContext) (string, error) {
+	ready := true
+	switch strings.ToLower(ctx.Args) {
+	case "", "on":
+	case "off":
+		ready = false
+	default:
+		return "", chatCommandError("Usage: /ready [on|off]")
+	}
+
+	if err := setLobbyReady(ctx.User, ready); err != nil {
+		return "", err
+	}
+	if ready {
+		return fmt.Sprintf("User %s is ready.", ctx.User.Nickname), nil
+	}
+	return fmt.Sprintf("User %s is not ready.", ctx.User.Nickname), nil
+}
+
+func runKickCommand(ctx chatCommandContext) (string, error) {
+	nickname, reason, _ := strings.Cut(ctx.Args, " ")
+	nickname = strings.TrimPrefix(nickname, "@")
+	if nickname == "" {
+		return "", chatCommandError("Usage: /kick <nickname> [reason]")
+	}
+
+	var member models.User
+	if err := database.DB.Where("nickname = ? AND current_lobby_id = ?", nickname, ctx.Lobby.ID).First(&member).Error; err != nil {
+		return "", chatCommandError(fmt.Sprintf("%s is not in this lobby", nickname))
+	}
+
+	// The kick publishes its own system message
+	err := kickLobbyMember(ctx.User, ctx.Lobby, member.ID, KickInput{Reason: strings.TrimSpace(reason)})
+	switch {
+	case errors.Is(err, errKickHost):
+		return "", chatCommandError("The host cannot be kicked")
+	case errors.Is(err, errKickSelf):
+		return "", chatCommandError("You cannot kick yourself")
+	case errors.Is(err, errKickCoHost):
+		return "", chatCommandError("Only the host can kick a co-host")
+	case errors.Is(err, errMemberNotFound):
+		return "", chatCommandError(fmt.Sprintf("%s is not in this lobby", nickname))
+	}
+	return "", err
+}
+
+func runSlowModeCommand(ctx chatCommandContext) (string, error) {
+	seconds := 0
+	if arg := strings.ToLower(ctx.Args); arg != "off" {
+		var err error
+		if seconds, err = strconv.Atoi(arg); err != nil || seconds < 0 || seconds > maxSlowModeSeconds {
+			return "", chatCommandError(fmt.Sprintf("Usage: /slowmode <0-%d|off>", maxSlowModeSeconds))
+		}
+	}
+
+	// The change posts its own system message
+	return "", setLobbySlowMode(ctx.Lobby, seconds)
+}
+
+func runAnnounceCommand(ctx chatCommandContext) (string, error) {
+	if err := setLobbyAnnouncement(ctx.Lobby, ctx.Args); err != nil {
+		if errors.Is(err, errAnnouncementTooLong) {
+			return "", chatCommandError(fmt.Sprintf("Announcement is limited to %d characters", maxAnnouncementLength))
+		}
+		return "", err
+	}
+	if ctx.Lobby.Announcement == "" {
+		return fmt.Sprintf("%s removed the lobby announcement.", ctx.User.Nickname), nil
+	}
+	return fmt.Sprintf("%s updated the lobby announcement: %s", ctx.User.Nickname, ctx.Lobby.Announcement), nil
+}
+
+// endregion
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"errors"
+	"playmatch/backend/internal/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDicePattern(t *testing.T) {
+	tests := []struct {
+		notation string
+		want     bool
+	}{
+		{"d6", true},
+		{"1d6", true},
+		{"20d1000", true},
+		{"2d6+3", true},
+		{"2d6-1", true},
+		{"", false},
+		{"6", false},
+		{"2d", false},
+		{"d", false},
+		{"2x6", false},
+		{"2d6+", false},
+		{"2d6+1+1", false},
+		{"-2d6", false},
+		{"2d6 + 3", false},
+	}
+
+	for _, tt := range tests {
+		if got := dicePattern.MatchString(tt.notation); got != tt.want {
+			t.Errorf("dicePattern.MatchString(%q) = %v, want %v", tt.notation, got, tt.want)
+		}
+	}
+}
+
+func TestParseDiceNotation(t *testing.T) {
+	tests := []struct {
+		notation     string
+		wantCount    int
+		wantSides    int
+		wantModifier int
+		wantErr      bool
+	}{
+		{notation: "d6", wantCount: 1, wantSides: 6},
+		{notation: "3d8", wantCount: 3, wantSides: 8},
+		{notation: "2d6+5", wantCount: 2, wantSides: 6, wantModifier: 5},
+		{notation: "1d20-2", wantCount: 1, wantSides: 20, wantModifier: -2},
+		{notation: "20d1000+1000", wantCount: 20, wantSides: 1000, wantModifier: 1000},
+		{notation: "1d6-1000", wantCount: 1, wantSides: 6, wantModifier: -1000},
+		{notation: "0d6", wantErr: true},
+		{notation: "21d6", wantErr: true},
+		{notation: "1d1", wantErr: true},
+		{notation: "1d1001", wantErr: true},
+		{notation: "1d6+1001", wantErr: true},
+		{notation: "1d6-1001", wantErr: true},
+		{notation: "99999999999999999999d6", wantErr: true},
+		{notation: "1d99999999999999999999", wantErr: true},
+		{notation: "2d6+99999999999999999999", wantErr: true},
+		{notation: "roll", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			count, sides, modifier, err := parseDiceNotation(tt.notation)
+			if tt.wantErr {
+				var commandErr chatCommandError
+				if !errors.As(err, &commandErr) {
+					t.Fatalf("parseDiceNotation(%q) error = %v, want a chat command error", tt.notation, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDiceNotation(%q) error = %v", tt.notation, err)
+			}
+			if count != tt.wantCount || sides != tt.wantSides || modifier != tt.wantModifier {
+				t.Errorf("parseDiceNotation(%q) = %d, %d, %d, want %d, %d, %d",
+					tt.notation, count, sides, modifier, tt.wantCount, tt.wantSides, tt.wantModifier)
+			}
+		})
+	}
+}
+
+func TestRunRollCommand(t *testing.T) {
+	totalPattern := regexp.MustCompile(`\(total (-?\d+)\)$`)
+
+	tests := []struct {
+		args     string
+		minTotal int
+		maxTotal int
+		wantErr  bool
+	}{
+		{args: "", minTotal: 1, maxTotal: 6},
+		{args: "2d6+3", minTotal: 5, maxTotal: 15},
+		{args: "2 D 6", minTotal: 2, maxTotal: 12},
+		{args: "20d1000-1000", minTotal: -980, maxTotal: 19000},
+		{args: "2d6+99999999999999999999", wantErr: true},
+		{args: "100d6", wantErr: true},
+	}
+
+	ctx := chatCommandContext{User: models.User{Nickname: "bob"}}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			ctx.Args = tt.args
+			for i := 0; i < 50; i++ {
+				content, err := runRollCommand(ctx)
+				if tt.wantErr {
+					if err == nil {
+						t.Fatalf("runRollCommand(%q) = %q, want an error", tt.args, content)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("runRollCommand(%q) error = %v", tt.args, err)
+				}
+
+				match := totalPattern.FindStringSubmatch(content)
+				if match == nil {
+					t.Fatalf("runRollCommand(%q) = %q, want a total", tt.args, content)
+				}
+				total, _ := strconv.Atoi(match[1])
+				if total < tt.minTotal || total > tt.maxTotal {
+					t.Fatalf("runRollCommand(%q) total = %d, want %d to %d", tt.args, total, tt.minTotal, tt.maxTotal)
+				}
+			}
+		})
+	}
+}
+
+func TestRunHelpCommandIsPrivate(t *testing.T) {
+	db := testDB(t)
+
+	host := createTestUser(t, db, "host")
+	lobby := createTestLobby(t, db, models.Lobby{MaxPlayers: 4}, host)
+
+	response, err := runChatCommand(chatScope{UserID: host.ID, LobbyID: &lobby.ID}, "/help")
+	if err != nil {
+		t.Fatalf("runChatCommand: %v", err)
+	}
+	if response.Message != nil {
+		t.Error("help listing was posted as a message")
+	}
+	if !strings.Contains(response.Text, "/roll") {
+		t.Errorf("text = %q, want the command listing", response.Text)
+	}
+
+	var posted int64
+	db.Model(&models.Message{}).Where("lobby_id = ?", lobby.ID).Count(&posted)
+	if posted != 0 {
+		t.Errorf("%d messages posted, want none", posted)
+	}
+}
+
+func TestRunAnnounceCommandLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		wantErr bool
+	}{
+		{name: "at the limit", args: strings.Repeat("ä", maxAnnouncementLength)},
+		{name: "surrounding spaces do not count", args: "  " + strings.Repeat("a", maxAnnouncementLength) + "  "},
+		{name: "too long", args: strings.Repeat("a", maxAnnouncementLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+
+			host := createTestUser(t, db, "host")
+			lobby := createTestLobby(t, db, models.Lobby{MaxPlayers: 4}, host)
+
+			_, err := runAnnounceCommand(chatCommandContext{User: host, Lobby: &lobby, Args: tt.args})
+			var commandErr chatCommandError
+			if tt.wantErr != errors.As(err, &commandErr) {
+				t.Fatalf("err = %v, want a command error = %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("runAnnounceCommand: %v", err)
+			}
+
+			db.First(&lobby, lobby.ID)
+			if got := lobby.Announcement != ""; got == tt.wantErr {
+				t.Errorf("announcement set = %v, want %v", got, !tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,534 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"playmatch/backend/internal/config"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxSlowModeSeconds        = 600
+	userMessageLimit          = 5 // Messages a user may send within userMessageWindow, across all chats
+	userMessageWindow         = 10 * time.Second
+	lobbyMessageLimit         = 60 // Messages a lobby chat accepts within lobbyMessageWindow
+	lobbyMessageWindow        = 10 * time.Second
+	duplicateMessageWindow    = 30 * time.Second // Repeating the previous message within this window is refused
+	typingThrottleInterval    = 3 * time.Second
+	wordFilterRefreshInterval = time.Minute
+)
+
+// Word filter modes, set by CHAT_FILTER_MODE.
+const (
+	ChatFilterMask   = "mask"
+	ChatFilterReject = "reject"
+)
+
+var (
+	errDuplicateMessage = errors.New("duplicate message")
+	errBlockedWords     = errors.New("message contains blocked words")
+)
+
+// chatLimitError refuses a message that was sent too soon.
+type chatLimitError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e chatLimitError) Error() string { return e.Message }
+
+// region --- DTOs ---
+
+// SlowModeInput defines the minimum delay between chat messages of regular lobby members.
+type SlowModeInput struct {
+	Seconds int `json:"seconds" binding:"min=0,max=600" example:"10"` // 0 disables slow mode
+}
+
+// BlockedWordInput defines a word for the chat filter.
+type BlockedWordInput struct {
+	Word     string `json:"word" binding:"required,max=100" example:"badword"`
+	Language string `json:"language" binding:"max=10" example:"en"` // Leave empty to filter it in every chat
+}
+
+// BlockedWordResponse describes a word of the chat filter.
+type BlockedWordResponse struct {
+	ID       uint   `json:"id"`
+	Word     string `json:"word"`
+	Language string `json:"language,omitempty"`
+}
+
+// endregion
+
+// region --- Rate limiting ---
+
+// rateLimiter allows a number of hits per key within a sliding window.
+// Its state is kept in memory, so every server instance limits on its own.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	hits    map[uint][]time.Time
+	sweptAt time.Time // When keys whose hits all expired were last removed
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, hits: make(map[uint][]time.Time)}
+}
+
+// allow records a hit for the key, unless the key already used up its hits,
+// in which case it returns how long to wait before the next one is allowed.
+func (l *rateLimiter) allow(key uint, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if wait := l.retryAfter(key, now); wait > 0 {
+		return wait, false
+	}
+	l.add(key, now)
+	return 0, true
+}
+
+// retryAfter returns how long the key has to wait before its next hit is allowed, or 0 when it is allowed now.
+// It does not record a hit. The caller must hold the lock.
+func (l *rateLimiter) retryAfter(key uint, now time.Time) time.Duration {
+	l.sweep(now)
+
+	hits := l.recentHits(key, now)
+	if len(hits) >= l.limit {
+		return l.window - now.Sub(hits[0])
+	}
+	return 0
+}
+
+// add counts a hit for the key, whether or not it is within the limit. The caller must hold the lock.
+func (l *rateLimiter) add(key uint, now time.Time) {
+	l.hits[key] = append(l.recentHits(key, now), now)
+}
+
+// sweep removes the keys whose hits all expired, at most once per window,
+// so users and lobbies that went quiet do not stay in memory. The caller must hold the lock.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.sweptAt) < l.window {
+		return
+	}
+	l.sweptAt = now
+
+	for key, hits := range l.hits {
+		if now.Sub(hits[len(hits)-1]) >= l.window {
+			delete(l.hits, key)
+		}
+	}
+}
+
+// recentHits drops the expired hits of the key and returns the others.
+// A key left without hits is removed. The caller must hold the lock.
+func (l *rateLimiter) recentHits(key uint, now time.Time) []time.Time {
+	hits := l.hits[key]
+	expired := 0
+	for expired < len(hits) && now.Sub(hits[expired]) >= l.window {
+		expired++
+	}
+	if expired == 0 {
+		return hits
+	}
+
+	hits = hits[expired:]
+	if len(hits) == 0 {
+		delete(l.hits, key)
+		return nil
+	}
+	l.hits[key] = hits
+	return hits
+}
+
+// chatLimiter combines the per-user and per-lobby message limits, which a message has to pass together.
+type chatLimiter struct {
+	user  *rateLimiter
+	lobby *rateLimiter
+}
+
+// charge counts a message against the limits of the user and, in a lobby chat, the lobby.
+// When either limit is used up nothing is counted and the error tells how long to wait.
+// Both limiters stay locked from the check to the count, so concurrent messages cannot all pass on the last free hit.
+func (c *chatLimiter) charge(userID uint, lobbyID *uint, now time.Time) error {
+	c.user.mu.Lock()
+	defer c.user.mu.Unlock()
+	c.lobby.mu.Lock()
+	defer c.lobby.mu.Unlock()
+
+	if wait := c.user.retryAfter(userID, now); wait > 0 {
+		return chatLimitError{Message: "You are sending messages too fast", RetryAfter: wait}
+	}
+	if lobbyID != nil {
+		if wait := c.lobby.retryAfter(*lobbyID, now); wait > 0 {
+			return chatLimitError{Message: "The lobby chat is too busy, try again shortly", RetryAfter: wait}
+		}
+		c.lobby.add(*lobbyID, now)
+	}
+	c.user.add(userID, now)
+	return nil
+}
+
+var (
+	chatMessageLimiter = &chatLimiter{
+		user:  newRateLimiter(userMessageLimit, userMessageWindow),
+		lobby: newRateLimiter(lobbyMessageLimit, lobbyMessageWindow),
+	}
+	typingThrottle = newRateLimiter(1, typingThrottleInterval)
+)
+
+// endregion
+
+// region --- Word filter ---
+
+// wordFilter caches the blocked words by language.
+type wordFilter struct {
+	mu       sync.Mutex
+	loadedAt time.Time
+	words    map[string]map[string]bool // Blocked words by language, "" holds the words of every chat
+}
+
+var chatWordFilter = &wordFilter{}
+
+// invalidate makes the next lookup reload the words.
+func (f *wordFilter) invalidate() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.loadedAt = time.Time{}
+}
+
+// isBlocked reports whether the lower-case word is blocked in a chat of the language.
+func (f *wordFilter) isBlocked(word, language string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if time.Since(f.loadedAt) > wordFilterRefreshInterval {
+		var blockedWords []models.BlockedWord
+		if err := database.DB.Find(&blockedWords).Error; err == nil {
+			f.words = make(map[string]map[string]bool)
+			for _, blocked := range blockedWords {
+				if f.words[blocked.Language] == nil {
+					f.words[blocked.Language] = make(map[string]bool)
+				}
+				f.words[blocked.Language][blocked.Word] = true
+			}
+			f.loadedAt = time.Now()
+		}
+	}
+	return f.words[""][word] || (language != "" && f.words[language][word])
+}
+
+// apply masks the blocked words of the content with asterisks and reports whether it found any.
+// Words are runs of letters and digits, compared case-insensitively.
+func (f *wordFilter) apply(content, language string) (string, bool) {
+	runes := []rune(content)
+	found := false
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if f.isBlocked(strings.ToLower(string(runes[start:end])), language) {
+			found = true
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+		}
+		start = end
+	}
+	return string(runes), found
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// guardChatMessage applies the word filter, slow mode, duplicate check and rate limits to a new message of the chat.
+// It returns the content to store, with blocked words masked. Only a message that passes every check counts against the rate limits,
+// so refused messages of one member do not use up the lobby's budget.
+func guardChatMessage(scope chatScope, content string) (string, error) {
+	now := time.Now()
+
+	var lobby models.Lobby
+	if scope.LobbyID != nil {
+		database.DB.Select("id", "language", "slow_mode_seconds").First(&lobby, *scope.LobbyID)
+	}
+
+	content, err := filterChatContent(content, lobby.Language)
+	if err != nil {
+		return "", err
+	}
+
+	// Deleted messages count too, otherwise deleting the last message would lift slow mode
+	var last models.Message
+	if err := scope.scopeMessages(database.DB.Unscoped()).Where("user_id = ?", scope.UserID).Order("id DESC").Take(&last).Error; err == nil {
+		elapsed := now.Sub(last.CreatedAt)
+
+		// Hosts and co-hosts are not slowed down
+		slowMode := time.Duration(lobby.SlowModeSeconds) * time.Second
+		if !scope.CanModerate && elapsed < slowMode {
+			return "", chatLimitError{
+				Message:    fmt.Sprintf("Slow mode is on, wait %d seconds between messages", lobby.SlowModeSeconds),
+				RetryAfter: slowMode - elapsed,
+			}
+		}
+		if !last.DeletedAt.Valid && elapsed < duplicateMessageWindow && strings.EqualFold(strings.TrimSpace(last.Content), strings.TrimSpace(content)) {
+			return "", errDuplicateMessage
+		}
+	}
+
+	if err := chatMessageLimiter.charge(scope.UserID, scope.LobbyID, now); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// guardChatCommand applies the word filter and rate limits to the arguments of a slash command in the lobby.
+func guardChatCommand(scope chatScope, lobby *models.Lobby, args string) (string, error) {
+	args, err := filterChatContent(args, lobby.Language)
+	if err != nil {
+		return "", err
+	}
+	if err := chatMessageLimiter.charge(scope.UserID, &lobby.ID, time.Now()); err != nil {
+		return "", err
+	}
+	return args, nil
+}
+
+// filterChatContent masks the blocked words of the content, or refuses it in reject mode.
+// Lobby chats also filter the words of the lobby's language.
+func filterChatContent(content, language string) (string, error) {
+	content, found := chatWordFilter.apply(content, language)
+	if found && config.AppConfig.ChatFilterMode == ChatFilterReject {
+		return "", errBlockedWords
+	}
+	return content, nil
+}
+
+// chatLanguage returns the language whose blocked words apply to the chat, "" outside lobbies.
+func chatLanguage(scope chatScope) string {
+	if scope.LobbyID == nil {
+		return ""
+	}
+	var lobby models.Lobby
+	database.DB.Select("id", "language").First(&lobby, *scope.LobbyID)
+	return lobby.Language
+}
+
+// respondChatGuardError answers with the reason a message was refused and reports whether the error was one of those.
+func respondChatGuardError(c *gin.Context, err error) bool {
+	var limitErr chatLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": limitErr.Message})
+	case errors.Is(err, errDuplicateMessage):
+		c.JSON(http.StatusConflict, gin.H{"error": "You already sent this message"})
+	case errors.Is(err, errBlockedWords):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message contains blocked words"})
+	default:
+		return false
+	}
+	return true
+}
+
+// chatGuardMessage returns the message shown to a client whose message was refused, or "" for other errors.
+func chatGuardMessage(err error) string {
+	var limitErr chatLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		return limitErr.Message
+	case errors.Is(err, errDuplicateMessage):
+		return "You already sent this message"
+	case errors.Is(err, errBlockedWords):
+		return "Message contains blocked words"
+	}
+	return ""
+}
+
+// allowTyping reports whether a typing event of the user should be broadcast, dropping repeats within typingThrottleInterval.
+func allowTyping(userID uint) bool {
+	_, ok := typingThrottle.allow(userID, time.Now())
+	return ok
+}
+
+// setLobbySlowMode changes the slow mode of the lobby and announces it in the chat.
+func setLobbySlowMode(lobby *models.Lobby, seconds int) error {
+	if err := database.DB.Model(lobby).Update("slow_mode_seconds", seconds).Error; err != nil {
+		return err
+	}
+
+	content := "Slow mode is off."
+	if seconds > 0 {
+		content = fmt.Sprintf("Slow mode is on: one message every %d seconds.", seconds)
+	}
+	_, err := publishSystemMessage(lobby.ID, content)
+	return err
+}
+
+func newBlockedWordResponse(word models.BlockedWord) BlockedWordResponse {
+	return BlockedWordResponse{ID: word.ID, Word: word.Word, Language: word.Language}
+}
+
+// endregion
+
+// region --- Handlers ---
+
+// SetLobbySlowMode godoc
+// @Summary      Set slow mode of my lobby chat (Host or co-host)
+// @Description  Sets the minimum number of seconds between two chat messages of a regular member, up to 600; 0 turns slow mode off. Hosts and co-hosts are not slowed down. The change is announced as a system message; `/slowmode <seconds|off>` does the same from the chat.
+// @Tags         lobbies-chat
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body SlowModeInput true "Slow mode delay"
+// @Success      200 {object} LobbyResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Only the host or a co-host can change slow mode"
+// @Failure      404 {object} ErrorResponse "User is not in a lobby"
+// @Router       /lobbies/me/slow-mode [put]
+func SetLobbySlowMode(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input SlowModeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Preload("CurrentLobby").First(&user, userID).Error; err != nil || user.CurrentLobby == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+		return
+	}
+	lobby := user.CurrentLobby
+	if !canManageLobby(lobby, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host or a co-host can change slow mode"})
+		return
+	}
+
+	if err := setLobbySlowMode(lobby, input.Seconds); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update slow mode"})
+		return
+	}
+
+	database.DB.Preload("Game").Preload("Host").Preload("Members").First(lobby, lobby.ID)
+	c.JSON(http.StatusOK, newLobbyResponse(*lobby))
+}
+
+// GetBlockedWords godoc
+// @Summary      List the words of the chat filter
+// @Description  Lists the blocked words, optionally only those of one language. Depending on CHAT_FILTER_MODE, blocked words are masked with asterisks (`mask`, the default) or the message is refused (`reject`). Words without a language apply to every chat, the others only to lobbies of that language.
+// @Tags         admin-chat-filter
+// @Produce      json
+// @Security     BearerAuth
+// @Param        language query string false "Only words of this language, use an empty value for the words of every chat"
+// @Success      200 {array} BlockedWordResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Router       /admin/blocked-words [get]
+func GetBlockedWords(c *gin.Context) {
+	query := database.DB.Order("language ASC, word ASC")
+	if language, ok := c.GetQuery("language"); ok {
+		query = query.Where("language = ?", normalizeLocaleCode(language))
+	}
+
+	var words []models.BlockedWord
+	if err := query.Find(&words).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve blocked words"})
+		return
+	}
+
+	response := make([]BlockedWordResponse, 0, len(words))
+	for _, word := range words {
+		response = append(response, newBlockedWordResponse(word))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateBlockedWord godoc
+// @Summary      Add a word to the chat filter
+// @Description  Blocks a word in every chat, or only in lobbies of the given language.
+// @Tags         admin-chat-filter
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body BlockedWordInput true "Word"
+// @Success      201 {object} BlockedWordResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse "Word is already blocked"
+// @Router       /admin/blocked-words [post]
+func CreateBlockedWord(c *gin.Context) {
+	var input BlockedWordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	word := models.BlockedWord{
+		Word:     strings.ToLower(strings.TrimSpace(input.Word)),
+		Language: normalizeLocaleCode(input.Language),
+	}
+	if strings.IndexFunc(word.Word, func(r rune) bool { return !isWordRune(r) }) >= 0 || word.Word == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A blocked word may only contain letters and digits"})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.BlockedWord{}).Where("word = ? AND language = ?", word.Word, word.Language).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Word is already blocked"})
+		return
+	}
+	if err := database.DB.Create(&word).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block word"})
+		return
+	}
+	chatWordFilter.invalidate()
+
+	c.JSON(http.StatusCreated, newBlockedWordResponse(word))
+}
+
+// DeleteBlockedWord godoc
+// @Summary      Remove a word from the chat filter
+// @Tags         admin-chat-filter
+// @Security     BearerAuth
+// @Param        id path int true "Blocked word ID"
+// @Success      204 "No Content"
+// @Failure      404 {object} ErrorResponse "Blocked word not found"
+// @Router       /admin/blocked-words/{id} [delete]
+func DeleteBlockedWord(c *gin.Context) {
+	result := database.DB.Delete(&models.BlockedWord{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blocked word"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blocked word not found"})
+		return
+	}
+	chatWordFilter.invalidate()
+
+	c.Status(http.StatusNoContent)
+}
+
+// endregion
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"errors"
+	"playmatch/backend/internal/config"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	type hit struct {
+		key      uint
+		at       time.Time
+		wantOK   bool
+		wantWait time.Duration
+	}
+	tests := []struct {
+		name string
+		hits []hit
+	}{
+		{
+			name: "within the limit",
+			hits: []hit{
+				{1, at(0), true, 0},
+				{1, at(100), true, 0},
+				{1, at(200), true, 0},
+			},
+		},
+		{
+			name: "over the limit waits for the oldest hit to expire",
+			hits: []hit{
+				{1, at(0), true, 0},
+				{1, at(400), true, 0},
+				{1, at(500), true, 0},
+				{1, at(600), false, 400 * time.Millisecond},
+				{1, at(999), false, time.Millisecond},
+				{1, at(1000), true, 0},
+			},
+		},
+		{
+			name: "refused hits are not recorded",
+			hits: []hit{
+				{1, at(0), true, 0},
+				{1, at(1), true, 0},
+				{1, at(2), true, 0},
+				{1, at(500), false, 500 * time.Millisecond},
+				{1, at(1002), true, 0},
+				{1, at(1003), true, 0},
+			},
+		},
+		{
+			name: "keys are limited separately",
+			hits: []hit{
+				{1, at(0), true, 0},
+				{1, at(0), true, 0},
+				{1, at(0), true, 0},
+				{1, at(10), false, 990 * time.Millisecond},
+				{2, at(10), true, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newRateLimiter(3, time.Second)
+			for i, h := range tt.hits {
+				wait, ok := limiter.allow(h.key, h.at)
+				if ok != h.wantOK || wait != h.wantWait {
+					t.Errorf("hit %d: allow = %v, %v, want %v, %v", i, wait, ok, h.wantWait, h.wantOK)
+				}
+			}
+		})
+	}
+}
+
+func TestChatLimiterCharge(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := &chatLimiter{user: newRateLimiter(2, time.Second), lobby: newRateLimiter(3, time.Second)}
+	lobbyID := uint(10)
+
+	if err := limiter.charge(1, &lobbyID, now); err != nil {
+		t.Fatalf("first message: %v", err)
+	}
+	if err := limiter.charge(1, nil, now.Add(100*time.Millisecond)); err != nil {
+		t.Fatalf("second message: %v", err)
+	}
+
+	// The user's limit is used up; the refused message must not count against the lobby
+	var limitErr chatLimitError
+	if err := limiter.charge(1, &lobbyID, now.Add(200*time.Millisecond)); !errors.As(err, &limitErr) || limitErr.RetryAfter != 800*time.Millisecond {
+		t.Fatalf("third message: err = %v, want a wait of 800ms", err)
+	}
+	if hits := len(limiter.lobby.hits[lobbyID]); hits != 1 {
+		t.Errorf("lobby has %d hits, want 1", hits)
+	}
+
+	// Other users fill up the lobby; a refused message must not count against its user
+	limiter.charge(2, &lobbyID, now.Add(300*time.Millisecond))
+	limiter.charge(3, &lobbyID, now.Add(300*time.Millisecond))
+	if err := limiter.charge(4, &lobbyID, now.Add(400*time.Millisecond)); !errors.As(err, &limitErr) {
+		t.Fatalf("message to a full lobby: err = %v, want a limit error", err)
+	}
+	if hits := len(limiter.user.hits[4]); hits != 0 {
+		t.Errorf("user 4 has %d hits, want 0", hits)
+	}
+
+	if err := limiter.charge(1, nil, now.Add(time.Second)); err != nil {
+		t.Errorf("message once the first one expired: %v", err)
+	}
+}
+
+func TestChatLimiterChargeConcurrently(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := &chatLimiter{user: newRateLimiter(5, time.Second), lobby: newRateLimiter(5, time.Second)}
+	lobbyID := uint(10)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limiter.charge(1, &lobbyID, now) == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted != 5 {
+		t.Errorf("accepted %d concurrent messages, want the limit of 5", accepted)
+	}
+}
+
+func TestRateLimiterRemovesExpiredKeys(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(5, time.Second)
+
+	for key := uint(1); key <= 100; key++ {
+		limiter.allow(key, now)
+	}
+	if len(limiter.hits) != 100 {
+		t.Fatalf("got %d keys, want 100", len(limiter.hits))
+	}
+
+	// Within the window nothing is swept
+	limiter.allow(1, now.Add(500*time.Millisecond))
+	if len(limiter.hits) != 100 {
+		t.Fatalf("got %d keys within the window, want 100", len(limiter.hits))
+	}
+
+	// Once the window passed, keys that went quiet are removed on the next hit of any key
+	limiter.allow(2, now.Add(1200*time.Millisecond))
+	if len(limiter.hits) != 2 {
+		t.Errorf("got %d keys after the sweep, want keys 1 and 2", len(limiter.hits))
+	}
+	if hits := limiter.hits[2]; len(hits) != 1 {
+		t.Errorf("key 2 has %d hits, want only the new one", len(hits))
+	}
+}
+
+// newTestWordFilter returns a word filter with the given blocked words by language, without loading them from the database.
+func newTestWordFilter(words map[string][]string) *wordFilter {
+	filter := &wordFilter{loadedAt: time.Now(), words: make(map[string]map[string]bool)}
+	for language, list := range words {
+		filter.words[language] = make(map[string]bool)
+		for _, word := range list {
+			filter.words[language][word] = true
+		}
+	}
+	return filter
+}
+
+func TestWordFilterApply(t *testing.T) {
+	filter := newTestWordFilter(map[string][]string{
+		"":   {"darn", "heck"},
+		"de": {"mist"},
+		"ru": {"блин"},
+	})
+
+	tests := []struct {
+		name      string
+		content   string
+		language  string
+		want      string
+		wantFound bool
+	}{
+		{name: "clean", content: "good game everyone", want: "good game everyone"},
+		{name: "blocked everywhere", content: "oh darn it", want: "oh **** it", wantFound: true},
+		{name: "case-insensitive", content: "DARN, Heck!", want: "****, ****!", wantFound: true},
+		{name: "whole words only", content: "darnit and checked", want: "darnit and checked"},
+		{name: "word of another language", content: "so ein mist", want: "so ein mist"},
+		{name: "word of the chat language", content: "so ein mist", language: "de", want: "so ein ****", wantFound: true},
+		{name: "non-Latin letters", content: "ну блин, опять", language: "ru", want: "ну ****, опять", wantFound: true},
+		{name: "digits are part of words", content: "heck2 heck", want: "heck2 ****", wantFound: true},
+		{name: "empty", content: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := filter.apply(tt.content, tt.language)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("apply(%q, %q) = %q, %v, want %q, %v", tt.content, tt.language, got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestFilterChatContent(t *testing.T) {
+	previousFilter, previousConfig := chatWordFilter, config.AppConfig
+	t.Cleanup(func() { chatWordFilter, config.AppConfig = previousFilter, previousConfig })
+	chatWordFilter = newTestWordFilter(map[string][]string{"": {"darn"}})
+
+	tests := []struct {
+		mode    string
+		content string
+		want    string
+		wantErr error
+	}{
+		{mode: ChatFilterMask, content: "darn it", want: "**** it"},
+		{mode: ChatFilterMask, content: "fine", want: "fine"},
+		{mode: ChatFilterReject, content: "darn it", wantErr: errBlockedWords},
+		{mode: ChatFilterReject, content: "fine", want: "fine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+tt.content, func(t *testing.T) {
+			config.AppConfig = &config.Config{ChatFilterMode: tt.mode}
+			got, err := filterChatContent(tt.content, "")
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("filterChatContent(%q) = %q, %v, want %q, %v", tt.content, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,535 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// region --- DTOs ---
+
+// ConversationInput defines the user to open a direct conversation with.
+type ConversationInput struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// ConversationResponse describes a direct conversation from the point of view of the current user.
+type ConversationResponse struct {
+	ID                     uint               `json:"id"`
+	User                   PublicUserResponse `json:"user"` // The other participant
+	LastMessage            *MessageResponse   `json:"last_message,omitempty"`
+	LastMessageAt          *time.Time         `json:"last_message_at,omitempty"`
+	UnreadCount            int64              `json:"unread_count"`
+	LastReadMessageID      *uint              `json:"last_read_message_id,omitempty"`       // Latest message the current user has read
+	OtherLastReadMessageID *uint              `json:"other_last_read_message_id,omitempty"` // Latest message the other participant has read
+}
+
+// PaginatedConversationResponse defines the structure for a paginated list of conversations.
+type PaginatedConversationResponse struct {
+	Data []ConversationResponse `json:"data"`
+	Meta PaginationMeta         `json:"meta"`
+}
+
+// MarkConversationReadInput defines how far the current user has read a conversation.
+type MarkConversationReadInput struct {
+	MessageID *uint `json:"message_id"` // Leave empty to mark the whole conversation as read
+}
+
+// ConversationReadPayload is sent to both participants when one of them reads a conversation.
+type ConversationReadPayload struct {
+	ConversationID    uint  `json:"conversation_id"`
+	UserID            uint  `json:"user_id"`
+	LastReadMessageID *uint `json:"last_read_message_id"`
+}
+
+// DMPolicyInput defines who can start a direct conversation with the current user.
+type DMPolicyInput struct {
+	Policy models.DMPolicy `json:"policy" binding:"required,oneof=friends everyone"`
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// isBlocked reports whether either user blocked the other.
+func isBlocked(db *gorm.DB, userID, otherID uint) bool {
+	var count int64
+	db.Model(&models.UserRelation{}).
+		Where("((from_user_id = ? AND to_user_id = ?) OR (from_user_id = ? AND to_user_id = ?)) AND status = ?",
+			userID, otherID, otherID, userID, models.StatusBlocked).
+		Count(&count)
+	return count > 0
+}
+
+// directMessageError returns why the sender cannot message the recipient, or an empty string if they can.
+func directMessageError(db *gorm.DB, senderID uint, recipient models.User) string {
+	if isBlocked(db, senderID, recipient.ID) {
+		return "You cannot message this user"
+	}
+	if recipient.DMPolicy != models.DMPolicyEveryone && !areFriends(db, senderID, recipient.ID) {
+		return "This user only accepts messages from friends"
+	}
+	return ""
+}
+
+// conversationPair orders two user IDs the way conversations store them.
+func conversationPair(userID, otherID uint) (uint, uint) {
+	if userID < otherID {
+		return userID, otherID
+	}
+	return otherID, userID
+}
+
+// otherParticipantID returns the participant of the conversation who is not the user.
+func otherParticipantID(conversation models.Conversation, userID uint) uint {
+	if conversation.UserLowID == userID {
+		return conversation.UserHighID
+	}
+	return conversation.UserLowID
+}
+
+// sendToConversation delivers an event to the personal streams of both participants.
+func sendToConversation(conversationID uint, event hub.Event) {
+	var memberIDs []uint
+	database.DB.Model(&models.ConversationMember{}).Where("conversation_id = ?", conversationID).Pluck("user_id", &memberIDs)
+	for _, memberID := range memberIDs {
+		hub.GlobalHub.SendToUser(memberID, event)
+	}
+}
+
+// touchConversation moves the conversation to the top of the list and marks the new message as read by its author.
+func touchConversation(conversationID uint, message models.Message) {
+	database.DB.Model(&models.Conversation{}).Where("id = ?", conversationID).Update("last_message_at", message.CreatedAt)
+	database.DB.Model(&models.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, *message.UserID).
+		Updates(map[string]interface{}{"last_read_message_id": message.ID, "last_read_at": message.CreatedAt})
+}
+
+// loadConversation loads the conversation from the path if the current user takes part in it.
+func loadConversation(c *gin.Context) (*models.Conversation, uint, bool) {
+	userID, _ := c.Get("userID")
+
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return nil, 0, false
+	}
+
+	var conversation models.Conversation
+	if err := database.DB.Where("user_low_id = ? OR user_high_id = ?", userID, userID).
+		First(&conversation, conversationID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return nil, 0, false
+	}
+	return &conversation, userID.(uint), true
+}
+
+// conversationChatScope resolves the chat of the conversation in the path.
+func conversationChatScope(c *gin.Context) (chatScope, bool) {
+	conversation, userID, ok := loadConversation(c)
+	if !ok {
+		return chatScope{}, false
+	}
+	return chatScope{
+		ConversationID: &conversation.ID,
+		UserID:         userID,
+	}, true
+}
+
+// buildConversationResponses describes conversations for the user, with their last messages and unread counts.
+func buildConversationResponses(conversations []models.Conversation, userID uint) []ConversationResponse {
+	response := []ConversationResponse{}
+	if len(conversations) == 0 {
+		return response
+	}
+
+	conversationIDs := make([]uint, 0, len(conversations))
+	otherIDs := make([]uint, 0, len(conversations))
+	for _, conversation := range conversations {
+		conversationIDs = append(conversationIDs, conversation.ID)
+		otherIDs = append(otherIDs, otherParticipantID(conversation, userID))
+	}
+
+	var users []models.User
+	database.DB.Where("id IN ?", otherIDs).Find(&users)
+	usersByID := map[uint]models.User{}
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	var members []models.ConversationMember
+	database.DB.Where("conversation_id IN ?", conversationIDs).Find(&members)
+	readPointers := map[uint]map[uint]*uint{}
+	for _, member := range members {
+		if readPointers[member.ConversationID] == nil {
+			readPointers[member.ConversationID] = map[uint]*uint{}
+		}
+		readPointers[member.ConversationID][member.UserID] = member.LastReadMessageID
+	}
+
+	var lastMessages []models.Message
+	preloadMessageDetails(database.DB).
+		Where("id IN (SELECT MAX(id) FROM messages WHERE conversation_id IN ? AND deleted_at IS NULL GROUP BY conversation_id)", conversationIDs).
+		Find(&lastMessages)
+	lastMessagesByID := map[uint]models.Message{}
+	for _, message := range lastMessages {
+		lastMessagesByID[*message.ConversationID] = message
+	}
+
+	type unreadRow struct {
+		ConversationID uint
+		Unread         int64
+	}
+	var unreadRows []unreadRow
+	database.DB.Table("messages AS m").
+		Select("m.conversation_id, COUNT(*) AS unread").
+		Joins("JOIN conversation_members AS cm ON cm.conversation_id = m.conversation_id AND cm.user_id = ?", userID).
+		Where("m.conversation_id IN ? AND m.deleted_at IS NULL", conversationIDs).
+		Where("m.user_id <> ? AND m.id > COALESCE(cm.last_read_message_id, 0)", userID).
+		Group("m.conversation_id").
+		Scan(&unreadRows)
+	unreadCounts := map[uint]int64{}
+	for _, row := range unreadRows {
+		unreadCounts[row.ConversationID] = row.Unread
+	}
+
+	for _, conversation := range conversations {
+		otherID := otherParticipantID(conversation, userID)
+		item := ConversationResponse{
+			ID:                     conversation.ID,
+			User:                   buildPublicUserResponse(usersByID[otherID], userID),
+			LastMessageAt:          conversation.LastMessageAt,
+			UnreadCount:            unreadCounts[conversation.ID],
+			LastReadMessageID:      readPointers[conversation.ID][userID],
+			OtherLastReadMessageID: readPointers[conversation.ID][otherID],
+		}
+		if message, ok := lastMessagesByID[conversation.ID]; ok {
+			lastMessage := newMessageResponse(message)
+			item.LastMessage = &lastMessage
+		}
+		response = append(response, item)
+	}
+	return response
+}
+
+// endregion
+
+// region --- Conversation Handlers ---
+
+// GetConversations godoc
+// @Summary      Get my conversations
+// @Description  Lists the current user's direct conversations with their last message and unread count, most recently active first.
+// @Tags         direct-messages
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page  query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} PaginatedConversationResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /conversations [get]
+func GetConversations(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.Conversation{}).Where("user_low_id = ? OR user_high_id = ?", userID, userID)
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count conversations"})
+		return
+	}
+
+	var conversations []models.Conversation
+	if err := query.Order("COALESCE(last_message_at, created_at) DESC").
+		Limit(limit).Offset(offset).
+		Find(&conversations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve conversations"})
+		return
+	}
+
+	response := buildConversationResponses(conversations, userID.(uint))
+	c.JSON(http.StatusOK, NewPaginatedResponse(response, totalItems, page, limit))
+}
+
+// OpenConversation godoc
+// @Summary      Open a conversation
+// @Description  Returns the direct conversation with a user, creating it if needed. Users who only accept messages from friends cannot be messaged by others, and blocked users cannot message each other.
+// @Tags         direct-messages
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body ConversationInput true "User to message"
+// @Success      200 {object} ConversationResponse "Existing conversation"
+// @Success      201 {object} ConversationResponse "New conversation"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Blocked or not allowed by the user's DM policy"
+// @Failure      404 {object} ErrorResponse "User not found"
+// @Router       /conversations [post]
+func OpenConversation(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input ConversationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.UserID == userID.(uint) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot message yourself"})
+		return
+	}
+
+	var recipient models.User
+	if err := database.DB.First(&recipient, input.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	lowID, highID := conversationPair(userID.(uint), recipient.ID)
+	var conversation models.Conversation
+	err := database.DB.Where("user_low_id = ? AND user_high_id = ?", lowID, highID).First(&conversation).Error
+	if err == nil {
+		c.JSON(http.StatusOK, buildConversationResponses([]models.Conversation{conversation}, userID.(uint))[0])
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open conversation"})
+		return
+	}
+
+	if reason := directMessageError(database.DB, userID.(uint), recipient); reason != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": reason})
+		return
+	}
+
+	conversation = models.Conversation{UserLowID: lowID, UserHighID: highID}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Both users may open the conversation at the same time
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&conversation)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return tx.Where("user_low_id = ? AND user_high_id = ?", lowID, highID).First(&conversation).Error
+		}
+		return tx.Create(&[]models.ConversationMember{
+			{ConversationID: conversation.ID, UserID: lowID},
+			{ConversationID: conversation.ID, UserID: highID},
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open conversation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, buildConversationResponses([]models.Conversation{conversation}, userID.(uint))[0])
+}
+
+// GetConversationMessages godoc
+// @Summary      Get conversation messages
+// @Description  Retrieves the history of a direct conversation, oldest first. Pass a message ID as `before` or `after` instead of `page` to page without gaps or duplicates; the response is then a MessageCursorResponse.
+// @Tags         direct-messages
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path  int true  "Conversation ID"
+// @Param        page   query int false "Page number" default(1)
+// @Param        limit  query int false "Items per page" default(50)
+// @Param        before query int false "Only messages older than this message ID"
+// @Param        after  query int false "Only messages newer than this message ID"
+// @Success      200 {object} PaginatedMessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Conversation not found"
+// @Router       /conversations/{id}/messages [get]
+func GetConversationMessages(c *gin.Context) {
+	if scope, ok := conversationChatScope(c); ok {
+		listChatMessages(c, scope)
+	}
+}
+
+// PostConversationMessage godoc
+// @Summary      Send a direct message
+// @Description  Sends a message in a direct conversation. Both participants receive it as a `direct_message` event on `/users/me/events`.
+// @Tags         direct-messages
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int          true "Conversation ID"
+// @Param        input body MessageInput true "Message"
+// @Success      201 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Blocked or not allowed by the user's DM policy"
+// @Failure      404 {object} ErrorResponse "Conversation not found"
+// @Failure      409 {object} ErrorResponse "You already sent this message"
+// @Failure      429 {object} ErrorResponse "Sending too fast, see Retry-After"
+// @Router       /conversations/{id}/messages [post]
+func PostConversationMessage(c *gin.Context) {
+	conversation, userID, ok := loadConversation(c)
+	if !ok {
+		return
+	}
+
+	var recipient models.User
+	if err := database.DB.First(&recipient, otherParticipantID(*conversation, userID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if reason := directMessageError(database.DB, userID, recipient); reason != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": reason})
+		return
+	}
+
+	postChatMessage(c, chatScope{ConversationID: &conversation.ID, UserID: userID}, EventDirectMessage)
+}
+
+// EditConversationMessage godoc
+// @Summary      Edit my direct message
+// @Description  Changes the content of one of the current user's direct messages within 15 minutes of posting. Both participants receive a `message_updated` event.
+// @Tags         direct-messages
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path int          true "Conversation ID"
+// @Param        messageID path int          true "Message ID"
+// @Param        input     body MessageInput true "New content"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Not the author or edit window has passed"
+// @Failure      404 {object} ErrorResponse "Conversation or message not found"
+// @Router       /conversations/{id}/messages/{messageID} [put]
+func EditConversationMessage(c *gin.Context) {
+	if scope, ok := conversationChatScope(c); ok {
+		editChatMessage(c, scope)
+	}
+}
+
+// DeleteConversationMessage godoc
+// @Summary      Delete my direct message
+// @Description  Deletes one of the current user's direct messages within 15 minutes of posting. Both participants receive a `message_deleted` event.
+// @Tags         direct-messages
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path int true "Conversation ID"
+// @Param        messageID path int true "Message ID"
+// @Success      200 {object} map[string]string "{"message": "Message deleted"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Conversation or message not found"
+// @Router       /conversations/{id}/messages/{messageID} [delete]
+func DeleteConversationMessage(c *gin.Context) {
+	if scope, ok := conversationChatScope(c); ok {
+		deleteChatMessage(c, scope)
+	}
+}
+
+// MarkConversationRead godoc
+// @Summary      Mark a conversation as read
+// @Description  Moves the current user's read pointer forward to the given message, or to the latest message. Both participants receive a `conversation_read` event, which serves as a read receipt.
+// @Tags         direct-messages
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int                       true  "Conversation ID"
+// @Param        input body MarkConversationReadInput false "Last read message"
+// @Success      200 {object} ConversationReadPayload
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Conversation or message not found"
+// @Router       /conversations/{id}/read [post]
+func MarkConversationRead(c *gin.Context) {
+	conversation, userID, ok := loadConversation(c)
+	if !ok {
+		return
+	}
+
+	var input MarkConversationReadInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var message models.Message
+	query := database.DB.Where("conversation_id = ?", conversation.ID)
+	if input.MessageID != nil {
+		query = query.Where("id = ?", *input.MessageID)
+	}
+	if err := query.Order("id DESC").First(&message).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		return
+	}
+
+	// The read pointer only moves forward
+	if err := database.DB.Model(&models.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversation.ID, userID).
+		Where("last_read_message_id IS NULL OR last_read_message_id < ?", message.ID).
+		Updates(map[string]interface{}{"last_read_message_id": message.ID, "last_read_at": time.Now()}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark conversation as read"})
+		return
+	}
+
+	var member models.ConversationMember
+	database.DB.Where("conversation_id = ? AND user_id = ?", conversation.ID, userID).First(&member)
+	payload := ConversationReadPayload{
+		ConversationID:    conversation.ID,
+		UserID:            userID,
+		LastReadMessageID: member.LastReadMessageID,
+	}
+	sendToConversation(conversation.ID, hub.Event{
+		Type:    EventConversationRead,
+		Payload: payload,
+	})
+
+	c.JSON(http.StatusOK, payload)
+}
+
+// UpdateDMPolicy godoc
+// @Summary      Set who can message me
+// @Description  Sets whether only friends (`friends`, the default) or everyone (`everyone`) can send the current user direct messages. Blocked users can never message each other.
+// @Tags         direct-messages
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body DMPolicyInput true "DM policy"
+// @Success      200 {object} PrivateUserResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "User not found"
+// @Router       /users/me/dm-policy [put]
+func UpdateDMPolicy(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input DMPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err := database.DB.Model(&user).Update("dm_policy", input.Policy).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update DM policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildPrivateUserResponse(user))
+}
+
+// endregion
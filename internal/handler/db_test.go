@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/models"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+var connectTestDB sync.Once
+
+// testDB returns a transaction on the database named by TEST_DATABASE_URL that is rolled back after the test.
+// database.DB points to the transaction until then, so tests must not run in parallel.
+// Tests that need a database are skipped when the variable is not set.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	connectTestDB.Do(func() { database.Connect(dsn) })
+
+	connected := database.DB
+	tx := connected.Begin()
+	database.DB = tx
+	t.Cleanup(func() {
+		database.DB = connected
+		tx.Rollback()
+	})
+	return tx
+}
+
+// createTestUser stores a user with the nickname.
+func createTestUser(t *testing.T, db *gorm.DB, nickname string) models.User {
+	t.Helper()
+
+	user := models.User{Nickname: nickname, Email: nickname + "@example.com", PasswordHash: "-"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user %s: %v", nickname, err)
+	}
+	return user
+}
+
+// createTestGame stores a game with the name.
+func createTestGame(t *testing.T, db *gorm.DB, name string) models.Game {
+	t.Helper()
+
+	game := models.Game{Name: name, SteamURL: "https://store.steampowered.com/app/" + name}
+	if err := db.Create(&game).Error; err != nil {
+		t.Fatalf("create game %s: %v", name, err)
+	}
+	return game
+}
+
+// createTestLobby stores a lobby of the game with the host and members in it.
+func createTestLobby(t *testing.T, db *gorm.DB, lobby models.Lobby, host models.User, members ...models.User) models.Lobby {
+	t.Helper()
+
+	lobby.HostID = host.ID
+	if lobby.GameID == 0 {
+		lobby.GameID = createTestGame(t, db, fmt.Sprintf("game-%s", host.Nickname)).ID
+	}
+	if err := db.Create(&lobby).Error; err != nil {
+		t.Fatalf("create lobby: %v", err)
+	}
+	for _, member := range append([]models.User{host}, members...) {
+		if err := db.Model(&member).Updates(joinLobbyColumns(lobby.ID)).Error; err != nil {
+			t.Fatalf("add %s to lobby: %v", member.Nickname, err)
+		}
+	}
+	return lobby
+}
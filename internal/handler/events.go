@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"playmatch/backend/internal/asyncapi"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+)
+
+// Real-time event types. The payload of each type is listed in EventCatalog.
+const (
+	// Lobby events
+	EventLobbyCreated            hub.EventType = "lobby_created"
+	EventLobbyUpdated            hub.EventType = "lobby_updated"
+	EventLobbyGameChanged        hub.EventType = "lobby_game_changed"
+	EventLobbyDeleted            hub.EventType = "lobby_deleted"
+	EventUserJoined              hub.EventType = "user_joined"
+	EventUserLeft                hub.EventType = "user_left"
+	EventUserKicked              hub.EventType = "user_kicked"
+	EventUserTyping              hub.EventType = "user_typing"
+	EventUserReady               hub.EventType = "user_ready"
+	EventHostChanged             hub.EventType = "host_changed"
+	EventMemberRoleChanged       hub.EventType = "member_role_changed"
+	EventNewMessage              hub.EventType = "new_message"
+	EventMessageUpdated          hub.EventType = "message_updated"
+	EventMessageDeleted          hub.EventType = "message_deleted"
+	EventMessageReactionsUpdated hub.EventType = "message_reactions_updated"
+	EventMessagePinned           hub.EventType = "message_pinned"
+	EventMessageUnpinned         hub.EventType = "message_unpinned"
+
+	// Personal events
+	EventDirectMessage          hub.EventType = "direct_message"
+	EventConversationRead       hub.EventType = "conversation_read"
+	EventGroupMessage           hub.EventType = "group_message"
+	EventGroupMemberRoleChanged hub.EventType = "group_member_role_changed"
+	EventGroupSessionScheduled  hub.EventType = "group_session_scheduled"
+	EventGroupLobbyCreated      hub.EventType = "group_lobby_created"
+	EventMention                hub.EventType = "mention"
+	EventNotification           hub.EventType = "notification"
+	EventPresenceChanged        hub.EventType = "presence_changed"
+	EventMatchFound             hub.EventType = "match_found"
+	EventSessionEnded           hub.EventType = "session_ended"
+	EventWaitlistOffer          hub.EventType = "waitlist_offer"
+	EventWaitlistOfferExpired   hub.EventType = "waitlist_offer_expired"
+	EventWaitlistPosition       hub.EventType = "waitlist_position"
+	EventWaitlistClosed         hub.EventType = "waitlist_closed"
+
+	// WebSocket replies
+	EventCommandResult hub.EventType = "command_result"
+)
+
+// region --- Payloads ---
+
+// LobbyRefPayload identifies the lobby an event is about.
+type LobbyRefPayload struct {
+	LobbyID uint `json:"lobby_id"`
+}
+
+// UserTypingPayload is sent to the lobby while a member is typing.
+type UserTypingPayload struct {
+	UserID   uint   `json:"user_id"`
+	Nickname string `json:"nickname"`
+}
+
+// MemberRoleChangedPayload is sent to the lobby when a member is promoted or demoted.
+type MemberRoleChangedPayload struct {
+	UserID uint             `json:"user_id"`
+	Role   models.LobbyRole `json:"role"`
+}
+
+// GroupMemberRoleChangedPayload is sent to the group members when a member's role changes.
+type GroupMemberRoleChangedPayload struct {
+	GroupID uint             `json:"group_id"`
+	UserID  uint             `json:"user_id"`
+	Role    models.GroupRole `json:"role"`
+}
+
+// endregion
+
+// EventCatalog documents every real-time event with the channels it is delivered on and its payload.
+var EventCatalog = []asyncapi.Message{
+	{Type: string(EventLobbyCreated), Channels: lobbyChannel, Summary: "The current user created the lobby.", Payload: LobbyResponse{}},
+	{Type: string(EventLobbyUpdated), Channels: lobbyChannel, Summary: "The lobby settings changed.", Payload: LobbyResponse{}},
+	{Type: string(EventLobbyGameChanged), Channels: lobbyChannel, Summary: "The lobby switched to another game.", Payload: GameResponse{}},
+	{Type: string(EventLobbyDeleted), Channels: lobbyChannel, Summary: "The last member left and the lobby was deleted.", Payload: LobbyRefPayload{}},
+	{Type: string(EventUserJoined), Channels: lobbyChannel, Summary: "A user joined the lobby.", Payload: PublicUserResponse{}},
+	{Type: string(EventUserLeft), Channels: lobbyChannel, Summary: "A member left the lobby.", Payload: PublicUserResponse{}},
+	{Type: string(EventUserKicked), Channels: lobbyChannel, Summary: "A member was kicked from the lobby.", Payload: PublicUserResponse{}},
+	{Type: string(EventUserTyping), Channels: lobbyChannel, Summary: "A member is typing in the lobby chat.", Payload: UserTypingPayload{}},
+	{Type: string(EventUserReady), Channels: lobbyChannel, Summary: "A member changed their ready state.", Payload: UserReadyPayload{}},
+	{Type: string(EventHostChanged), Channels: lobbyChannel, Summary: "The lobby has a new host.", Payload: HostChangedPayload{}},
+	{Type: string(EventMemberRoleChanged), Channels: lobbyChannel, Summary: "A member was promoted to or demoted from co-host.", Payload: MemberRoleChangedPayload{}},
+	{Type: string(EventNewMessage), Channels: lobbyChannel, Summary: "A message was posted to the lobby chat.", Payload: MessageResponse{}},
+	{Type: string(EventMessageUpdated), Channels: chatChannels, Summary: "A chat message was edited.", Payload: MessageResponse{}},
+	{Type: string(EventMessageDeleted), Channels: chatChannels, Summary: "A chat message was deleted.", Payload: MessageDeletedPayload{}},
+	{Type: string(EventMessageReactionsUpdated), Channels: chatChannels, Summary: "The reactions to a chat message changed.", Payload: ReactionsUpdatedPayload{}},
+	{Type: string(EventMessagePinned), Channels: lobbyChannel, Summary: "A message was pinned in the lobby chat.", Payload: MessageResponse{}},
+	{Type: string(EventMessageUnpinned), Channels: lobbyChannel, Summary: "A message was unpinned from the lobby chat.", Payload: MessageUnpinnedPayload{}},
+	{Type: string(hub.EventResync), Channels: chatChannels, Summary: "Events were lost; the client has to reload its state.", Payload: hub.ResyncPayload{}},
+
+	{Type: string(EventDirectMessage), Channels: userChannel, Summary: "A direct message was sent in one of the user's conversations.", Payload: MessageResponse{}},
+	{Type: string(EventConversationRead), Channels: userChannel, Summary: "A participant read a conversation up to a message.", Payload: ConversationReadPayload{}},
+	{Type: string(EventGroupMessage), Channels: userChannel, Summary: "A message was posted to the chat of one of the user's groups.", Payload: MessageResponse{}},
+	{Type: string(EventGroupMemberRoleChanged), Channels: userChannel, Summary: "The role of a member of one of the user's groups changed.", Payload: GroupMemberRoleChangedPayload{}},
+	{Type: string(EventGroupSessionScheduled), Channels: userChannel, Summary: "A session was scheduled in one of the user's groups.", Payload: GroupSessionResponse{}},
+	{Type: string(EventGroupLobbyCreated), Channels: userChannel, Summary: "A group-only lobby was created in one of the user's groups.", Payload: LobbyResponse{}},
+	{Type: string(EventMention), Channels: userChannel, Summary: "The user was mentioned in a chat message.", Payload: MentionNotificationResponse{}},
+	{Type: string(EventNotification), Channels: userChannel, Summary: "A new entry was added to the notification inbox.", Payload: NotificationResponse{}},
+	{Type: string(EventPresenceChanged), Channels: userChannel, Summary: "The presence of a friend changed.", Payload: PresenceChangedPayload{}},
+	{Type: string(EventMatchFound), Channels: userChannel, Summary: "Matchmaking placed the user into a lobby.", Payload: MatchFoundPayload{}},
+	{Type: string(EventSessionEnded), Channels: userChannel, Summary: "A session the user took part in ended and teammates can be reviewed.", Payload: SessionEndedPayload{}},
+	{Type: string(EventWaitlistOffer), Channels: userChannel, Summary: "A slot in a lobby the user is waiting for is offered to them.", Payload: WaitlistEntryResponse{}},
+	{Type: string(EventWaitlistOfferExpired), Channels: userChannel, Summary: "A waitlist offer expired without being accepted.", Payload: LobbyRefPayload{}},
+	{Type: string(EventWaitlistPosition), Channels: userChannel, Summary: "The user's position in a waitlist changed.", Payload: WaitlistEntryResponse{}},
+	{Type: string(EventWaitlistClosed), Channels: userChannel, Summary: "The lobby the user was waiting for was deleted.", Payload: LobbyRefPayload{}},
+
+	{Type: string(EventCommandResult), Channels: []asyncapi.Channel{asyncapi.ChannelWebSocket}, Summary: "Reply to a command sent over the WebSocket.", Payload: WSCommandResult{}},
+}
+
+var (
+	lobbyChannel = []asyncapi.Channel{asyncapi.ChannelLobby}
+	userChannel  = []asyncapi.Channel{asyncapi.ChannelUser}
+	chatChannels = []asyncapi.Channel{asyncapi.ChannelLobby, asyncapi.ChannelUser} // Lobby, group and conversation chats
+)
@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// sessionFeedbackWindow is how long after a session ends its participants can endorse or report each other.
+const sessionFeedbackWindow = 24 * time.Hour
+
+// region --- DTOs ---
+
+// EndorseInput defines the badges given to a teammate.
+type EndorseInput struct {
+	UserID uint                      `json:"user_id" binding:"required"`
+	Badges []models.EndorsementBadge `json:"badges" binding:"required,min=1,dive,oneof=good_comms carried friendly" example:"good_comms,friendly"`
+}
+
+// ReportInput defines a report against a teammate.
+type ReportInput struct {
+	UserID  uint                `json:"user_id" binding:"required"`
+	Reason  models.ReportReason `json:"reason" binding:"required,oneof=toxicity harassment spam offensive_content cheating griefing no_show other" example:"toxicity"`
+	Comment string              `json:"comment" binding:"max=1000"`
+}
+
+// GivenEndorsementResponse describes a badge the current user gave in a session.
+type GivenEndorsementResponse struct {
+	UserID uint                    `json:"user_id"`
+	Badge  models.EndorsementBadge `json:"badge"`
+}
+
+// SessionFeedbackResponse describes who the current user can give feedback to after a session, and what they already gave.
+type SessionFeedbackResponse struct {
+	SessionID        uint                       `json:"session_id"`
+	FeedbackDeadline time.Time                  `json:"feedback_deadline"`
+	Teammates        []PublicUserResponse       `json:"teammates"`
+	Endorsed         []GivenEndorsementResponse `json:"endorsed"`
+	ReportedUserIDs  []uint                     `json:"reported_user_ids"`
+}
+
+// SessionEndedPayload is sent to every participant when a lobby session ends.
+type SessionEndedPayload struct {
+	SessionID        uint                 `json:"session_id"`
+	LobbyID          uint                 `json:"lobby_id"`
+	FeedbackDeadline time.Time            `json:"feedback_deadline"`
+	Teammates        []PublicUserResponse `json:"teammates"`
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// sessionTeammateIDs returns the participants of a session who were in the lobby at the same time as the user.
+func sessionTeammateIDs(sessionID, userID uint) []uint {
+	var teammateIDs []uint
+	now := time.Now()
+	database.DB.Table("lobby_session_participants AS mine").
+		Distinct("other.user_id").
+		Joins("JOIN lobby_session_participants AS other ON other.session_id = mine.session_id AND other.user_id <> mine.user_id").
+		Where("mine.session_id = ? AND mine.user_id = ?", sessionID, userID).
+		Where("mine.joined_at < COALESCE(other.left_at, ?) AND other.joined_at < COALESCE(mine.left_at, ?)", now, now).
+		Pluck("other.user_id", &teammateIDs)
+	return teammateIDs
+}
+
+// loadTeammates loads the users with the given IDs as public responses.
+func loadTeammates(userIDs []uint, viewerID uint) []PublicUserResponse {
+	teammates := []PublicUserResponse{}
+	if len(userIDs) == 0 {
+		return teammates
+	}
+
+	var users []models.User
+	database.DB.Order("nickname ASC").Find(&users, userIDs)
+	for _, user := range users {
+		teammates = append(teammates, buildPublicUserResponse(user, viewerID))
+	}
+	return teammates
+}
+
+// loadFeedbackSession loads the ended session from the path for giving feedback, and the current user's teammates in it.
+func loadFeedbackSession(c *gin.Context) (*models.LobbySession, []uint, bool) {
+	userID, _ := c.Get("userID")
+	sessionID, err := strconv.ParseUint(c.Param("sessionID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return nil, nil, false
+	}
+
+	var session models.LobbySession
+	if err := database.DB.
+		Where("id = ? AND id IN (?)", sessionID,
+			database.DB.Model(&models.LobbySessionParticipant{}).Select("session_id").Where("user_id = ?", userID)).
+		First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return nil, nil, false
+	}
+	if session.EndedAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session has not ended yet"})
+		return nil, nil, false
+	}
+	if time.Since(*session.EndedAt) > sessionFeedbackWindow {
+		c.JSON(http.StatusGone, gin.H{"error": "Feedback window has closed"})
+		return nil, nil, false
+	}
+
+	return &session, sessionTeammateIDs(session.ID, userID.(uint)), true
+}
+
+// containsUserID reports whether the ID is in the list.
+func containsUserID(userIDs []uint, userID uint) bool {
+	for _, id := range userIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// notifySessionEnded prompts every participant of the lobby's last session for feedback on their teammates.
+func notifySessionEnded(lobbyID uint) {
+	var session models.LobbySession
+	if err := database.DB.Where("lobby_id = ? AND ended_at IS NOT NULL", lobbyID).Order("ended_at DESC").First(&session).Error; err != nil {
+		return
+	}
+
+	var participantIDs []uint
+	database.DB.Model(&models.LobbySessionParticipant{}).Distinct("user_id").Where("session_id = ?", session.ID).Pluck("user_id", &participantIDs)
+
+	deadline := session.EndedAt.Add(sessionFeedbackWindow)
+	for _, participantID := range participantIDs {
+		teammateIDs := sessionTeammateIDs(session.ID, participantID)
+		if len(teammateIDs) == 0 {
+			continue // Nobody to give feedback to
+		}
+		hub.GlobalHub.SendToUser(participantID, hub.Event{
+			Type: EventSessionEnded,
+			Payload: SessionEndedPayload{
+				SessionID:        session.ID,
+				LobbyID:          session.LobbyID,
+				FeedbackDeadline: deadline,
+				Teammates:        loadTeammates(teammateIDs, participantID),
+			},
+		})
+	}
+}
+
+// userEndorsementCounts returns how many times a user received each badge.
+func userEndorsementCounts(userID uint) map[models.EndorsementBadge]int64 {
+	type countRow struct {
+		Badge models.EndorsementBadge
+		Count int64
+	}
+
+	var rows []countRow
+	database.DB.Model(&models.Endorsement{}).
+		Select("badge, COUNT(*) AS count").
+		Where("to_user_id = ?", userID).
+		Group("badge").
+		Scan(&rows)
+
+	counts := make(map[models.EndorsementBadge]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Badge] = row.Count
+	}
+	return counts
+}
+
+// endregion
+
+// GetSessionFeedback godoc
+// @Summary      Get my feedback for a session
+// @Description  Lists the teammates the current user can endorse or report after an ended session, and the feedback already given. Feedback is accepted for 24 hours after the session ends.
+// @Tags         feedback
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sessionID path int true "Session ID"
+// @Success      200 {object} SessionFeedbackResponse
+// @Failure      404 {object} ErrorResponse "Session not found"
+// @Failure      409 {object} ErrorResponse "Session has not ended yet"
+// @Failure      410 {object} ErrorResponse "Feedback window has closed"
+// @Router       /users/me/history/{sessionID}/feedback [get]
+func GetSessionFeedback(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	session, teammateIDs, ok := loadFeedbackSession(c)
+	if !ok {
+		return
+	}
+
+	var endorsements []models.Endorsement
+	database.DB.Where("session_id = ? AND from_user_id = ?", session.ID, userID).Order("id ASC").Find(&endorsements)
+
+	response := SessionFeedbackResponse{
+		SessionID:        session.ID,
+		FeedbackDeadline: session.EndedAt.Add(sessionFeedbackWindow),
+		Teammates:        loadTeammates(teammateIDs, userID.(uint)),
+		Endorsed:         []GivenEndorsementResponse{},
+		ReportedUserIDs:  []uint{},
+	}
+	for _, endorsement := range endorsements {
+		response.Endorsed = append(response.Endorsed, GivenEndorsementResponse{
+			UserID: endorsement.ToUserID,
+			Badge:  endorsement.Badge,
+		})
+	}
+	database.DB.Model(&models.Report{}).Where("session_id = ? AND reporter_id = ?", session.ID, userID).
+		Pluck("reported_user_id", &response.ReportedUserIDs)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// EndorseTeammate godoc
+// @Summary      Endorse a teammate
+// @Description  Gives a teammate from an ended session one or more badges. Badges already given are ignored.
+// @Tags         feedback
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sessionID path int          true "Session ID"
+// @Param        input     body EndorseInput true "Badges"
+// @Success      200 {object} map[string]string "{"message": "Teammate endorsed"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "User was not a teammate in this session"
+// @Failure      404 {object} ErrorResponse "Session not found"
+// @Failure      409 {object} ErrorResponse "Session has not ended yet"
+// @Failure      410 {object} ErrorResponse "Feedback window has closed"
+// @Router       /users/me/history/{sessionID}/endorsements [post]
+func EndorseTeammate(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	session, teammateIDs, ok := loadFeedbackSession(c)
+	if !ok {
+		return
+	}
+
+	var input EndorseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !containsUserID(teammateIDs, input.UserID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "User was not a teammate in this session"})
+		return
+	}
+
+	endorsements := make([]models.Endorsement, 0, len(input.Badges))
+	for _, badge := range input.Badges {
+		endorsements = append(endorsements, models.Endorsement{
+			SessionID:  session.ID,
+			FromUserID: userID.(uint),
+			ToUserID:   input.UserID,
+			Badge:      badge,
+		})
+	}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&endorsements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to endorse teammate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Teammate endorsed"})
+}
+
+// ReportTeammate godoc
+// @Summary      Report a teammate
+// @Description  Reports a teammate from an ended session to the moderators (see `/admin/reports`). A user can report each teammate once per session.
+// @Tags         feedback
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sessionID path int         true "Session ID"
+// @Param        input     body ReportInput true "Report"
+// @Success      201 {object} map[string]string "{"message": "Report submitted"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "User was not a teammate in this session"
+// @Failure      404 {object} ErrorResponse "Session not found"
+// @Failure      409 {object} ErrorResponse "Session has not ended yet or user already reported"
+// @Failure      410 {object} ErrorResponse "Feedback window has closed"
+// @Router       /users/me/history/{sessionID}/reports [post]
+func ReportTeammate(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	session, teammateIDs, ok := loadFeedbackSession(c)
+	if !ok {
+		return
+	}
+
+	var input ReportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !containsUserID(teammateIDs, input.UserID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "User was not a teammate in this session"})
+		return
+	}
+
+	var reported models.User
+	database.DB.First(&reported, input.UserID)
+
+	report := models.Report{
+		SessionID:      &session.ID,
+		ReporterID:     userID.(uint),
+		ReportedUserID: input.UserID,
+		TargetType:     models.ReportTargetUser,
+		Reason:         input.Reason,
+		Comment:        input.Comment,
+		Snapshot:       encodeReportSnapshot(ReportSnapshot{User: newReportUserResponse(reported)}),
+		Status:         models.ReportStatusOpen,
+	}
+	result := database.DB.Omit("Reporter", "ReportedUser", "Assignee").Clauses(clause.OnConflict{DoNothing: true}).Create(&report)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit report"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You already reported this user for this session"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Report submitted"})
+}
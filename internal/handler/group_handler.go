@@ -0,0 +1,1064 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// upcomingGroupSessionsLimit caps the sessions shown on a group page.
+const upcomingGroupSessionsLimit = 10
+
+// region --- DTOs ---
+
+// GroupInput defines the structure for creating or updating a group.
+type GroupInput struct {
+	Name        string `json:"name" binding:"required,max=100" example:"Night Owls"`
+	Description string `json:"description"`
+	IsOpen      bool   `json:"is_open"`
+}
+
+// GroupMemberInput defines the user to add to a group.
+type GroupMemberInput struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// GroupRoleInput defines the new role of a group member.
+type GroupRoleInput struct {
+	Role models.GroupRole `json:"role" binding:"required,oneof=owner officer member" example:"officer"`
+}
+
+// GroupSessionInput defines the structure for scheduling a group session.
+type GroupSessionInput struct {
+	GameID   uint      `json:"game_id" binding:"required"`
+	Title    string    `json:"title" binding:"required,max=100" example:"Friday ranked"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+}
+
+// GroupResponse describes a group in lists.
+type GroupResponse struct {
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	OwnerID      uint      `json:"owner_id"`
+	IsOpen       bool      `json:"is_open"`
+	MembersCount int64     `json:"members_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// GroupMemberResponse describes a member of a group.
+type GroupMemberResponse struct {
+	User     PublicUserResponse `json:"user"`
+	Role     models.GroupRole   `json:"role"`
+	JoinedAt time.Time          `json:"joined_at"`
+}
+
+// GroupSessionResponse describes a session scheduled by a group.
+type GroupSessionResponse struct {
+	ID          uint         `json:"id"`
+	GroupID     uint         `json:"group_id"`
+	Title       string       `json:"title"`
+	Game        GameResponse `json:"game"`
+	StartsAt    time.Time    `json:"starts_at"`
+	CreatedByID uint         `json:"created_by_id"`
+}
+
+// GroupPageResponse describes a group page with its members, favourite games and upcoming sessions.
+type GroupPageResponse struct {
+	GroupResponse
+	MyRole           *models.GroupRole      `json:"my_role,omitempty"`
+	Members          []GroupMemberResponse  `json:"members"`
+	FavoriteGames    []GameResponse         `json:"favorite_games"`
+	UpcomingSessions []GroupSessionResponse `json:"upcoming_sessions"`
+}
+
+// PaginatedGroupResponse defines the structure for a paginated list of groups.
+type PaginatedGroupResponse struct {
+	Data []GroupResponse `json:"data"`
+	Meta PaginationMeta  `json:"meta"`
+}
+
+func newGroupResponse(group models.Group, membersCount int64) GroupResponse {
+	return GroupResponse{
+		ID:           group.ID,
+		Name:         group.Name,
+		Description:  group.Description,
+		OwnerID:      group.OwnerID,
+		IsOpen:       group.IsOpen,
+		MembersCount: membersCount,
+		CreatedAt:    group.CreatedAt,
+	}
+}
+
+func newGroupSessionResponse(session models.GroupSession) GroupSessionResponse {
+	return GroupSessionResponse{
+		ID:          session.ID,
+		GroupID:     session.GroupID,
+		Title:       session.Title,
+		Game:        newGameResponse(session.Game, nil),
+		StartsAt:    session.StartsAt,
+		CreatedByID: session.CreatedByID,
+	}
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// findGroupMember returns the membership of the user in the group.
+func findGroupMember(db *gorm.DB, groupID, userID uint) (*models.GroupMember, bool) {
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error; err != nil {
+		return nil, false
+	}
+	return &member, true
+}
+
+// countGroupMembers returns the number of members of a group.
+func countGroupMembers(db *gorm.DB, groupID uint) int64 {
+	var count int64
+	db.Model(&models.GroupMember{}).Where("group_id = ?", groupID).Count(&count)
+	return count
+}
+
+// canManageGroup reports whether the member may manage the group's members, games, sessions and settings.
+func canManageGroup(member *models.GroupMember) bool {
+	return member != nil && (member.Role == models.GroupRoleOwner || member.Role == models.GroupRoleOfficer)
+}
+
+// loadGroup loads the group from the path and the current user's membership in it.
+func loadGroup(c *gin.Context) (*models.Group, *models.GroupMember, bool) {
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return nil, nil, false
+	}
+
+	var group models.Group
+	if err := database.DB.First(&group, groupID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return nil, nil, false
+	}
+
+	var member *models.GroupMember
+	if userID, ok := c.Get("userID"); ok {
+		member, _ = findGroupMember(database.DB, group.ID, userID.(uint))
+	}
+	return &group, member, true
+}
+
+// loadGroupAsMember loads the group from the path, answering 403 unless the current user belongs to it.
+func loadGroupAsMember(c *gin.Context) (*models.Group, *models.GroupMember, bool) {
+	group, member, ok := loadGroup(c)
+	if !ok {
+		return nil, nil, false
+	}
+	if member == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "User is not a member of this group"})
+		return nil, nil, false
+	}
+	return group, member, true
+}
+
+// loadGroupAsManager loads the group from the path, answering 403 unless the current user is its owner or an officer.
+func loadGroupAsManager(c *gin.Context) (*models.Group, *models.GroupMember, bool) {
+	group, member, ok := loadGroupAsMember(c)
+	if !ok {
+		return nil, nil, false
+	}
+	if !canManageGroup(member) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the group owner and officers can do this"})
+		return nil, nil, false
+	}
+	return group, member, true
+}
+
+// areFriends reports whether two users have accepted each other's friend request.
+func areFriends(db *gorm.DB, userID, otherID uint) bool {
+	var count int64
+	db.Model(&models.UserRelation{}).
+		Where("((from_user_id = ? AND to_user_id = ?) OR (from_user_id = ? AND to_user_id = ?)) AND status = ?",
+			userID, otherID, otherID, userID, models.StatusAccepted).
+		Count(&count)
+	return count > 0
+}
+
+// sendToGroup delivers an event to the personal streams of every group member.
+func sendToGroup(groupID uint, event hub.Event) {
+	var memberIDs []uint
+	database.DB.Model(&models.GroupMember{}).Where("group_id = ?", groupID).Pluck("user_id", &memberIDs)
+	for _, memberID := range memberIDs {
+		hub.GlobalHub.SendToUser(memberID, event)
+	}
+}
+
+// postGroupSystemMessage stores a system message in the group chat and delivers it to the members.
+func postGroupSystemMessage(groupID uint, content string) {
+	message := models.Message{
+		GroupID: &groupID,
+		Type:    models.MessageTypeSystem,
+		Content: content,
+	}
+	if err := database.DB.Create(&message).Error; err != nil {
+		return
+	}
+	sendToGroup(groupID, hub.Event{
+		Type:    EventGroupMessage,
+		Payload: newMessageResponse(message),
+	})
+}
+
+// addGroupMember stores a new membership and announces it in the group chat.
+func addGroupMember(group models.Group, user models.User) error {
+	member := models.GroupMember{
+		GroupID: group.ID,
+		UserID:  user.ID,
+		Role:    models.GroupRoleMember,
+	}
+	if err := database.DB.Omit("User").Create(&member).Error; err != nil {
+		return err
+	}
+	postGroupSystemMessage(group.ID, "User "+user.Nickname+" joined the group.")
+	return nil
+}
+
+// endregion
+
+// region --- Group Handlers ---
+
+// SearchGroups godoc
+// @Summary      Search for groups
+// @Description  Gets a paginated list of groups, optionally filtered by name.
+// @Tags         groups
+// @Produce      json
+// @Param        q     query string false "Search by name"
+// @Param        page  query int    false "Page number" default(1)
+// @Param        limit query int    false "Items per page" default(10)
+// @Success      200 {object} PaginatedGroupResponse
+// @Router       /groups [get]
+func SearchGroups(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100 // Max limit
+	}
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.Group{})
+	if q := c.Query("q"); q != "" {
+		query = query.Where("name ILIKE ?", "%"+q+"%")
+	}
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count groups"})
+		return
+	}
+
+	var groups []models.Group
+	if err := query.Order("name ASC").Limit(limit).Offset(offset).Find(&groups).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve groups"})
+		return
+	}
+
+	response := []GroupResponse{}
+	for _, group := range groups {
+		response = append(response, newGroupResponse(group, countGroupMembers(database.DB, group.ID)))
+	}
+
+	c.JSON(http.StatusOK, NewPaginatedResponse(response, totalItems, page, limit))
+}
+
+// GetMyGroups godoc
+// @Summary      Get my groups
+// @Description  Lists the groups the current user belongs to.
+// @Tags         groups
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} GroupResponse
+// @Router       /users/me/groups [get]
+func GetMyGroups(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var groups []models.Group
+	if err := database.DB.
+		Where("id IN (?)", database.DB.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", userID)).
+		Order("name ASC").
+		Find(&groups).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve groups"})
+		return
+	}
+
+	response := []GroupResponse{}
+	for _, group := range groups {
+		response = append(response, newGroupResponse(group, countGroupMembers(database.DB, group.ID)))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetGroupByID godoc
+// @Summary      Get a group page
+// @Description  Gets a group with its members, favourite games and upcoming sessions.
+// @Tags         groups
+// @Produce      json
+// @Param        id path int true "Group ID"
+// @Success      200 {object} GroupPageResponse
+// @Failure      404 {object} ErrorResponse "Group not found"
+// @Router       /groups/{id} [get]
+func GetGroupByID(c *gin.Context) {
+	group, myMember, ok := loadGroup(c)
+	if !ok {
+		return
+	}
+	var viewerID uint
+	if userID, exists := c.Get("userID"); exists {
+		viewerID = userID.(uint)
+	}
+
+	var members []models.GroupMember
+	database.DB.Preload("User").Where("group_id = ?", group.ID).Order("created_at ASC").Find(&members)
+	database.DB.Model(group).Preload("Tags").Association("FavoriteGames").Find(&group.FavoriteGames)
+
+	var sessions []models.GroupSession
+	database.DB.Preload("Game.Tags").
+		Where("group_id = ? AND starts_at > ?", group.ID, time.Now()).
+		Order("starts_at ASC").
+		Limit(upcomingGroupSessionsLimit).
+		Find(&sessions)
+
+	response := GroupPageResponse{
+		GroupResponse:    newGroupResponse(*group, int64(len(members))),
+		Members:          []GroupMemberResponse{},
+		FavoriteGames:    []GameResponse{},
+		UpcomingSessions: []GroupSessionResponse{},
+	}
+	if myMember != nil {
+		response.MyRole = &myMember.Role
+	}
+	for _, member := range members {
+		response.Members = append(response.Members, GroupMemberResponse{
+			User:     buildPublicUserResponse(member.User, viewerID),
+			Role:     member.Role,
+			JoinedAt: member.CreatedAt,
+		})
+	}
+	for _, game := range group.FavoriteGames {
+		response.FavoriteGames = append(response.FavoriteGames, newGameResponse(*game, nil))
+	}
+	for _, session := range sessions {
+		response.UpcomingSessions = append(response.UpcomingSessions, newGroupSessionResponse(session))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateGroup godoc
+// @Summary      Create a group
+// @Description  Creates a persistent group, making the creator its owner.
+// @Tags         groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body GroupInput true "Group Info"
+// @Success      201 {object} GroupResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse "Group name is already taken"
+// @Router       /groups [post]
+func CreateGroup(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input GroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var taken int64
+	database.DB.Unscoped().Model(&models.Group{}).Where("name = ?", input.Name).Count(&taken)
+	if taken > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Group name is already taken"})
+		return
+	}
+
+	group := models.Group{
+		Name:        input.Name,
+		Description: input.Description,
+		OwnerID:     userID.(uint),
+		IsOpen:      input.IsOpen,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		return tx.Omit("User").Create(&models.GroupMember{
+			GroupID: group.ID,
+			UserID:  group.OwnerID,
+			Role:    models.GroupRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create group"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newGroupResponse(group, 1))
+}
+
+// UpdateGroup godoc
+// @Summary      Update a group
+// @Description  Updates the name, description and openness of a group. Only the owner and officers can do this.
+// @Tags         groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int        true "Group ID"
+// @Param        input body GroupInput true "Group Info"
+// @Success      200 {object} GroupResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group not found"
+// @Failure      409 {object} ErrorResponse "Group name is already taken"
+// @Router       /groups/{id} [put]
+func UpdateGroup(c *gin.Context) {
+	group, _, ok := loadGroupAsManager(c)
+	if !ok {
+		return
+	}
+
+	var input GroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var taken int64
+	database.DB.Unscoped().Model(&models.Group{}).Where("name = ? AND id <> ?", input.Name, group.ID).Count(&taken)
+	if taken > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Group name is already taken"})
+		return
+	}
+
+	group.Name = input.Name
+	group.Description = input.Description
+	group.IsOpen = input.IsOpen
+	if err := database.DB.Save(group).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newGroupResponse(*group, countGroupMembers(database.DB, group.ID)))
+}
+
+// DeleteGroup godoc
+// @Summary      Delete a group
+// @Description  Deletes a group together with its memberships and scheduled sessions. Only the owner can do this.
+// @Description  The group's lobbies stay open but are no longer restricted to group members, and the group name becomes free again.
+// @Tags         groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Group ID"
+// @Success      200 {object} map[string]string "{"message": "Group deleted"}"
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group not found"
+// @Router       /groups/{id} [delete]
+func DeleteGroup(c *gin.Context) {
+	group, member, ok := loadGroupAsMember(c)
+	if !ok {
+		return
+	}
+	if member.Role != models.GroupRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the group owner can delete the group"})
+		return
+	}
+
+	var lobbyIDs []uint
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Lobby{}).Where("group_id = ?", group.ID).Pluck("id", &lobbyIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Lobby{}).Where("group_id = ?", group.ID).Update("group_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.GroupSession{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(group).Association("FavoriteGames").Clear(); err != nil {
+			return err
+		}
+		// Deleted for good, so that the unique name can be used by a new group
+		return tx.Unscoped().Delete(group).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
+		return
+	}
+
+	for _, lobbyID := range lobbyIDs {
+		var lobby models.Lobby
+		if err := database.DB.Preload("Game").Preload("Host").Preload("Members").First(&lobby, lobbyID).Error; err != nil {
+			continue
+		}
+		hub.GlobalHub.Broadcast(lobby.ID, hub.Event{
+			Type:    EventLobbyUpdated,
+			Payload: newLobbyResponse(lobby),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Group deleted"})
+}
+
+// endregion
+
+// region --- Membership Handlers ---
+
+// JoinGroup godoc
+// @Summary      Join an open group
+// @Description  Joins a group that is open to everyone. Closed groups only accept members added by the owner or officers.
+// @Tags         groups-members
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Group ID"
+// @Success      200 {object} map[string]string "{"message": "Joined group successfully"}"
+// @Failure      403 {object} ErrorResponse "Group is closed"
+// @Failure      404 {object} ErrorResponse "Group not found"
+// @Failure      409 {object} ErrorResponse "User is already a member"
+// @Router       /groups/{id}/join [post]
+func JoinGroup(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	group, member, ok := loadGroup(c)
+	if !ok {
+		return
+	}
+	if member != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member of this group"})
+		return
+	}
+	if !group.IsOpen {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Group is closed, ask an officer to add you"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err := addGroupMember(*group, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Joined group successfully"})
+}
+
+// LeaveGroup godoc
+// @Summary      Leave a group
+// @Description  Leaves a group. The owner must hand ownership over or delete the group instead.
+// @Tags         groups-members
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Group ID"
+// @Success      200 {object} map[string]string "{"message": "Left group successfully"}"
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group not found"
+// @Failure      409 {object} ErrorResponse "The owner cannot leave the group"
+// @Router       /groups/{id}/leave [post]
+func LeaveGroup(c *gin.Context) {
+	group, member, ok := loadGroupAsMember(c)
+	if !ok {
+		return
+	}
+	if member.Role == models.GroupRoleOwner {
+		c.JSON(http.StatusConflict, gin.H{"error": "The owner cannot leave the group, transfer ownership or delete it"})
+		return
+	}
+
+	var user models.User
+	database.DB.First(&user, member.UserID)
+	if err := database.DB.Delete(member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave group"})
+		return
+	}
+	postGroupSystemMessage(group.ID, "User "+user.Nickname+" left the group.")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left group successfully"})
+}
+
+// AddGroupMember godoc
+// @Summary      Add a member to a group
+// @Description  Adds one of the current user's friends to the group. Only the owner and officers can do this.
+// @Tags         groups-members
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int              true "Group ID"
+// @Param        input body GroupMemberInput true "User to add"
+// @Success      201 {object} GroupMemberResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group or user not found"
+// @Failure      409 {object} ErrorResponse "User is already a member"
+// @Router       /groups/{id}/members [post]
+func AddGroupMember(c *gin.Context) {
+	group, manager, ok := loadGroupAsManager(c)
+	if !ok {
+		return
+	}
+
+	var input GroupMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, input.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if _, exists := findGroupMember(database.DB, group.ID, user.ID); exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member of this group"})
+		return
+	}
+	// Nobody ends up in a group on a stranger's say-so
+	if !areFriends(database.DB, manager.UserID, user.ID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add your friends to a group"})
+		return
+	}
+
+	if err := addGroupMember(*group, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, GroupMemberResponse{
+		User:     buildPublicUserResponse(user, manager.UserID),
+		Role:     models.GroupRoleMember,
+		JoinedAt: time.Now(),
+	})
+}
+
+// RemoveGroupMember godoc
+// @Summary      Remove a member from a group
+// @Description  Removes a member from the group. Officers can remove regular members; the owner can remove anyone but themselves.
+// @Tags         groups-members
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path int true "Group ID"
+// @Param        userID path int true "User ID"
+// @Success      200 {object} map[string]string "{"message": "Member removed"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group or member not found"
+// @Router       /groups/{id}/members/{userID} [delete]
+func RemoveGroupMember(c *gin.Context) {
+	group, manager, ok := loadGroupAsManager(c)
+	if !ok {
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if uint(targetID) == manager.UserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use leave to exit the group"})
+		return
+	}
+
+	target, exists := findGroupMember(database.DB, group.ID, uint(targetID))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	if manager.Role != models.GroupRoleOwner && target.Role != models.GroupRoleMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Officers can only remove regular members"})
+		return
+	}
+
+	var user models.User
+	database.DB.First(&user, target.UserID)
+	if err := database.DB.Delete(target).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+	postGroupSystemMessage(group.ID, "User "+user.Nickname+" was removed from the group.")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// SetGroupMemberRole godoc
+// @Summary      Change a member's role
+// @Description  Promotes or demotes a group member. Only the owner can do this; giving the owner role transfers ownership and makes the previous owner an officer.
+// @Tags         groups-members
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path int            true "Group ID"
+// @Param        userID path int            true "User ID"
+// @Param        input  body GroupRoleInput true "New role"
+// @Success      200 {object} map[string]string "{"message": "Role updated"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group or member not found"
+// @Router       /groups/{id}/members/{userID}/role [put]
+func SetGroupMemberRole(c *gin.Context) {
+	group, owner, ok := loadGroupAsMember(c)
+	if !ok {
+		return
+	}
+	if owner.Role != models.GroupRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the group owner can change roles"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if uint(targetID) == owner.UserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change your own role"})
+		return
+	}
+
+	var input GroupRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, exists := findGroupMember(database.DB, group.ID, uint(targetID))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if input.Role == models.GroupRoleOwner {
+			if err := tx.Model(owner).Update("role", models.GroupRoleOfficer).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(group).Update("owner_id", target.UserID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(target).Update("role", input.Role).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	sendToGroup(group.ID, hub.Event{
+		Type: EventGroupMemberRoleChanged,
+		Payload: GroupMemberRoleChangedPayload{
+			GroupID: group.ID,
+			UserID:  target.UserID,
+			Role:    input.Role,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role updated"})
+}
+
+// ToggleGroupFavoriteGame godoc
+// @Summary      Toggle a group favourite game
+// @Description  Adds or removes a game from the group's favourites shown on the group page. Only the owner and officers can do this.
+// @Tags         groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path int true "Group ID"
+// @Param        gameID path int true "Game ID"
+// @Success      200 {object} map[string]bool "{"is_favorite": true}"
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group or game not found"
+// @Router       /groups/{id}/favorite-games/{gameID} [post]
+func ToggleGroupFavoriteGame(c *gin.Context) {
+	group, _, ok := loadGroupAsManager(c)
+	if !ok {
+		return
+	}
+	gameID, _ := strconv.Atoi(c.Param("gameID"))
+
+	var game models.Game
+	if err := database.DB.First(&game, gameID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
+		return
+	}
+
+	association := database.DB.Model(group).Association("FavoriteGames")
+
+	var current []*models.Game
+	association.Find(&current, "id = ?", game.ID)
+
+	if len(current) > 0 {
+		if err := association.Delete(&game); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove from favorites"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"is_favorite": false})
+	} else {
+		if err := association.Append(&game); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add to favorites"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"is_favorite": true})
+	}
+}
+
+// endregion
+
+// region --- Group Chat Handlers ---
+
+// PostGroupMessage godoc
+// @Summary      Post a message to a group chat
+// @Description  Posts a message to the group chat. Members receive it as a `group_message` event on `/users/me/events`. It can reply to an earlier message of the chat, and `@nickname` mentions of members are notified with a `mention` event and kept in their mentions inbox.
+// @Tags         groups-chat
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int          true "Group ID"
+// @Param        input body MessageInput true "Message"
+// @Success      201 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group not found"
+// @Failure      409 {object} ErrorResponse "You already sent this message"
+// @Failure      429 {object} ErrorResponse "Sending too fast, see Retry-After"
+// @Router       /groups/{id}/messages [post]
+func PostGroupMessage(c *gin.Context) {
+	if scope, ok := groupChatScope(c); ok {
+		postChatMessage(c, scope, EventGroupMessage)
+	}
+}
+
+// GetGroupMessages godoc
+// @Summary      Get group chat messages
+// @Description  Retrieves a paginated history of the group chat. Pass a message ID as `before` or `after` instead of `page` to page without gaps or duplicates; the response is then a MessageCursorResponse.
+// @Tags         groups-chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path  int true  "Group ID"
+// @Param        page   query int false "Page number" default(1)
+// @Param        limit  query int false "Items per page" default(50)
+// @Param        before query int false "Only messages older than this message ID"
+// @Param        after  query int false "Only messages newer than this message ID"
+// @Success      200 {object} PaginatedMessageResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group not found"
+// @Router       /groups/{id}/messages [get]
+func GetGroupMessages(c *gin.Context) {
+	if scope, ok := groupChatScope(c); ok {
+		listChatMessages(c, scope)
+	}
+}
+
+// endregion
+
+// region --- Group Session and Lobby Handlers ---
+
+// CreateGroupSession godoc
+// @Summary      Schedule a group session
+// @Description  Schedules a session shown on the group page. Only the owner and officers can do this.
+// @Tags         groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int               true "Group ID"
+// @Param        input body GroupSessionInput true "Session"
+// @Success      201 {object} GroupSessionResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group or game not found"
+// @Router       /groups/{id}/sessions [post]
+func CreateGroupSession(c *gin.Context) {
+	group, manager, ok := loadGroupAsManager(c)
+	if !ok {
+		return
+	}
+
+	var input GroupSessionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !input.StartsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session must start in the future"})
+		return
+	}
+	if !gameExists(input.GameID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
+		return
+	}
+
+	session := models.GroupSession{
+		GroupID:     group.ID,
+		GameID:      input.GameID,
+		CreatedByID: manager.UserID,
+		Title:       input.Title,
+		StartsAt:    input.StartsAt,
+	}
+	if err := database.DB.Omit("Game").Create(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule session"})
+		return
+	}
+	database.DB.Preload("Game.Tags").First(&session, session.ID)
+
+	sendToGroup(group.ID, hub.Event{
+		Type:    EventGroupSessionScheduled,
+		Payload: newGroupSessionResponse(session),
+	})
+
+	c.JSON(http.StatusCreated, newGroupSessionResponse(session))
+}
+
+// DeleteGroupSession godoc
+// @Summary      Cancel a group session
+// @Description  Removes a scheduled session from the group. Only the owner and officers can do this.
+// @Tags         groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path int true "Group ID"
+// @Param        sessionID path int true "Session ID"
+// @Success      200 {object} map[string]string "{"message": "Session cancelled"}"
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group or session not found"
+// @Router       /groups/{id}/sessions/{sessionID} [delete]
+func DeleteGroupSession(c *gin.Context) {
+	group, _, ok := loadGroupAsManager(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("id = ? AND group_id = ?", c.Param("sessionID"), group.ID).Delete(&models.GroupSession{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel session"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session cancelled"})
+}
+
+// GetGroupLobbies godoc
+// @Summary      Get group lobbies
+// @Description  Lists the group-only lobbies of a group.
+// @Tags         groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Group ID"
+// @Success      200 {array} LobbyResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group not found"
+// @Router       /groups/{id}/lobbies [get]
+func GetGroupLobbies(c *gin.Context) {
+	group, _, ok := loadGroupAsMember(c)
+	if !ok {
+		return
+	}
+
+	var lobbies []models.Lobby
+	if err := database.DB.Preload("Game").Preload("Host").Preload("Members").
+		Where("group_id = ?", group.ID).
+		Order("created_at DESC").
+		Find(&lobbies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve lobbies"})
+		return
+	}
+
+	response := []LobbyResponse{}
+	for _, lobby := range lobbies {
+		response = append(response, newLobbyResponse(lobby))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateGroupLobby godoc
+// @Summary      Create a group-only lobby
+// @Description  Creates a lobby only members of the group can find and join, making the creator the host.
+// @Tags         groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int        true "Group ID"
+// @Param        input body LobbyInput true "Lobby Info"
+// @Success      201 {object} LobbyResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group not found"
+// @Failure      409 {object} ErrorResponse "User is already in a lobby"
+// @Router       /groups/{id}/lobbies [post]
+func CreateGroupLobby(c *gin.Context) {
+	group, member, ok := loadGroupAsMember(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, member.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.CurrentLobbyID != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+		return
+	}
+
+	var input LobbyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lobby := models.Lobby{
+		GameID:        input.GameID,
+		HostID:        user.ID,
+		Description:   input.Description,
+		MaxPlayers:    input.MaxPlayers,
+		Region:        normalizeLocaleCode(input.Region),
+		Language:      normalizeLocaleCode(input.Language),
+		GroupID:       &group.ID,
+		MinReputation: input.MinReputation,
+	}
+	if err := createLobby(user, &lobby); err != nil {
+		if errors.Is(err, errInAnotherLobby) {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create lobby"})
+		return
+	}
+
+	sendToGroup(group.ID, hub.Event{
+		Type:    EventGroupLobbyCreated,
+		Payload: newLobbyResponse(lobby),
+	})
+
+	c.JSON(http.StatusCreated, newLobbyResponse(lobby))
+}
+
+// endregion
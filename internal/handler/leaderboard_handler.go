@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// leaderboardRefreshInterval is how often the leaderboard snapshot is recomputed.
+	leaderboardRefreshInterval = 15 * time.Minute
+
+	// leaderboardMinRatings is how many ratings a user needs on a scale or tag to be ranked on it.
+	leaderboardMinRatings = 3
+
+	// leaderboardActivityWindow is the period game leaderboards count played sessions over.
+	leaderboardActivityWindow = 30 * 24 * time.Hour
+
+	boardReputation  = "reputation"
+	boardHostedMonth = "hosted_month"
+)
+
+// region --- DTOs ---
+
+// LeaderboardEntryResponse describes a user's position on a leaderboard.
+type LeaderboardEntryResponse struct {
+	Rank  int                `json:"rank"`
+	Score float64            `json:"score"`
+	User  PublicUserResponse `json:"user"`
+}
+
+// LeaderboardResponse describes a page of a leaderboard and, for signed-in users, their own position on it.
+type LeaderboardResponse struct {
+	Board      string                     `json:"board"`
+	ComputedAt *time.Time                 `json:"computed_at,omitempty"`
+	Data       []LeaderboardEntryResponse `json:"data"`
+	Meta       PaginationMeta             `json:"meta"`
+	Me         *LeaderboardEntryResponse  `json:"me,omitempty"`
+}
+
+// MyRankResponse describes the current user's position on one leaderboard.
+type MyRankResponse struct {
+	Board      string    `json:"board"`
+	Rank       int       `json:"rank"`
+	Score      float64   `json:"score"`
+	TotalUsers int64     `json:"total_users"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// endregion
+
+// region --- Snapshot ---
+
+// leaderboardQueries select (board, user_id, score, rank) rows for every leaderboard. Each expects the snapshot time as its only argument.
+// Deleted users are left out of every board.
+var leaderboardQueries = []string{
+	// Overall reputation
+	`SELECT '` + boardReputation + `' AS board, id AS user_id, reputation_score AS score,
+		RANK() OVER (ORDER BY reputation_score DESC) AS rank, ?::timestamptz AS computed_at
+	FROM users WHERE deleted_at IS NULL AND reputation_score > 0`,
+
+	// Average score per rating scale
+	fmt.Sprintf(`SELECT 'scale:' || r.scale_id AS board, r.ratee_id AS user_id, AVG(r.score) AS score,
+		RANK() OVER (PARTITION BY r.scale_id ORDER BY AVG(r.score) DESC) AS rank, ?::timestamptz AS computed_at
+	FROM user_ratings AS r JOIN rating_scales AS s ON s.id = r.scale_id AND s.deleted_at IS NULL
+	JOIN users AS u ON u.id = r.ratee_id AND u.deleted_at IS NULL
+	GROUP BY r.scale_id, r.ratee_id HAVING COUNT(*) >= %d`, leaderboardMinRatings),
+
+	// Average score on the scales scoped to each tag
+	fmt.Sprintf(`SELECT 'tag:' || s.tag_id AS board, r.ratee_id AS user_id, AVG(r.score) AS score,
+		RANK() OVER (PARTITION BY s.tag_id ORDER BY AVG(r.score) DESC) AS rank, ?::timestamptz AS computed_at
+	FROM user_ratings AS r JOIN rating_scales AS s ON s.id = r.scale_id AND s.deleted_at IS NULL
+	JOIN users AS u ON u.id = r.ratee_id AND u.deleted_at IS NULL
+	WHERE s.tag_id IS NOT NULL
+	GROUP BY s.tag_id, r.ratee_id HAVING COUNT(*) >= %d`, leaderboardMinRatings),
+
+	// Sessions played per game over the activity window
+	fmt.Sprintf(`SELECT 'game:' || s.game_id AS board, p.user_id, COUNT(DISTINCT s.id) AS score,
+		RANK() OVER (PARTITION BY s.game_id ORDER BY COUNT(DISTINCT s.id) DESC) AS rank, ?::timestamptz AS computed_at
+	FROM lobby_session_participants AS p JOIN lobby_sessions AS s ON s.id = p.session_id
+	JOIN users AS u ON u.id = p.user_id AND u.deleted_at IS NULL
+	WHERE s.started_at >= NOW() - INTERVAL '%d seconds'
+	GROUP BY s.game_id, p.user_id`, int64(leaderboardActivityWindow.Seconds())),
+
+	// Lobbies hosted this calendar month
+	`SELECT '` + boardHostedMonth + `' AS board, s.host_id AS user_id, COUNT(*) AS score,
+		RANK() OVER (ORDER BY COUNT(*) DESC) AS rank, ?::timestamptz AS computed_at
+	FROM lobby_sessions AS s JOIN users AS u ON u.id = s.host_id AND u.deleted_at IS NULL
+	WHERE s.started_at >= DATE_TRUNC('month', NOW())
+	GROUP BY s.host_id`,
+}
+
+// refreshLeaderboards replaces the leaderboard snapshot in one transaction so readers never see a half-built board.
+func refreshLeaderboards() error {
+	now := time.Now()
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM leaderboard_entries").Error; err != nil {
+			return err
+		}
+		for _, query := range leaderboardQueries {
+			if err := tx.Exec("INSERT INTO leaderboard_entries (board, user_id, score, rank, computed_at) "+
+				"SELECT board, user_id, score, rank, computed_at FROM ("+query+") AS board_rows", now).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// StartLeaderboardWorker computes the leaderboard snapshot right away and then periodically.
+func StartLeaderboardWorker() {
+	go func() {
+		ticker := time.NewTicker(leaderboardRefreshInterval)
+		defer ticker.Stop()
+		for {
+			if err := refreshLeaderboards(); err != nil {
+				log.Printf("leaderboards: failed to refresh snapshot: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// serveLeaderboard answers with a page of the given board and the viewer's own entry.
+func serveLeaderboard(c *gin.Context, board string) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100 // Max limit
+	}
+	offset := (page - 1) * limit
+
+	var viewerID uint
+	if userID, ok := c.Get("userID"); ok {
+		viewerID = userID.(uint)
+	}
+
+	query := database.DB.Model(&models.LeaderboardEntry{}).Where("board = ?", board)
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count leaderboard entries"})
+		return
+	}
+
+	var entries []models.LeaderboardEntry
+	if err := query.Preload("User").Order("rank ASC, user_id ASC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve leaderboard"})
+		return
+	}
+
+	pagination := NewPaginatedResponse([]LeaderboardEntryResponse{}, totalItems, page, limit)
+	response := LeaderboardResponse{
+		Board: board,
+		Data:  []LeaderboardEntryResponse{},
+		Meta:  pagination.Meta,
+	}
+	for _, entry := range entries {
+		response.Data = append(response.Data, LeaderboardEntryResponse{
+			Rank:  entry.Rank,
+			Score: entry.Score,
+			User:  buildPublicUserResponse(entry.User, viewerID),
+		})
+		if response.ComputedAt == nil {
+			computedAt := entry.ComputedAt
+			response.ComputedAt = &computedAt
+		}
+	}
+
+	if viewerID != 0 {
+		var mine models.LeaderboardEntry
+		if err := database.DB.Preload("User").Where("board = ? AND user_id = ?", board, viewerID).First(&mine).Error; err == nil {
+			response.Me = &LeaderboardEntryResponse{
+				Rank:  mine.Rank,
+				Score: mine.Score,
+				User:  buildPublicUserResponse(mine.User, viewerID),
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// serveScopedLeaderboard answers with the board of the entity whose ID is in the path, e.g. "game:7".
+func serveScopedLeaderboard(c *gin.Context, prefix string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	serveLeaderboard(c, fmt.Sprintf("%s:%d", prefix, id))
+}
+
+// endregion
+
+// GetReputationLeaderboard godoc
+// @Summary      Get the reputation leaderboard
+// @Description  Ranks users by overall reputation score. Leaderboards are recomputed every 15 minutes.
+// @Tags         leaderboards
+// @Produce      json
+// @Param        page  query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} LeaderboardResponse
+// @Router       /leaderboards/reputation [get]
+func GetReputationLeaderboard(c *gin.Context) {
+	serveLeaderboard(c, boardReputation)
+}
+
+// GetHostedLeaderboard godoc
+// @Summary      Get the most active hosts this month
+// @Description  Ranks users by the number of lobbies they hosted in the current calendar month.
+// @Tags         leaderboards
+// @Produce      json
+// @Param        page  query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} LeaderboardResponse
+// @Router       /leaderboards/hosted-month [get]
+func GetHostedLeaderboard(c *gin.Context) {
+	serveLeaderboard(c, boardHostedMonth)
+}
+
+// GetScaleLeaderboard godoc
+// @Summary      Get a rating scale leaderboard
+// @Description  Ranks users by their average score on a rating scale. Users need at least 3 ratings on the scale to be ranked.
+// @Tags         leaderboards
+// @Produce      json
+// @Param        id    path  int true  "Scale ID"
+// @Param        page  query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} LeaderboardResponse
+// @Failure      400 {object} ErrorResponse
+// @Router       /leaderboards/scales/{id} [get]
+func GetScaleLeaderboard(c *gin.Context) {
+	serveScopedLeaderboard(c, "scale")
+}
+
+// GetTagLeaderboard godoc
+// @Summary      Get a tag leaderboard
+// @Description  Ranks users by their average score on the rating scales scoped to a tag, e.g. the best shooter players. Users need at least 3 such ratings to be ranked.
+// @Tags         leaderboards
+// @Produce      json
+// @Param        id    path  int true  "Tag ID"
+// @Param        page  query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} LeaderboardResponse
+// @Failure      400 {object} ErrorResponse
+// @Router       /leaderboards/tags/{id} [get]
+func GetTagLeaderboard(c *gin.Context) {
+	serveScopedLeaderboard(c, "tag")
+}
+
+// GetGameLeaderboard godoc
+// @Summary      Get a game leaderboard
+// @Description  Ranks users by the number of sessions they played in a game over the last 30 days.
+// @Tags         leaderboards
+// @Produce      json
+// @Param        id    path  int true  "Game ID"
+// @Param        page  query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} LeaderboardResponse
+// @Failure      400 {object} ErrorResponse
+// @Router       /leaderboards/games/{id} [get]
+func GetGameLeaderboard(c *gin.Context) {
+	serveScopedLeaderboard(c, "game")
+}
+
+// GetMyRanks godoc
+// @Summary      Get my leaderboard ranks
+// @Description  Lists the current user's rank on every leaderboard they appear on.
+// @Tags         leaderboards
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} MyRankResponse
+// @Router       /users/me/ranks [get]
+func GetMyRanks(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var entries []models.LeaderboardEntry
+	// Overall boards first, then scoped boards by name
+	if err := database.DB.Where("user_id = ?", userID).Order("board LIKE '%:%' ASC, board ASC").Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ranks"})
+		return
+	}
+
+	type boardSize struct {
+		Board string
+		Total int64
+	}
+	boards := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		boards = append(boards, entry.Board)
+	}
+	totals := make(map[string]int64, len(entries))
+	if len(boards) > 0 {
+		var sizes []boardSize
+		database.DB.Model(&models.LeaderboardEntry{}).
+			Select("board, COUNT(*) AS total").
+			Where("board IN ?", boards).
+			Group("board").
+			Scan(&sizes)
+		for _, size := range sizes {
+			totals[size.Board] = size.Total
+		}
+	}
+
+	response := []MyRankResponse{}
+	for _, entry := range entries {
+		response = append(response, MyRankResponse{
+			Board:      entry.Board,
+			Rank:       entry.Rank,
+			Score:      entry.Score,
+			TotalUsers: totals[entry.Board],
+			ComputedAt: entry.ComputedAt,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
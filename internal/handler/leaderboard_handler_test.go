@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"playmatch/backend/internal/models"
+	"testing"
+	"time"
+)
+
+func TestRefreshLeaderboardsSkipsDeletedUsers(t *testing.T) {
+	db := testDB(t)
+
+	active := createTestUser(t, db, "active")
+	deleted := createTestUser(t, db, "deleted")
+	for _, host := range []models.User{active, deleted} {
+		session := createTestSession(t, db, host, time.Now(), nil)
+		createTestParticipant(t, db, session.ID, host.ID, session.StartedAt, nil)
+	}
+	if err := db.Delete(&deleted).Error; err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+
+	if err := refreshLeaderboards(); err != nil {
+		t.Fatalf("refreshLeaderboards: %v", err)
+	}
+
+	var boards []string
+	db.Model(&models.LeaderboardEntry{}).Where("user_id = ?", deleted.ID).Pluck("board", &boards)
+	if len(boards) > 0 {
+		t.Errorf("deleted user is on boards %v", boards)
+	}
+	var hosted int64
+	db.Model(&models.LeaderboardEntry{}).Where("user_id = ? AND board = ?", active.ID, boardHostedMonth).Count(&hosted)
+	if hosted != 1 {
+		t.Errorf("active user is on the hosted board %d times, want once", hosted)
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// region --- DTOs ---
+
+// KickInput defines the optional parameters of a kick.
+type KickInput struct {
+	Ban             bool   `json:"ban"`                                           // Also ban the user from rejoining
+	DurationMinutes int    `json:"duration_minutes" binding:"min=0" example:"60"` // Ban duration, 0 means for the lifetime of the lobby
+	Reason          string `json:"reason" binding:"max=255" example:"Griefing"`   // Shown in the system message
+}
+
+// LobbyBanResponse defines the structure of a lobby ban entry.
+type LobbyBanResponse struct {
+	User       PublicUserResponse `json:"user"`
+	BannedByID uint               `json:"banned_by_id"`
+	Reason     string             `json:"reason"`
+	ExpiresAt  *time.Time         `json:"expires_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+func newLobbyBanResponse(ban models.LobbyBan) LobbyBanResponse {
+	return LobbyBanResponse{
+		User:       buildPublicUserResponse(ban.User, 0),
+		BannedByID: ban.BannedByID,
+		Reason:     ban.Reason,
+		ExpiresAt:  ban.ExpiresAt,
+		CreatedAt:  ban.CreatedAt,
+	}
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// findActiveLobbyBan returns the ban of the user in the lobby if one is still in effect.
+func findActiveLobbyBan(db *gorm.DB, lobbyID, userID uint) (*models.LobbyBan, bool) {
+	var ban models.LobbyBan
+	if err := db.Where("lobby_id = ? AND user_id = ?", lobbyID, userID).First(&ban).Error; err != nil {
+		return nil, false
+	}
+	if !ban.IsActive(time.Now()) {
+		return nil, false
+	}
+	return &ban, true
+}
+
+// lobbyBanError builds the error message returned to a banned user.
+func lobbyBanError(ban *models.LobbyBan) string {
+	if ban.ExpiresAt == nil {
+		return "You are banned from this lobby"
+	}
+	return fmt.Sprintf("You are banned from this lobby until %s", ban.ExpiresAt.Format(time.RFC3339))
+}
+
+// endregion
+
+// GetLobbyBans godoc
+// @Summary      List bans of my lobby (Host or co-host)
+// @Description  Returns the users currently banned from the user's lobby.
+// @Tags         lobbies
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array}  LobbyBanResponse
+// @Failure      403 {object} ErrorResponse "Only the host or a co-host can view bans"
+// @Failure      404 {object} ErrorResponse "User is not in a lobby"
+// @Router       /lobbies/me/bans [get]
+func GetLobbyBans(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var user models.User
+	if err := database.DB.Preload("CurrentLobby").First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+		return
+	}
+
+	if !canManageLobby(user.CurrentLobby, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host or a co-host can view bans"})
+		return
+	}
+
+	var bans []models.LobbyBan
+	if err := database.DB.Preload("User").
+		Where("lobby_id = ? AND (expires_at IS NULL OR expires_at > ?)", user.CurrentLobby.ID, time.Now()).
+		Order("created_at DESC").
+		Find(&bans).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bans"})
+		return
+	}
+
+	response := []LobbyBanResponse{}
+	for _, ban := range bans {
+		response = append(response, newLobbyBanResponse(ban))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UnbanUser godoc
+// @Summary      Lift a ban in my lobby (Host or co-host)
+// @Description  Removes a user from the ban list of the user's lobby so they can join again.
+// @Tags         lobbies
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userID  path int true "User ID of the banned user"
+// @Success      200 {object} map[string]string "{"message": "User unbanned"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Only the host or a co-host can lift bans"
+// @Failure      404 {object} ErrorResponse "User is not in a lobby or ban not found"
+// @Router       /lobbies/me/bans/{userID} [delete]
+func UnbanUser(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	bannedUserID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Preload("CurrentLobby").First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+		return
+	}
+
+	if !canManageLobby(user.CurrentLobby, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host or a co-host can lift bans"})
+		return
+	}
+
+	result := database.DB.Where("lobby_id = ? AND user_id = ?", user.CurrentLobby.ID, bannedUserID).Delete(&models.LobbyBan{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lift ban"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ban not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unbanned"})
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"playmatch/backend/internal/database"
@@ -16,18 +17,39 @@ import (
 // region --- DTOs ---
 
 type LobbyInput struct {
-	GameID      uint   `json:"game_id" binding:"required"`
-	Description string `json:"description"`
-	MaxPlayers  int    `json:"max_players" binding:"required,min=2,max=10"`
+	GameID        uint    `json:"game_id" binding:"required"`
+	Description   string  `json:"description"`
+	MaxPlayers    int     `json:"max_players" binding:"required,min=2,max=10"`
+	Region        string  `json:"region" binding:"max=20" example:"eu"`
+	Language      string  `json:"language" binding:"max=10" example:"en"`
+	MinReputation float64 `json:"min_reputation" binding:"min=0"` // Users with a lower reputation score cannot join
 }
 
 type LobbyResponse struct {
-	ID          uint                 `json:"id"`
-	Description string               `json:"description"`
-	MaxPlayers  int                  `json:"max_players"`
-	Game        GameResponse         `json:"game"`
-	Host        PublicUserResponse   `json:"host"`
-	Members     []PublicUserResponse `json:"members"`
+	ID              uint                 `json:"id"`
+	Description     string               `json:"description"`
+	MaxPlayers      int                  `json:"max_players"`
+	Region          string               `json:"region,omitempty"`
+	Language        string               `json:"language,omitempty"`
+	Game            GameResponse         `json:"game"`
+	Host            PublicUserResponse   `json:"host"`
+	CoHostIDs       []uint               `json:"co_host_ids"`
+	ReadyMemberIDs  []uint               `json:"ready_member_ids"`
+	GroupID         *uint                `json:"group_id,omitempty"`
+	MinReputation   float64              `json:"min_reputation"`
+	SlowModeSeconds int                  `json:"slow_mode_seconds"` // Minimum delay between chat messages of regular members, 0 when off
+	Announcement    string               `json:"announcement,omitempty"`
+	Members         []PublicUserResponse `json:"members"`
+}
+
+// ReadyInput defines whether the user is ready to play.
+type ReadyInput struct {
+	Ready *bool `json:"ready" binding:"required"`
+}
+
+// TransferHostInput defines the member who should become the new host.
+type TransferHostInput struct {
+	UserID uint `json:"user_id" binding:"required"`
 }
 
 // PaginatedLobbyResponse defines the structure for a paginated list of lobbies.
@@ -38,22 +60,31 @@ type PaginatedLobbyResponse struct {
 
 // PaginatedMessageResponse defines the structure for a paginated list of messages.
 type PaginatedMessageResponse struct {
-	Data []MessageResponse `json:"data"`
-	Meta PaginationMeta    `json:"meta"`
+	Data   []MessageResponse `json:"data"`
+	Meta   PaginationMeta    `json:"meta"`
+	Pinned []MessageResponse `json:"pinned,omitempty"` // Pinned messages of a lobby chat
 }
 
 type MessageInput struct {
-	Content string `json:"content" binding:"required"`
+	Content   string `json:"content" binding:"required,max=2000"`
+	ReplyToID *uint  `json:"reply_to_id"` // Optional, an earlier message of the same chat
 }
 
 type MessageResponse struct {
-	ID        uint                     `json:"id"`
-	LobbyID   uint                     `json:"lobby_id"`
-	UserID    *uint                    `json:"user_id,omitempty"`
-	Type      models.MessageType       `json:"type"`
-	Content   string                   `json:"content"`
-	CreatedAt time.Time                `json:"created_at"`
-	User      *PublicUserResponse      `json:"user,omitempty"`
+	ID             uint                  `json:"id"`
+	LobbyID        *uint                 `json:"lobby_id,omitempty"`
+	GroupID        *uint                 `json:"group_id,omitempty"`
+	ConversationID *uint                 `json:"conversation_id,omitempty"`
+	UserID         *uint                 `json:"user_id,omitempty"`
+	Type           models.MessageType    `json:"type"`
+	Content        string                `json:"content"`
+	CreatedAt      time.Time             `json:"created_at"`
+	EditedAt       *time.Time            `json:"edited_at,omitempty"` // Set when the message was edited
+	PinnedAt       *time.Time            `json:"pinned_at,omitempty"` // Set while the message is pinned in the lobby chat
+	User           *PublicUserResponse   `json:"user,omitempty"`
+	ReplyTo        *ReplyPreviewResponse `json:"reply_to,omitempty"`
+	Mentions       []MentionResponse     `json:"mentions"`
+	Reactions      []ReactionResponse    `json:"reactions"`
 }
 
 func newLobbyResponse(lobby models.Lobby) LobbyResponse {
@@ -72,12 +103,20 @@ func newLobbyResponse(lobby models.Lobby) LobbyResponse {
 	gameResponse := newGameResponse(lobby.Game, dummyFavoriteIDs)
 
 	return LobbyResponse{
-		ID:          lobby.ID,
-		Description: lobby.Description,
-		MaxPlayers:  lobby.MaxPlayers,
-		Game:        gameResponse,
-		Host:        hostResponse,
-		Members:     memberResponses,
+		ID:              lobby.ID,
+		Description:     lobby.Description,
+		MaxPlayers:      lobby.MaxPlayers,
+		Region:          lobby.Region,
+		Language:        lobby.Language,
+		Game:            gameResponse,
+		Host:            hostResponse,
+		CoHostIDs:       lobbyCoHostIDs(lobby),
+		ReadyMemberIDs:  lobbyReadyMemberIDs(lobby),
+		GroupID:         lobby.GroupID,
+		MinReputation:   lobby.MinReputation,
+		SlowModeSeconds: lobby.SlowModeSeconds,
+		Announcement:    lobby.Announcement,
+		Members:         memberResponses,
 	}
 }
 
@@ -88,13 +127,20 @@ func newMessageResponse(message models.Message) MessageResponse {
 		userResponse = &tempUserResponse
 	}
 	return MessageResponse{
-		ID:        message.ID,
-		LobbyID:   message.LobbyID,
-		UserID:    message.UserID,
-		Type:      message.Type,
-		Content:   message.Content,
-		CreatedAt: message.CreatedAt,
-		User:      userResponse,
+		ID:             message.ID,
+		LobbyID:        message.LobbyID,
+		GroupID:        message.GroupID,
+		ConversationID: message.ConversationID,
+		UserID:         message.UserID,
+		Type:           message.Type,
+		Content:        message.Content,
+		CreatedAt:      message.CreatedAt,
+		EditedAt:       message.EditedAt,
+		PinnedAt:       message.PinnedAt,
+		User:           userResponse,
+		ReplyTo:        newReplyPreviewResponse(message),
+		Mentions:       newMentionResponses(message.Mentions),
+		Reactions:      newReactionResponses(message.Reactions),
 	}
 }
 
@@ -105,9 +151,13 @@ func newMessageResponse(message models.Message) MessageResponse {
 // SubscribeToLobbyEvents godoc
 // @Summary      Subscribe to my lobby events (SSE)
 // @Description  Establishes a Server-Sent Events connection to receive real-time updates for the current user's lobby.
+// @Description  Every event carries an ID. A reconnecting client sends the last one it received as `Last-Event-ID` and gets the events it missed replayed first; if they are no longer buffered it gets a `resync` event and should reload the chat history with `after`.
+// @Description  The stream ends when the user leaves the lobby or is kicked or banned from it.
 // @Tags         lobbies-chat
 // @Produce      text/event-stream
 // @Security     BearerAuth
+// @Param        Last-Event-ID header string false "ID of the last event received before reconnecting"
+// @Param        last_event_id query  int    false "Same as the Last-Event-ID header"
 // @Success      200 {string} string "Event stream"
 // @Failure      401 {object} ErrorResponse
 // @Failure      404 {object} ErrorResponse "User is not in a lobby"
@@ -123,141 +173,67 @@ func SubscribeToLobbyEvents(c *gin.Context) {
 	}
 	lobbyID := *user.CurrentLobbyID
 
-	clientChan := make(hub.Client)
-	hub.GlobalHub.Subscribe(lobbyID, clientChan)
+	clientChan := hub.GlobalHub.NewClient()
+	var missed []hub.Message
+	if eventID, ok := lastEventID(c); ok {
+		missed = hub.GlobalHub.Resume(lobbyID, user.ID, clientChan, eventID)
+	} else {
+		hub.GlobalHub.Subscribe(lobbyID, user.ID, clientChan)
+	}
 
 	defer func() {
 		hub.GlobalHub.Unsubscribe(lobbyID, clientChan)
 	}()
 
-	c.Stream(func(w io.Writer) bool { // Changed from http.ResponseWriter to io.Writer
-		select {
-		case message := <-clientChan:
-			c.SSEvent("message", string(message))
-			return true
-		case <-c.Request.Context().Done():
-			return false
-		}
-	})
+	streamEvents(c, clientChan, missed)
 }
 
 // PostMessage godoc
 // @Summary      Post a message to my lobby chat
-// @Description  Sends a new chat message to the user's current lobby.
+// @Description  Sends a new chat message to the user's current lobby. It can reply to an earlier message of the chat, and `@nickname` mentions of lobby members are notified with a `mention` event and kept in their mentions inbox.
+// @Description  Messages starting with `/` are slash commands: `/help`, `/me <action>`, `/roll [N]d<sides>[+modifier]`, `/coinflip`, `/pick a, b, c`, `/ready [on|off]` and, for the host and co-hosts, `/kick <nickname> [reason]`, `/slowmode <seconds|off>` and `/announce [text]`. Their result is posted as a system message and broadcast as `new_message`, and the request is answered with 200 and a ChatCommandResponse. The `/help` listing is not posted; it is only returned to the caller in `text`. Start a message with `//` to send text beginning with a slash.
+// @Description  Messages are limited to 2000 characters. Sending too many messages, or sending faster than the lobby's slow mode allows, is answered with 429 and a Retry-After header; repeating the previous message within 30 seconds is answered with 409. Blocked words are masked with asterisks, or the message is refused with 400 when the server rejects them.
 // @Tags         lobbies-chat
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        input body      MessageInput true  "Message Content"
 // @Success      201   {object}  MessageResponse
+// @Success      200   {object}  ChatCommandResponse "The message was a slash command"
 // @Failure      400   {object}  ErrorResponse
 // @Failure      401   {object}  ErrorResponse
+// @Failure      403   {object}  ErrorResponse "Only the host or a co-host can use this command"
 // @Failure      404   {object}  ErrorResponse "User is not in a lobby"
+// @Failure      409   {object}  ErrorResponse "You already sent this message"
+// @Failure      429   {object}  ErrorResponse "Rate limit or slow mode, see Retry-After"
 // @Failure      500   {object}  ErrorResponse
 // @Router       /lobbies/me/messages [post]
 func PostMessage(c *gin.Context) {
-	userID, _ := c.Get("userID")
-
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
-		return
+	if scope, ok := lobbyChatScope(c); ok {
+		postChatMessage(c, scope, EventNewMessage)
 	}
-	lobbyID := *user.CurrentLobbyID
-
-	var input MessageInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	newMessage := models.Message{
-		LobbyID: lobbyID,
-		UserID:  &user.ID, // User-sent message
-		Type:    models.MessageTypeText,
-		Content: input.Content,
-	}
-
-	if err := database.DB.Create(&newMessage).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post message"})
-		return
-	}
-	
-	// Preload user for the message response
-	database.DB.Preload("User").First(&newMessage, newMessage.ID)
-
-	// Broadcast new message event
-	hub.GlobalHub.Broadcast(lobbyID, hub.Event{
-		Type:    "new_message",
-		Payload: newMessageResponse(newMessage),
-	})
-
-	c.JSON(http.StatusCreated, newMessageResponse(newMessage))
 }
 
 // GetMessages godoc
 // @Summary      Get my lobby chat messages
 // @Description  Retrieves a paginated history of messages for the user's current lobby.
+// @Description  Pages shift as new messages arrive. To page without gaps or duplicates, pass a message ID as `before` (older messages) or `after` (newer messages) instead of `page`; the response is then a MessageCursorResponse.
+// @Description  Both responses also list the pinned messages of the lobby in `pinned`, oldest pin first.
 // @Tags         lobbies-chat
 // @Produce      json
 // @Security     BearerAuth
 // @Param        page query     int  false "Page number" default(1)
 // @Param        limit query    int  false "Items per page" default(50)
+// @Param        before query   int  false "Only messages older than this message ID"
+// @Param        after query    int  false "Only messages newer than this message ID"
 // @Success      200 {object} PaginatedMessageResponse
 // @Failure      401 {object} ErrorResponse
 // @Failure      404 {object} ErrorResponse "User is not in a lobby"
 // @Router       /lobbies/me/messages [get]
 func GetMessages(c *gin.Context) {
-	userID, _ := c.Get("userID")
-
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
-		return
-	}
-	lobbyID := *user.CurrentLobbyID
-
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if page < 1 {
-		page = 1
-	}
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
-	if limit < 1 {
-		limit = 50
+	if scope, ok := lobbyChatScope(c); ok {
+		listChatMessages(c, scope)
 	}
-	offset := (page - 1) * limit
-
-	var messages []models.Message
-	var totalItems int64
-
-	query := database.DB.Model(&models.Message{}).Where("lobby_id = ?", lobbyID)
-
-	// Count total items
-	if err := query.Count(&totalItems).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count messages"})
-		return
-	}
-
-	// Fetch paginated items
-	if err := query.Preload("User").
-		Order("created_at DESC"). // Latest messages first
-		Limit(limit).Offset(offset).
-		Find(&messages).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages"})
-		return
-	}
-
-	// Reverse messages to show oldest first in the paginated set
-	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
-		messages[i], messages[j] = messages[j], messages[i]
-	}
-
-	var response []MessageResponse
-	for _, msg := range messages {
-		response = append(response, newMessageResponse(msg))
-	}
-
-	c.JSON(http.StatusOK, NewPaginatedResponse(response, totalItems, page, limit))
 }
 
 // PostUserTyping godoc
@@ -278,20 +254,47 @@ func PostUserTyping(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
 		return
 	}
-	lobbyID := *user.CurrentLobbyID
 
-	// Broadcast typing event
-	hub.GlobalHub.Broadcast(lobbyID, hub.Event{
-		Type: "user_typing",
-		Payload: map[string]interface{}{
-			"user_id": user.ID,
-			"nickname": user.Nickname,
-		},
-	})
+	broadcastTyping(user)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Typing signal sent"})
 }
 
+// SetLobbyReady godoc
+// @Summary      Mark myself ready in my lobby
+// @Description  Sets whether the current user is ready to play. Members receive a `user_ready` event and the lobby lists the ready members in `ready_member_ids`. Leaving the lobby resets the flag.
+// @Tags         lobbies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body     ReadyInput true "Ready state"
+// @Success      200 {object} UserReadyPayload
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "User is not in a lobby"
+// @Router       /lobbies/me/ready [put]
+func SetLobbyReady(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input ReadyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+		return
+	}
+
+	if err := setLobbyReady(user, *input.Ready); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update ready state"})
+		return
+	}
+
+	c.JSON(http.StatusOK, UserReadyPayload{UserID: user.ID, Ready: *input.Ready})
+}
+
 // CreateLobby godoc
 // @Summary      Create a new lobby
 // @Description  Creates a new lobby, making the creator the host.
@@ -325,58 +328,37 @@ func CreateLobby(c *gin.Context) {
 	}
 
 	lobby := models.Lobby{
-		GameID:      input.GameID,
-		HostID:      user.ID,
-		Description: input.Description,
-		MaxPlayers:  input.MaxPlayers,
-	}
-
-	// Use a transaction to ensure both lobby creation and user update succeed
-	tx := database.DB.Begin()
-
-	if err := tx.Create(&lobby).Error; err != nil {
-		tx.Rollback()
+		GameID:        input.GameID,
+		HostID:        user.ID,
+		Description:   input.Description,
+		MaxPlayers:    input.MaxPlayers,
+		Region:        normalizeLocaleCode(input.Region),
+		Language:      normalizeLocaleCode(input.Language),
+		MinReputation: input.MinReputation,
+	}
+
+	if err := createLobby(user, &lobby); err != nil {
+		if errors.Is(err, errInAnotherLobby) {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create lobby"})
 		return
 	}
 
-	user.CurrentLobbyID = &lobby.ID
-	if err := tx.Save(&user).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user's lobby"})
-		return
-	}
-
-	tx.Commit()
-
-	// Reload lobby with all associations
-	database.DB.Preload("Game").Preload("Host").Preload("Members").First(&lobby, lobby.ID)
-
-	// Post system message
-	systemMessage := models.Message{
-		LobbyID: lobby.ID,
-		UserID:  nil, // System message
-		Type:    models.MessageTypeSystem,
-		Content: fmt.Sprintf("User %s created the lobby.", user.Nickname),
-	}
-	database.DB.Create(&systemMessage) // Not in transaction, best-effort
-
-	// Broadcast event
-	hub.GlobalHub.Broadcast(lobby.ID, hub.Event{
-		Type:    "lobby_created",
-		Payload: newLobbyResponse(lobby),
-	})
-
 	c.JSON(http.StatusCreated, newLobbyResponse(lobby))
 }
 
 // SearchLobbies godoc
 // @Summary      Search for lobbies
-// @Description  Gets a paginated list of available lobbies, optionally filtered by game.
+// @Description  Gets a paginated list of available lobbies, optionally filtered by game, region and language. Group-only lobbies are listed to members of their group only.
 // @Tags         lobbies
 // @Produce      json
 // @Security     BearerAuth
 // @Param        game_id query int false "Filter by Game ID"
+// @Param        region   query string false "Filter by region"
+// @Param        language query string false "Filter by language"
+// @Param        sort     query string false "Sort order: host_reputation puts lobbies with the most reputable hosts first" Enums(host_reputation)
 // @Param        page    query int false "Page number" default(1)
 // @Param        limit   query int false "Items per page" default(10)
 // @Success      200 {object} PaginatedLobbyResponse
@@ -392,6 +374,15 @@ func SearchLobbies(c *gin.Context) {
 	}
 	offset := (page - 1) * limit
 	gameID := c.Query("game_id")
+	region := normalizeLocaleCode(c.Query("region"))
+	language := normalizeLocaleCode(c.Query("language"))
+
+	// Group-only lobbies are only listed to members of their group
+	var viewerID uint
+	if userID, ok := c.Get("userID"); ok {
+		viewerID = userID.(uint)
+	}
+	viewerGroupIDs := database.DB.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", viewerID)
 
 	var lobbies []models.Lobby
 	var totalItems int64
@@ -405,6 +396,13 @@ func SearchLobbies(c *gin.Context) {
 	if gameID != "" {
 		baseQuery = baseQuery.Where("lobbies.game_id = ?", gameID)
 	}
+	if region != "" {
+		baseQuery = baseQuery.Where("lobbies.region = ?", region)
+	}
+	if language != "" {
+		baseQuery = baseQuery.Where("lobbies.language = ?", language)
+	}
+	baseQuery = baseQuery.Where("lobbies.group_id IS NULL OR lobbies.group_id IN (?)", viewerGroupIDs)
 
 	// For counting, we need a subquery to correctly handle the GROUP and HAVING clauses.
 	// We select only the ID in the subquery for efficiency.
@@ -427,6 +425,20 @@ func SearchLobbies(c *gin.Context) {
 	if gameID != "" {
 		dataQuery = dataQuery.Where("lobbies.game_id = ?", gameID)
 	}
+	if region != "" {
+		dataQuery = dataQuery.Where("lobbies.region = ?", region)
+	}
+	if language != "" {
+		dataQuery = dataQuery.Where("lobbies.language = ?", language)
+	}
+	dataQuery = dataQuery.Where("lobbies.group_id IS NULL OR lobbies.group_id IN (?)", viewerGroupIDs)
+
+	if c.Query("sort") == "host_reputation" {
+		dataQuery = dataQuery.
+			Joins("JOIN users AS hosts ON hosts.id = lobbies.host_id").
+			Order("MAX(hosts.reputation_score) DESC").
+			Order("lobbies.created_at ASC")
+	}
 
 	if err := dataQuery.Offset(offset).Limit(limit).Find(&lobbies).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve lobbies"})
@@ -491,6 +503,7 @@ func GetMyLobby(c *gin.Context) {
 // @Security     BearerAuth
 // @Param        id path int true "Lobby ID"
 // @Success      200 {object} map[string]string "{"message": "Joined lobby successfully"}"
+// @Failure      403 {object} ErrorResponse "User is banned from this lobby or not in its group"
 // @Failure      404 {object} ErrorResponse "Lobby not found"
 // @Failure      409 {object} ErrorResponse "Lobby is full or user is in another lobby"
 // @Router       /lobbies/{id}/join [post]
@@ -512,29 +525,25 @@ func JoinLobby(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Lobby not found"})
 		return
 	}
-	if len(lobby.Members) >= lobby.MaxPlayers {
-		c.JSON(http.StatusConflict, gin.H{"error": "Lobby is full"})
+	if reason := lobbyAccessError(database.DB, lobby, user.ID); reason != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": reason})
+		return
+	}
+	// Slots offered to waitlisted users are reserved for them
+	if len(lobby.Members)+int(countReservedSlots(database.DB, lobby.ID, user.ID)) >= lobby.MaxPlayers {
+		c.JSON(http.StatusConflict, gin.H{"error": "Lobby is full, join its waitlist instead"})
 		return
 	}
 
 	// Join lobby
-	lobbyIDUint := uint(lobbyID)
-	database.DB.Model(&user).Update("current_lobby_id", &lobbyIDUint)
-
-	// Post system message
-	systemMessage := models.Message{
-		LobbyID: lobby.ID,
-		UserID:  nil, // System message
-		Type:    models.MessageTypeSystem,
-		Content: fmt.Sprintf("User %s joined the lobby.", user.Nickname),
+	if err := addUserToLobby(user, lobby.ID); err != nil {
+		if errors.Is(err, errInAnotherLobby) {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join lobby"})
+		return
 	}
-	database.DB.Create(&systemMessage)
-
-	// Broadcast event
-	hub.GlobalHub.Broadcast(lobby.ID, hub.Event{
-		Type:    "user_joined",
-		Payload: buildPublicUserResponse(user, 0), // User who joined
-	})
 
 	c.JSON(http.StatusOK, gin.H{"message": "Joined lobby successfully"})
 }
@@ -555,106 +564,25 @@ func LeaveLobby(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
 		return
 	}
-	
-	lobbyID := *user.CurrentLobbyID
-	lobby := user.CurrentLobby
 
-	// Use transaction for leaving logic
-	tx := database.DB.Begin()
-	
-	// User leaves the lobby - use Updates with map or struct tag to set NULL
-	if err := tx.Model(&user).Select("CurrentLobbyID").Updates(map[string]interface{}{"current_lobby_id": nil}).Error; err != nil {
-		tx.Rollback()
+	if err := leaveLobby(user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave lobby"})
 		return
 	}
-	
-	// NOW load remaining members (after current user left)
-	var remainingMembers []models.User
-	if err := tx.Where("current_lobby_id = ?", lobbyID).Find(&remainingMembers).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check lobby members"})
-		return
-	}
-	
-	// Post system message for user leaving
-	systemMessage := models.Message{
-		LobbyID: lobbyID,
-		UserID:  nil,
-		Type:    models.MessageTypeSystem,
-		Content: fmt.Sprintf("User %s left the lobby.", user.Nickname),
-	}
-	tx.Create(&systemMessage)
-	
-	// If no one is left, delete the lobby
-	if len(remainingMembers) == 0 {
-		if err := tx.Delete(&lobby).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete empty lobby"})
-			return
-		}
-		
-		if err := tx.Commit().Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit failed"})
-			return
-		}
-		
-		hub.GlobalHub.Broadcast(lobbyID, hub.Event{
-			Type:    "lobby_deleted",
-			Payload: gin.H{"lobby_id": lobbyID},
-		})
-		
-		c.JSON(http.StatusOK, gin.H{"message": "Left lobby successfully"})
-		return
-	}
-	
-	// If the user was the host, promote the next member
-	if lobby.HostID == user.ID {
-		nextHost := remainingMembers[0]
-		
-		if err := tx.Model(&lobby).Update("host_id", nextHost.ID).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer host"})
-			return
-		}
-		
-		newHostMessage := models.Message{
-			LobbyID: lobbyID,
-			UserID:  nil,
-			Type:    models.MessageTypeSystem,
-			Content: fmt.Sprintf("User %s is now the host.", nextHost.Nickname),
-		}
-		tx.Create(&newHostMessage)
-		
-		hub.GlobalHub.Broadcast(lobbyID, hub.Event{
-			Type:    "host_changed",
-			Payload: buildPublicUserResponse(nextHost, 0),
-		})
-	}
-	
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit failed"})
-		return
-	}
-	
-	hub.GlobalHub.Broadcast(lobbyID, hub.Event{
-		Type:    "user_left",
-		Payload: buildPublicUserResponse(user, 0),
-	})
-	
+
 	c.JSON(http.StatusOK, gin.H{"message": "Left lobby successfully"})
 }
 
 // UpdateLobby godoc
-// @Summary      Update my lobby (Host only)
-// @Description  Updates the details of the user's current lobby. Only the host can perform this action.
+// @Summary      Update my lobby (Host or co-host)
+// @Description  Updates the details of the user's current lobby. Only the host or a co-host can perform this action.
 // @Tags         lobbies
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        input body      LobbyInput true  "New Lobby Info"
 // @Success      200   {object}  LobbyResponse
-// @Failure      403   {object}  ErrorResponse "Only the host can update the lobby"
+// @Failure      403   {object}  ErrorResponse "Only the host or a co-host can update the lobby"
 // @Failure      404   {object}  ErrorResponse "User is not in a lobby"
 // @Router       /lobbies/me [put]
 func UpdateLobby(c *gin.Context) {
@@ -668,8 +596,8 @@ func UpdateLobby(c *gin.Context) {
 
 	lobby := user.CurrentLobby
 
-	if lobby.HostID != userID.(uint) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can update the lobby"})
+	if !canManageLobby(lobby, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host or a co-host can update the lobby"})
 		return
 	}
 
@@ -685,6 +613,9 @@ func UpdateLobby(c *gin.Context) {
 	lobby.Description = input.Description
 	lobby.MaxPlayers = input.MaxPlayers
 	lobby.GameID = input.GameID
+	lobby.Region = normalizeLocaleCode(input.Region)
+	lobby.Language = normalizeLocaleCode(input.Language)
+	lobby.MinReputation = input.MinReputation
 
 	database.DB.Save(&lobby)
 
@@ -693,8 +624,10 @@ func UpdateLobby(c *gin.Context) {
 
 	// Post system message if game changed
 	if oldGameID != lobby.GameID {
+		recordSessionGame(database.DB, lobby.ID, lobby.GameID)
+
 		systemMessage := models.Message{
-			LobbyID: lobby.ID,
+			LobbyID: &lobby.ID,
 			UserID:  nil, // System message
 			Type:    models.MessageTypeSystem,
 			Content: fmt.Sprintf("Lobby game changed to %s.", lobby.Game.Name),
@@ -702,74 +635,246 @@ func UpdateLobby(c *gin.Context) {
 		database.DB.Create(&systemMessage)
 		// Broadcast event
 		hub.GlobalHub.Broadcast(lobby.ID, hub.Event{
-			Type:    "lobby_game_changed",
+			Type:    EventLobbyGameChanged,
 			Payload: newGameResponse(lobby.Game, nil), // Updated game info
 		})
 	}
 	// Broadcast general lobby update
 	hub.GlobalHub.Broadcast(lobby.ID, hub.Event{
-		Type:    "lobby_updated",
+		Type:    EventLobbyUpdated,
 		Payload: newLobbyResponse(*lobby),
 	})
+	// A larger lobby may have room for waitlisted users
+	offerWaitlistSlots(lobby.ID)
 
 	c.JSON(http.StatusOK, newLobbyResponse(*lobby))
 }
 
 // KickMember godoc
-// @Summary      Kick a member from my lobby (Host only)
-// @Description  Removes a member from the user's current lobby. Only the host can perform this action.
+// @Summary      Kick a member from my lobby (Host or co-host)
+// @Description  Removes a member from the user's current lobby. The host can kick anyone, co-hosts can kick regular members.
+// @Description  The kick can optionally ban the member from rejoining for a duration or for the lifetime of the lobby. The member receives a `lobby_kick` notification.
 // @Tags         lobbies
+// @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        userID  path int true "User ID of member to kick"
+// @Param        input   body KickInput false "Ban options and reason"
 // @Success      200 {object} map[string]string "{"message": "Member kicked successfully"}"
-// @Failure      403 {object} ErrorResponse "Only the host can kick members"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Only the host or a co-host can kick members"
 // @Failure      404 {object} ErrorResponse "User is not in a lobby or member not found"
 // @Router       /lobbies/me/members/{userID} [delete]
 func KickMember(c *gin.Context) {
 	hostID, _ := c.Get("userID")
 	memberToKickID, _ := strconv.Atoi(c.Param("userID"))
 
+	// The body is optional: a plain kick needs no options
+	var input KickInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var user models.User
 	if err := database.DB.Preload("CurrentLobby").First(&user, hostID).Error; err != nil || user.CurrentLobbyID == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
 		return
 	}
 	
+	err := kickLobbyMember(user, user.CurrentLobby, uint(memberToKickID), input)
+	switch {
+	case errors.Is(err, errNotLobbyManager):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host or a co-host can kick members"})
+		return
+	case errors.Is(err, errKickHost):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The host cannot be kicked"})
+		return
+	case errors.Is(err, errKickSelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot kick yourself"})
+		return
+	case errors.Is(err, errMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found in this lobby"})
+		return
+	case errors.Is(err, errKickCoHost):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can kick a co-host"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to kick member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member kicked successfully"})
+}
+
+// TransferHost godoc
+// @Summary      Transfer host to another member (Host only)
+// @Description  Hands the host role over to another member of the user's current lobby.
+// @Tags         lobbies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body      TransferHostInput true  "New host"
+// @Success      200   {object}  LobbyResponse
+// @Failure      400   {object}  ErrorResponse
+// @Failure      403   {object}  ErrorResponse "Only the host can transfer the host role"
+// @Failure      404   {object}  ErrorResponse "User is not in a lobby or member not found"
+// @Router       /lobbies/me/host [post]
+func TransferHost(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var user models.User
+	if err := database.DB.Preload("CurrentLobby").First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+		return
+	}
+
 	lobby := user.CurrentLobby
 
-	if lobby.HostID != hostID.(uint) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can kick members"})
+	if lobby.HostID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can transfer the host role"})
 		return
 	}
-	
-	if lobby.HostID == uint(memberToKickID) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Host cannot kick themselves"})
+
+	var input TransferHostInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.UserID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You are already the host"})
 		return
 	}
 
-	var memberToKick models.User
-	if err := database.DB.Where("id = ? AND current_lobby_id = ?", memberToKickID, lobby.ID).First(&memberToKick).Error; err != nil {
+	var newHost models.User
+	if err := database.DB.Where("id = ? AND current_lobby_id = ?", input.UserID, lobby.ID).First(&newHost).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found in this lobby"})
 		return
 	}
 
-	database.DB.Model(&memberToKick).Update("current_lobby_id", nil)
-	
-	// Post system message
-	systemMessage := models.Message{
-		LobbyID: lobby.ID,
-		UserID:  nil, // System message
-		Type:    models.MessageTypeSystem,
-		Content: fmt.Sprintf("User %s was kicked from the lobby.", memberToKick.Nickname),
+	tx := database.DB.Begin()
+
+	if err := tx.Model(lobby).Update("host_id", newHost.ID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer host"})
+		return
+	}
+
+	if err := tx.Model(&newHost).Update("lobby_role", models.LobbyRoleMember).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer host"})
+		return
+	}
+
+	postSystemMessage(tx, lobby.ID, fmt.Sprintf("User %s handed the host role to %s.", user.Nickname, newHost.Nickname))
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit failed"})
+		return
 	}
-	database.DB.Create(&systemMessage)
 
-	// Broadcast user kicked event
 	hub.GlobalHub.Broadcast(lobby.ID, hub.Event{
-		Type:    "user_kicked",
-		Payload: buildPublicUserResponse(memberToKick, 0),
+		Type: EventHostChanged,
+		Payload: HostChangedPayload{
+			Host:           buildPublicUserResponse(newHost, 0),
+			PreviousHostID: user.ID,
+			Reason:         HostChangeReasonTransferred,
+		},
 	})
 
-	c.JSON(http.StatusOK, gin.H{"message": "Member kicked successfully"})
+	database.DB.Preload("Game").Preload("Host").Preload("Members").First(lobby, lobby.ID)
+
+	c.JSON(http.StatusOK, newLobbyResponse(*lobby))
+}
+
+// PromoteCoHost godoc
+// @Summary      Make a member co-host (Host only)
+// @Description  Grants the co-host role to a member of the user's current lobby. Co-hosts can edit the lobby and kick regular members.
+// @Tags         lobbies
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userID  path int true "User ID of the member"
+// @Success      200 {object} LobbyResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Only the host can manage co-hosts"
+// @Failure      404 {object} ErrorResponse "User is not in a lobby or member not found"
+// @Router       /lobbies/me/co-hosts/{userID} [post]
+func PromoteCoHost(c *gin.Context) {
+	setCoHostRole(c, models.LobbyRoleCoHost)
+}
+
+// DemoteCoHost godoc
+// @Summary      Revoke co-host from a member (Host only)
+// @Description  Turns a co-host of the user's current lobby back into a regular member.
+// @Tags         lobbies
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userID  path int true "User ID of the member"
+// @Success      200 {object} LobbyResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Only the host can manage co-hosts"
+// @Failure      404 {object} ErrorResponse "User is not in a lobby or member not found"
+// @Router       /lobbies/me/co-hosts/{userID} [delete]
+func DemoteCoHost(c *gin.Context) {
+	setCoHostRole(c, models.LobbyRoleMember)
+}
+
+// setCoHostRole implements PromoteCoHost and DemoteCoHost.
+func setCoHostRole(c *gin.Context, role models.LobbyRole) {
+	userID, _ := c.Get("userID")
+	memberID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Preload("CurrentLobby").First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+		return
+	}
+
+	lobby := user.CurrentLobby
+
+	if lobby.HostID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can manage co-hosts"})
+		return
+	}
+
+	if uint(memberID) == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The host cannot change their own role"})
+		return
+	}
+
+	var member models.User
+	if err := database.DB.Where("id = ? AND current_lobby_id = ?", memberID, lobby.ID).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found in this lobby"})
+		return
+	}
+
+	if member.LobbyRole != role {
+		if err := database.DB.Model(&member).Update("lobby_role", role).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member role"})
+			return
+		}
+
+		if role == models.LobbyRoleCoHost {
+			postSystemMessage(database.DB, lobby.ID, fmt.Sprintf("User %s is now a co-host.", member.Nickname))
+		} else {
+			postSystemMessage(database.DB, lobby.ID, fmt.Sprintf("User %s is no longer a co-host.", member.Nickname))
+		}
+
+		hub.GlobalHub.Broadcast(lobby.ID, hub.Event{
+			Type: EventMemberRoleChanged,
+			Payload: MemberRoleChangedPayload{
+				UserID: member.ID,
+				Role:   role,
+			},
+		})
+	}
+
+	database.DB.Preload("Game").Preload("Host").Preload("Members").First(lobby, lobby.ID)
+
+	c.JSON(http.StatusOK, newLobbyResponse(*lobby))
 }
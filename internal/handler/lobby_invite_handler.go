@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lobbyInviteTTL is how long an invite can be accepted.
+const lobbyInviteTTL = 30 * time.Minute
+
+var (
+	errInviteeNotFound   = errors.New("invitee not found")
+	errInviteeInLobby    = errors.New("invitee is already in this lobby")
+	errInviteeBanned     = errors.New("invitee is banned from this lobby")
+	errInviteExists      = errors.New("invitee already has a pending invite to this lobby")
+	errInviteeNotInGroup = errors.New("invitee is not a member of the lobby's group")
+)
+
+// region --- DTOs ---
+
+// LobbyInviteInput defines the user to invite into the current lobby.
+type LobbyInviteInput struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// LobbyInviteResponse describes a lobby invite.
+type LobbyInviteResponse struct {
+	ID        uint                `json:"id"`
+	LobbyID   uint                `json:"lobby_id"`
+	Inviter   PublicUserResponse  `json:"inviter"`
+	InviteeID uint                `json:"invitee_id"`
+	Status    models.InviteStatus `json:"status"`
+	ExpiresAt time.Time           `json:"expires_at"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+func newLobbyInviteResponse(invite models.LobbyInvite) LobbyInviteResponse {
+	return LobbyInviteResponse{
+		ID:        invite.ID,
+		LobbyID:   invite.LobbyID,
+		Inviter:   buildPublicUserResponse(invite.Inviter, invite.InviteeID),
+		InviteeID: invite.InviteeID,
+		Status:    invite.Status,
+		ExpiresAt: invite.ExpiresAt,
+		CreatedAt: invite.CreatedAt,
+	}
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// createLobbyInvite invites a user into a lobby and notifies them on their personal stream.
+func createLobbyInvite(lobbyID uint, inviter models.User, inviteeID uint) (*models.LobbyInvite, error) {
+	var invitee models.User
+	if err := database.DB.First(&invitee, inviteeID).Error; err != nil {
+		return nil, errInviteeNotFound
+	}
+	if invitee.CurrentLobbyID != nil && *invitee.CurrentLobbyID == lobbyID {
+		return nil, errInviteeInLobby
+	}
+	if _, banned := findActiveLobbyBan(database.DB, lobbyID, invitee.ID); banned {
+		return nil, errInviteeBanned
+	}
+	var lobby models.Lobby
+	if err := database.DB.First(&lobby, lobbyID).Error; err == nil && lobby.GroupID != nil {
+		if _, member := findGroupMember(database.DB, *lobby.GroupID, invitee.ID); !member {
+			return nil, errInviteeNotInGroup
+		}
+	}
+
+	var pending int64
+	database.DB.Model(&models.LobbyInvite{}).
+		Where("lobby_id = ? AND invitee_id = ? AND status = ? AND expires_at > ?", lobbyID, invitee.ID, models.InviteStatusPending, time.Now()).
+		Count(&pending)
+	if pending > 0 {
+		return nil, errInviteExists
+	}
+
+	invite := models.LobbyInvite{
+		LobbyID:   lobbyID,
+		InviterID: inviter.ID,
+		InviteeID: invitee.ID,
+		Status:    models.InviteStatusPending,
+		ExpiresAt: time.Now().Add(lobbyInviteTTL),
+		Inviter:   inviter,
+	}
+	if err := database.DB.Omit("Lobby", "Inviter", "Invitee").Create(&invite).Error; err != nil {
+		return nil, err
+	}
+
+	notifyUser(invitee.ID, models.NotificationLobbyInvite, newLobbyInviteResponse(invite))
+
+	return &invite, nil
+}
+
+// findPendingInvite loads a pending invite addressed to the user.
+func findPendingInvite(c *gin.Context) (*models.LobbyInvite, bool) {
+	userID, _ := c.Get("userID")
+	inviteID, err := strconv.ParseUint(c.Param("inviteID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return nil, false
+	}
+
+	var invite models.LobbyInvite
+	if err := database.DB.Where("id = ? AND invitee_id = ? AND status = ?", inviteID, userID, models.InviteStatusPending).
+		First(&invite).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+		return nil, false
+	}
+	if invite.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusGone, gin.H{"error": "Invite expired"})
+		return nil, false
+	}
+	return &invite, true
+}
+
+// endregion
+
+// InviteToLobby godoc
+// @Summary      Invite a user to my lobby
+// @Description  Invites a user to the current user's lobby. The invitee receives a `lobby_invite` notification.
+// @Tags         lobbies-invites
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body LobbyInviteInput true "User to invite"
+// @Success      201 {object} LobbyInviteResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "User is banned from this lobby or not in its group"
+// @Failure      404 {object} ErrorResponse "User is not in a lobby or invitee not found"
+// @Failure      409 {object} ErrorResponse "Invitee is already in the lobby or already invited"
+// @Router       /lobbies/me/invites [post]
+func InviteToLobby(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+		return
+	}
+
+	var input LobbyInviteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.UserID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot invite yourself"})
+		return
+	}
+
+	invite, err := createLobbyInvite(*user.CurrentLobbyID, user, input.UserID)
+	switch {
+	case errors.Is(err, errInviteeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	case errors.Is(err, errInviteeBanned):
+		c.JSON(http.StatusForbidden, gin.H{"error": "User is banned from this lobby"})
+		return
+	case errors.Is(err, errInviteeNotInGroup):
+		c.JSON(http.StatusForbidden, gin.H{"error": "User is not a member of this lobby's group"})
+		return
+	case errors.Is(err, errInviteeInLobby):
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already in this lobby"})
+		return
+	case errors.Is(err, errInviteExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "User already has a pending invite to this lobby"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newLobbyInviteResponse(*invite))
+}
+
+// GetMyInvites godoc
+// @Summary      Get my pending lobby invites
+// @Description  Lists the lobby invites addressed to the current user that can still be accepted.
+// @Tags         lobbies-invites
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} LobbyInviteResponse
+// @Router       /users/me/invites [get]
+func GetMyInvites(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var invites []models.LobbyInvite
+	if err := database.DB.Preload("Inviter").
+		Joins("JOIN lobbies ON lobbies.id = lobby_invites.lobby_id AND lobbies.deleted_at IS NULL").
+		Where("lobby_invites.invitee_id = ? AND lobby_invites.status = ? AND lobby_invites.expires_at > ?", userID, models.InviteStatusPending, time.Now()).
+		Order("lobby_invites.created_at DESC").
+		Find(&invites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invites"})
+		return
+	}
+
+	response := []LobbyInviteResponse{}
+	for _, invite := range invites {
+		response = append(response, newLobbyInviteResponse(invite))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AcceptInvite godoc
+// @Summary      Accept a lobby invite
+// @Description  Joins the lobby the user was invited to. The usual join rules apply: the lobby must have room and the user must not be banned.
+// @Tags         lobbies-invites
+// @Produce      json
+// @Security     BearerAuth
+// @Param        inviteID path int true "Invite ID"
+// @Success      200 {object} LobbyResponse
+// @Failure      403 {object} ErrorResponse "User is banned from this lobby or not in its group"
+// @Failure      404 {object} ErrorResponse "Invite or lobby not found"
+// @Failure      409 {object} ErrorResponse "Lobby is full or user is in another lobby"
+// @Failure      410 {object} ErrorResponse "Invite expired"
+// @Router       /users/me/invites/{inviteID}/accept [post]
+func AcceptInvite(c *gin.Context) {
+	invite, ok := findPendingInvite(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	database.DB.First(&user, invite.InviteeID)
+	if user.CurrentLobbyID != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+		return
+	}
+
+	var lobby models.Lobby
+	if err := database.DB.Preload("Members").First(&lobby, invite.LobbyID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lobby not found"})
+		return
+	}
+	if reason := lobbyAccessError(database.DB, lobby, user.ID); reason != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": reason})
+		return
+	}
+	if len(lobby.Members)+int(countReservedSlots(database.DB, lobby.ID, user.ID)) >= lobby.MaxPlayers {
+		c.JSON(http.StatusConflict, gin.H{"error": "Lobby is full"})
+		return
+	}
+
+	if err := addUserToLobby(user, lobby.ID); err != nil {
+		if errors.Is(err, errInAnotherLobby) {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join lobby"})
+		return
+	}
+	database.DB.Model(invite).Update("status", models.InviteStatusAccepted)
+
+	database.DB.Preload("Game").Preload("Host").Preload("Members").First(&lobby, lobby.ID)
+	c.JSON(http.StatusOK, newLobbyResponse(lobby))
+}
+
+// DeclineInvite godoc
+// @Summary      Decline a lobby invite
+// @Description  Declines a pending lobby invite.
+// @Tags         lobbies-invites
+// @Produce      json
+// @Security     BearerAuth
+// @Param        inviteID path int true "Invite ID"
+// @Success      200 {object} map[string]string "{"message": "Invite declined"}"
+// @Failure      404 {object} ErrorResponse "Invite not found"
+// @Failure      410 {object} ErrorResponse "Invite expired"
+// @Router       /users/me/invites/{inviteID}/decline [post]
+func DeclineInvite(c *gin.Context) {
+	invite, ok := findPendingInvite(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Model(invite).Update("status", models.InviteStatusDeclined).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline invite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite declined"})
+}
@@ -0,0 +1,439 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errNotLobbyManager = errors.New("only the host or a co-host can do this")
+	errKickHost        = errors.New("the host cannot be kicked")
+	errKickSelf        = errors.New("users cannot kick themselves")
+	errKickCoHost      = errors.New("only the host can kick a co-host")
+	errMemberNotFound  = errors.New("member not found in this lobby")
+	errInAnotherLobby  = errors.New("user is already in a lobby")
+)
+
+// Host change reasons carried by the host_changed event.
+const (
+	HostChangeReasonTransferred = "transferred"
+	HostChangeReasonHostLeft    = "host_left"
+)
+
+// HostChangedPayload is the payload of the host_changed event.
+type HostChangedPayload struct {
+	Host           PublicUserResponse `json:"host"`
+	PreviousHostID uint               `json:"previous_host_id"`
+	Reason         string             `json:"reason"`
+}
+
+// UserReadyPayload is the payload of the user_ready event.
+type UserReadyPayload struct {
+	UserID uint `json:"user_id"`
+	Ready  bool `json:"ready"`
+}
+
+// joinLobbyColumns returns the user columns to update when a user enters a lobby.
+func joinLobbyColumns(lobbyID uint) map[string]interface{} {
+	return map[string]interface{}{
+		"current_lobby_id": lobbyID,
+		"lobby_role":       models.LobbyRoleMember,
+		"lobby_joined_at":  time.Now(),
+		"lobby_ready":      false,
+	}
+}
+
+// leaveLobbyColumns returns the user columns to update when a user leaves or is removed from a lobby.
+func leaveLobbyColumns() map[string]interface{} {
+	return map[string]interface{}{
+		"current_lobby_id": nil,
+		"lobby_role":       "",
+		"lobby_joined_at":  nil,
+		"lobby_ready":      false,
+	}
+}
+
+// createLobby stores a new lobby with host as its first member, posts the system message and announces it.
+// It fails with errInAnotherLobby when the host is in a lobby already. On success the lobby is reloaded with all associations.
+func createLobby(host models.User, lobby *models.Lobby) error {
+	lobby.HostID = host.ID
+
+	// Use a transaction to ensure both lobby creation and user update succeed
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(lobby).Error; err != nil {
+			return err
+		}
+		if err := joinLobbyIfFree(tx, host.ID, lobby.ID); err != nil {
+			return err
+		}
+		return startLobbySession(tx, *lobby)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The host no longer needs to be matched into another lobby
+	leaveMatchmaking(host.ID)
+
+	// Reload lobby with all associations
+	database.DB.Preload("Game").Preload("Host").Preload("Members").First(lobby, lobby.ID)
+
+	postSystemMessage(database.DB, lobby.ID, fmt.Sprintf("User %s created the lobby.", host.Nickname)) // Not in transaction, best-effort
+
+	hub.GlobalHub.Broadcast(lobby.ID, hub.Event{
+		Type:    EventLobbyCreated,
+		Payload: newLobbyResponse(*lobby),
+	})
+	refreshPresence(host.ID)
+
+	return nil
+}
+
+// joinLobbyIfFree makes the user a member of the lobby unless they are in a lobby already.
+// The check is part of the update, so a user who entered another lobby since they were loaded is never moved out of it.
+func joinLobbyIfFree(db *gorm.DB, userID, lobbyID uint) error {
+	result := db.Model(&models.User{}).
+		Where("id = ? AND current_lobby_id IS NULL", userID).
+		Updates(joinLobbyColumns(lobbyID))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInAnotherLobby
+	}
+	return nil
+}
+
+// addUserToLobby places the user into the lobby, posts the system message and notifies the lobby.
+// It fails with errInAnotherLobby when the user is in a lobby already.
+// The caller is responsible for checking that the lobby has room and that the user is allowed in.
+func addUserToLobby(user models.User, lobbyID uint) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := joinLobbyIfFree(tx, user.ID, lobbyID); err != nil {
+			return err
+		}
+		return recordSessionJoin(tx, lobbyID, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// A waitlist entry for this lobby is fulfilled once the user is in
+	database.DB.Where("lobby_id = ? AND user_id = ?", lobbyID, user.ID).Delete(&models.LobbyWaitlistEntry{})
+	leaveMatchmaking(user.ID)
+
+	postSystemMessage(database.DB, lobbyID, fmt.Sprintf("User %s joined the lobby.", user.Nickname))
+
+	hub.GlobalHub.Broadcast(lobbyID, hub.Event{
+		Type:    EventUserJoined,
+		Payload: buildPublicUserResponse(user, 0), // User who joined
+	})
+	refreshLobbyPresence(lobbyID)
+
+	return nil
+}
+
+// lobbyAccessError returns why the user may not enter the lobby, or an empty string when they may.
+// Banned users, users below the lobby's minimum reputation and, for group-only lobbies, users outside the group are kept out.
+func lobbyAccessError(db *gorm.DB, lobby models.Lobby, userID uint) string {
+	if ban, banned := findActiveLobbyBan(db, lobby.ID, userID); banned {
+		return lobbyBanError(ban)
+	}
+	if lobby.MinReputation > 0 {
+		var score float64
+		db.Model(&models.User{}).Select("reputation_score").Where("id = ?", userID).Scan(&score)
+		if score < lobby.MinReputation {
+			return fmt.Sprintf("This lobby requires a reputation of at least %.0f", lobby.MinReputation)
+		}
+	}
+	if lobby.GroupID != nil {
+		if _, ok := findGroupMember(db, *lobby.GroupID, userID); !ok {
+			return "This lobby is only open to members of its group"
+		}
+	}
+	return ""
+}
+
+// leaveLobby takes the user out of their current lobby, which must be preloaded.
+// It hands the host role to the successor, or deletes the lobby when the user was the last member.
+func leaveLobby(user models.User) error {
+	lobbyID := *user.CurrentLobbyID
+	lobby := user.CurrentLobby
+
+	tx := database.DB.Begin()
+
+	if err := tx.Model(&user).Updates(leaveLobbyColumns()).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := recordSessionLeave(tx, lobbyID, user.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Load the remaining members after the user left
+	var remainingMembers []models.User
+	if err := tx.Where("current_lobby_id = ?", lobbyID).Find(&remainingMembers).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	postSystemMessage(tx, lobbyID, fmt.Sprintf("User %s left the lobby.", user.Nickname))
+
+	// If no one is left, delete the lobby
+	if len(remainingMembers) == 0 {
+		if err := tx.Delete(lobby).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := endLobbySession(tx, lobbyID); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		// Bans only last for the lifetime of the lobby
+		if err := tx.Where("lobby_id = ?", lobbyID).Delete(&models.LobbyBan{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			return err
+		}
+
+		hub.GlobalHub.Broadcast(lobbyID, hub.Event{
+			Type:    EventLobbyDeleted,
+			Payload: LobbyRefPayload{LobbyID: lobbyID},
+		})
+		hub.GlobalHub.Disconnect(lobbyID, user.ID)
+		forgetWSAck(user.ID, lobbyID)
+		hub.GlobalHub.Forget(lobbyID)
+		closeWaitlist(lobbyID)
+		notifySessionEnded(lobbyID)
+		refreshPresence(user.ID)
+		return nil
+	}
+
+	// If the user was the host, promote the successor
+	var nextHost *models.User
+	if lobby.HostID == user.ID {
+		successor, err := findNextHost(tx, lobbyID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Model(lobby).Update("host_id", successor.ID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		// The host role supersedes co-host
+		tx.Model(&successor).Update("lobby_role", models.LobbyRoleMember)
+
+		postSystemMessage(tx, lobbyID, fmt.Sprintf("User %s is now the host.", successor.Nickname))
+		nextHost = &successor
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if nextHost != nil {
+		hub.GlobalHub.Broadcast(lobbyID, hub.Event{
+			Type: EventHostChanged,
+			Payload: HostChangedPayload{
+				Host:           buildPublicUserResponse(*nextHost, 0),
+				PreviousHostID: user.ID,
+				Reason:         HostChangeReasonHostLeft,
+			},
+		})
+	}
+
+	hub.GlobalHub.Broadcast(lobbyID, hub.Event{
+		Type:    EventUserLeft,
+		Payload: buildPublicUserResponse(user, 0),
+	})
+	hub.GlobalHub.Disconnect(lobbyID, user.ID)
+	forgetWSAck(user.ID, lobbyID)
+	offerWaitlistSlots(lobbyID)
+	refreshPresence(user.ID)
+	refreshLobbyPresence(lobbyID)
+
+	return nil
+}
+
+// kickLobbyMember removes a member from the actor's lobby, optionally banning them, and notifies the lobby and the member.
+func kickLobbyMember(actor models.User, lobby *models.Lobby, memberID uint, input KickInput) error {
+	if !canManageLobby(lobby, actor) {
+		return errNotLobbyManager
+	}
+	if lobby.HostID == memberID {
+		return errKickHost
+	}
+	if actor.ID == memberID {
+		return errKickSelf
+	}
+
+	var member models.User
+	if err := database.DB.Where("id = ? AND current_lobby_id = ?", memberID, lobby.ID).First(&member).Error; err != nil {
+		return errMemberNotFound
+	}
+	if member.LobbyRole == models.LobbyRoleCoHost && lobby.HostID != actor.ID {
+		return errKickCoHost
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&member).Updates(leaveLobbyColumns()).Error; err != nil {
+			return err
+		}
+		if err := recordSessionLeave(tx, lobby.ID, member.ID); err != nil {
+			return err
+		}
+
+		if input.Ban {
+			ban := models.LobbyBan{
+				LobbyID:    lobby.ID,
+				UserID:     member.ID,
+				BannedByID: actor.ID,
+				Reason:     input.Reason,
+			}
+			if input.DurationMinutes > 0 {
+				expiresAt := time.Now().Add(time.Duration(input.DurationMinutes) * time.Minute)
+				ban.ExpiresAt = &expiresAt
+			}
+			// Save upserts, replacing an earlier expired ban
+			if err := tx.Save(&ban).Error; err != nil {
+				return err
+			}
+			// A banned user cannot take a slot offered from the waitlist either
+			if err := tx.Where("lobby_id = ? AND user_id = ?", lobby.ID, member.ID).Delete(&models.LobbyWaitlistEntry{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Published after the commit, so open chats show the kick right away
+	content := fmt.Sprintf("User %s was kicked from the lobby.", member.Nickname)
+	if input.Ban {
+		content = fmt.Sprintf("User %s was kicked and banned from the lobby.", member.Nickname)
+	}
+	if input.Reason != "" {
+		content = fmt.Sprintf("%s Reason: %s", content, input.Reason)
+	}
+	publishSystemMessage(lobby.ID, content)
+
+	hub.GlobalHub.Broadcast(lobby.ID, hub.Event{
+		Type:    EventUserKicked,
+		Payload: buildPublicUserResponse(member, 0),
+	})
+	// The member's open streams would otherwise keep receiving the lobby's events
+	hub.GlobalHub.Disconnect(lobby.ID, member.ID)
+	forgetWSAck(member.ID, lobby.ID)
+	notifyUser(member.ID, models.NotificationLobbyKick, LobbyKickNotification{
+		LobbyID:  lobby.ID,
+		KickedBy: buildPublicUserResponse(actor, member.ID),
+		Banned:   input.Ban,
+		Reason:   input.Reason,
+	})
+	offerWaitlistSlots(lobby.ID)
+	refreshPresence(member.ID)
+	refreshLobbyPresence(lobby.ID)
+
+	return nil
+}
+
+// canManageLobby reports whether the user may edit the lobby and kick regular members.
+func canManageLobby(lobby *models.Lobby, user models.User) bool {
+	return lobby.HostID == user.ID || user.LobbyRole == models.LobbyRoleCoHost
+}
+
+// findNextHost picks the successor of a leaving host.
+// Members who have been in the lobby the longest come first; co-hosts win ties,
+// and the user ID keeps the order deterministic for legacy rows without a join time.
+func findNextHost(db *gorm.DB, lobbyID uint) (models.User, error) {
+	var nextHost models.User
+	err := db.Where("current_lobby_id = ?", lobbyID).
+		Order("lobby_joined_at ASC NULLS LAST").
+		Order(fmt.Sprintf("CASE WHEN lobby_role = '%s' THEN 0 ELSE 1 END", models.LobbyRoleCoHost)).
+		Order("id ASC").
+		First(&nextHost).Error
+	return nextHost, err
+}
+
+// lobbyCoHostIDs returns the IDs of the co-hosts among the lobby members.
+func lobbyCoHostIDs(lobby models.Lobby) []uint {
+	coHostIDs := []uint{}
+	for _, member := range lobby.Members {
+		if member.LobbyRole == models.LobbyRoleCoHost {
+			coHostIDs = append(coHostIDs, member.ID)
+		}
+	}
+	return coHostIDs
+}
+
+// lobbyReadyMemberIDs returns the IDs of the members who marked themselves ready.
+func lobbyReadyMemberIDs(lobby models.Lobby) []uint {
+	readyIDs := []uint{}
+	for _, member := range lobby.Members {
+		if member.LobbyReady {
+			readyIDs = append(readyIDs, member.ID)
+		}
+	}
+	return readyIDs
+}
+
+// broadcastTyping tells the user's lobby that the user is typing.
+// Repeats within typingThrottleInterval are dropped.
+func broadcastTyping(user models.User) {
+	if !allowTyping(user.ID) {
+		return
+	}
+	hub.GlobalHub.Broadcast(*user.CurrentLobbyID, hub.Event{
+		Type: EventUserTyping,
+		Payload: UserTypingPayload{
+			UserID:   user.ID,
+			Nickname: user.Nickname,
+		},
+	})
+}
+
+// setLobbyReady stores whether the user is ready and tells their lobby.
+func setLobbyReady(user models.User, ready bool) error {
+	if err := database.DB.Model(&user).Update("lobby_ready", ready).Error; err != nil {
+		return err
+	}
+	hub.GlobalHub.Broadcast(*user.CurrentLobbyID, hub.Event{
+		Type:    EventUserReady,
+		Payload: UserReadyPayload{UserID: user.ID, Ready: ready},
+	})
+	refreshLobbyPresence(*user.CurrentLobbyID)
+	return nil
+}
+
+// normalizeLocaleCode normalizes region and language codes so they can be compared.
+func normalizeLocaleCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// postSystemMessage stores a system message in the lobby chat. It is best-effort, like the other system messages.
+func postSystemMessage(db *gorm.DB, lobbyID uint, content string) {
+	db.Create(&models.Message{
+		LobbyID: &lobbyID,
+		UserID:  nil,
+		Type:    models.MessageTypeSystem,
+		Content: content,
+	})
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"fmt"
+	"playmatch/backend/internal/models"
+	"testing"
+	"time"
+)
+
+func TestFindNextHost(t *testing.T) {
+	joined := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		joinedAt := joined.Add(time.Duration(minutes) * time.Minute)
+		return &joinedAt
+	}
+
+	type member struct {
+		nickname string
+		role     models.LobbyRole
+		joinedAt *time.Time
+	}
+	tests := []struct {
+		name    string
+		members []member
+		want    string
+	}{
+		{
+			name: "longest member wins",
+			members: []member{
+				{"late", models.LobbyRoleMember, at(10)},
+				{"early", models.LobbyRoleMember, at(1)},
+			},
+			want: "early",
+		},
+		{
+			name: "earlier member beats later co-host",
+			members: []member{
+				{"cohost", models.LobbyRoleCoHost, at(5)},
+				{"member", models.LobbyRoleMember, at(2)},
+			},
+			want: "member",
+		},
+		{
+			name: "co-host wins a tie",
+			members: []member{
+				{"member", models.LobbyRoleMember, at(3)},
+				{"cohost", models.LobbyRoleCoHost, at(3)},
+			},
+			want: "cohost",
+		},
+		{
+			name: "legacy rows without join time come last",
+			members: []member{
+				{"legacy", models.LobbyRoleCoHost, nil},
+				{"member", models.LobbyRoleMember, at(30)},
+			},
+			want: "member",
+		},
+		{
+			name: "legacy rows fall back to user ID",
+			members: []member{
+				{"first", models.LobbyRoleMember, nil},
+				{"second", models.LobbyRoleMember, nil},
+			},
+			want: "first",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+
+			host := models.User{Nickname: "host", Email: "host@example.com", PasswordHash: "-"}
+			if err := db.Create(&host).Error; err != nil {
+				t.Fatalf("create host: %v", err)
+			}
+			game := models.Game{Name: "Game", SteamURL: "https://store.steampowered.com/app/test"}
+			if err := db.Create(&game).Error; err != nil {
+				t.Fatalf("create game: %v", err)
+			}
+			lobby := models.Lobby{GameID: game.ID, HostID: host.ID}
+			if err := db.Create(&lobby).Error; err != nil {
+				t.Fatalf("create lobby: %v", err)
+			}
+
+			for _, m := range tt.members {
+				user := models.User{
+					Nickname:       m.nickname,
+					Email:          fmt.Sprintf("%s@example.com", m.nickname),
+					PasswordHash:   "-",
+					CurrentLobbyID: &lobby.ID,
+					LobbyRole:      m.role,
+					LobbyJoinedAt:  m.joinedAt,
+				}
+				if err := db.Create(&user).Error; err != nil {
+					t.Fatalf("create member %s: %v", m.nickname, err)
+				}
+			}
+
+			next, err := findNextHost(db, lobby.ID)
+			if err != nil {
+				t.Fatalf("findNextHost: %v", err)
+			}
+			if next.Nickname != tt.want {
+				t.Errorf("findNextHost = %q, want %q", next.Nickname, tt.want)
+			}
+		})
+	}
+}
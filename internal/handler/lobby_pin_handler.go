@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxPinnedMessages caps the pinned messages of a lobby chat.
+	maxPinnedMessages = 5
+
+	// maxAnnouncementLength is the longest announcement in characters, as stored in Lobby.Announcement.
+	maxAnnouncementLength = 500
+)
+
+var errAnnouncementTooLong = errors.New("announcement is too long")
+
+// region --- DTOs ---
+
+// AnnouncementInput defines the announcement of a lobby.
+type AnnouncementInput struct {
+	Announcement string `json:"announcement" binding:"max=500" example:"Voice: discord.gg/example, server 203.0.113.7:27015"` // Leave empty to remove the announcement
+}
+
+// MessageUnpinnedPayload is broadcast when a message is unpinned.
+type MessageUnpinnedPayload struct {
+	MessageID uint `json:"message_id"`
+	LobbyID   uint `json:"lobby_id"`
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// pinnedMessages returns the pinned messages of a lobby chat, oldest pin first, or nil for other chats.
+func (scope chatScope) pinnedMessages() []MessageResponse {
+	if scope.LobbyID == nil {
+		return nil
+	}
+
+	var messages []models.Message
+	preloadMessageDetails(scope.scopeMessages(database.DB)).
+		Where("pinned_at IS NOT NULL").
+		Order("pinned_at ASC").
+		Find(&messages)
+
+	response := []MessageResponse{}
+	for _, message := range messages {
+		response = append(response, newMessageResponse(message))
+	}
+	return response
+}
+
+// setLobbyAnnouncement changes the announcement of the lobby, masking blocked words, and tells the lobby.
+// Announcements longer than maxAnnouncementLength fail with errAnnouncementTooLong.
+func setLobbyAnnouncement(lobby *models.Lobby, announcement string) error {
+	announcement = strings.TrimSpace(announcement)
+	if utf8.RuneCountInString(announcement) > maxAnnouncementLength {
+		return errAnnouncementTooLong
+	}
+	announcement, err := filterChatContent(announcement, lobby.Language)
+	if err != nil {
+		return err
+	}
+	if err := database.DB.Model(lobby).Update("announcement", announcement).Error; err != nil {
+		return err
+	}
+
+	database.DB.Preload("Game").Preload("Host").Preload("Members").First(lobby, lobby.ID)
+	hub.GlobalHub.Broadcast(lobby.ID, hub.Event{
+		Type:    EventLobbyUpdated,
+		Payload: newLobbyResponse(*lobby),
+	})
+	return nil
+}
+
+// endregion
+
+// region --- Handlers ---
+
+// GetPinnedMessages godoc
+// @Summary      Get the pinned messages of my lobby
+// @Description  Lists the pinned messages of the user's current lobby chat, oldest pin first. They are also returned with the chat history in `pinned`.
+// @Tags         lobbies-chat
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} MessageResponse
+// @Failure      404 {object} ErrorResponse "User is not in a lobby"
+// @Router       /lobbies/me/pins [get]
+func GetPinnedMessages(c *gin.Context) {
+	if scope, ok := lobbyChatScope(c); ok {
+		c.JSON(http.StatusOK, scope.pinnedMessages())
+	}
+}
+
+// PinLobbyMessage godoc
+// @Summary      Pin a message in my lobby chat (Host or co-host)
+// @Description  Pins a message of the user's current lobby chat; a lobby can have up to 5 pinned messages. Members receive a `message_pinned` event.
+// @Tags         lobbies-chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        messageID path int true "Message ID"
+// @Success      200 {object} MessageResponse
+// @Failure      403 {object} ErrorResponse "Only the host or a co-host can pin messages"
+// @Failure      404 {object} ErrorResponse "Message not found"
+// @Failure      409 {object} ErrorResponse "Too many pinned messages"
+// @Router       /lobbies/me/messages/{messageID}/pin [post]
+func PinLobbyMessage(c *gin.Context) {
+	scope, ok := lobbyChatScope(c)
+	if !ok {
+		return
+	}
+	if !scope.CanModerate {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host or a co-host can pin messages"})
+		return
+	}
+	message, ok := findChatMessage(c, scope)
+	if !ok {
+		return
+	}
+	if message.PinnedAt != nil {
+		c.JSON(http.StatusOK, newMessageResponse(*message))
+		return
+	}
+
+	var pinned int64
+	scope.scopeMessages(database.DB.Model(&models.Message{})).Where("pinned_at IS NOT NULL").Count(&pinned)
+	if pinned >= maxPinnedMessages {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A lobby can have at most %d pinned messages", maxPinnedMessages)})
+		return
+	}
+
+	now := time.Now()
+	if err := database.DB.Model(message).Updates(map[string]interface{}{
+		"pinned_at":    now,
+		"pinned_by_id": scope.UserID,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pin message"})
+		return
+	}
+	message.PinnedAt = &now
+	message.PinnedByID = &scope.UserID
+
+	scope.publish(hub.Event{
+		Type:    EventMessagePinned,
+		Payload: newMessageResponse(*message),
+	})
+
+	c.JSON(http.StatusOK, newMessageResponse(*message))
+}
+
+// UnpinLobbyMessage godoc
+// @Summary      Unpin a message in my lobby chat (Host or co-host)
+// @Description  Unpins a message of the user's current lobby chat. Members receive a `message_unpinned` event.
+// @Tags         lobbies-chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        messageID path int true "Message ID"
+// @Success      200 {object} MessageResponse
+// @Failure      403 {object} ErrorResponse "Only the host or a co-host can unpin messages"
+// @Failure      404 {object} ErrorResponse "Message not found or not pinned"
+// @Router       /lobbies/me/messages/{messageID}/pin [delete]
+func UnpinLobbyMessage(c *gin.Context) {
+	scope, ok := lobbyChatScope(c)
+	if !ok {
+		return
+	}
+	if !scope.CanModerate {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host or a co-host can unpin messages"})
+		return
+	}
+	message, ok := findChatMessage(c, scope)
+	if !ok {
+		return
+	}
+	if message.PinnedAt == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message is not pinned"})
+		return
+	}
+
+	if err := database.DB.Model(message).Updates(map[string]interface{}{
+		"pinned_at":    nil,
+		"pinned_by_id": nil,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unpin message"})
+		return
+	}
+	message.PinnedAt = nil
+	message.PinnedByID = nil
+
+	scope.publish(hub.Event{
+		Type:    EventMessageUnpinned,
+		Payload: MessageUnpinnedPayload{MessageID: message.ID, LobbyID: *scope.LobbyID},
+	})
+
+	c.JSON(http.StatusOK, newMessageResponse(*message))
+}
+
+// SetLobbyAnnouncement godoc
+// @Summary      Set the announcement of my lobby (Host or co-host)
+// @Description  Sets the announcement shown with the lobby, e.g. a voice chat link or server address, up to 500 characters; an empty announcement removes it. Blocked words are masked like in the chat. Members receive a `lobby_updated` event; `/announce [text]` does the same from the chat.
+// @Tags         lobbies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body AnnouncementInput true "Announcement"
+// @Success      200 {object} LobbyResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Only the host or a co-host can change the announcement"
+// @Failure      404 {object} ErrorResponse "User is not in a lobby"
+// @Router       /lobbies/me/announcement [put]
+func SetLobbyAnnouncement(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input AnnouncementInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Preload("CurrentLobby").First(&user, userID).Error; err != nil || user.CurrentLobby == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+		return
+	}
+	lobby := user.CurrentLobby
+	if !canManageLobby(lobby, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host or a co-host can change the announcement"})
+		return
+	}
+
+	if err := setLobbyAnnouncement(lobby, input.Announcement); err != nil {
+		if errors.Is(err, errBlockedWords) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Announcement contains blocked words"})
+			return
+		}
+		if errors.Is(err, errAnnouncementTooLong) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Announcement is limited to %d characters", maxAnnouncementLength)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update announcement"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newLobbyResponse(*lobby))
+}
+
+// endregion
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// playedWithLimit caps the "recently played with" list.
+const playedWithLimit = 20
+
+// region --- DTOs ---
+
+// SessionParticipantResponse describes one stay of a user in a lobby session.
+type SessionParticipantResponse struct {
+	User     PublicUserResponse `json:"user"`
+	JoinedAt time.Time          `json:"joined_at"`
+	LeftAt   *time.Time         `json:"left_at,omitempty"`
+}
+
+// LobbySessionResponse describes a past or ongoing lobby session.
+type LobbySessionResponse struct {
+	ID           uint                         `json:"id"`
+	LobbyID      uint                         `json:"lobby_id"`
+	HostID       uint                         `json:"host_id"`
+	Game         GameResponse                 `json:"game"`
+	StartedAt    time.Time                    `json:"started_at"`
+	EndedAt      *time.Time                   `json:"ended_at,omitempty"`
+	Participants []SessionParticipantResponse `json:"participants"`
+}
+
+// PaginatedLobbySessionResponse defines the structure for a paginated list of lobby sessions.
+type PaginatedLobbySessionResponse struct {
+	Data []LobbySessionResponse `json:"data"`
+	Meta PaginationMeta         `json:"meta"`
+}
+
+// PlayedWithResponse describes a user someone recently shared a lobby with.
+type PlayedWithResponse struct {
+	User             PublicUserResponse `json:"user"`
+	LastPlayedAt     time.Time          `json:"last_played_at"`
+	SessionsTogether int64              `json:"sessions_together"`
+}
+
+func newLobbySessionResponse(session models.LobbySession, viewerID uint) LobbySessionResponse {
+	participants := []SessionParticipantResponse{}
+	for _, participant := range session.Participants {
+		participants = append(participants, SessionParticipantResponse{
+			User:     buildPublicUserResponse(participant.User, viewerID),
+			JoinedAt: participant.JoinedAt,
+			LeftAt:   participant.LeftAt,
+		})
+	}
+
+	return LobbySessionResponse{
+		ID:           session.ID,
+		LobbyID:      session.LobbyID,
+		HostID:       session.HostID,
+		Game:         newGameResponse(session.Game, nil),
+		StartedAt:    session.StartedAt,
+		EndedAt:      session.EndedAt,
+		Participants: participants,
+	}
+}
+
+// endregion
+
+// region --- Session bookkeeping ---
+
+// startLobbySession opens the session of a newly created lobby with its host as first participant.
+func startLobbySession(db *gorm.DB, lobby models.Lobby) error {
+	now := time.Now()
+	session := models.LobbySession{
+		LobbyID:   lobby.ID,
+		GameID:    lobby.GameID,
+		HostID:    lobby.HostID,
+		StartedAt: now,
+		Participants: []models.LobbySessionParticipant{
+			{UserID: lobby.HostID, JoinedAt: now},
+		},
+	}
+	return db.Create(&session).Error
+}
+
+// findOpenLobbySession returns the open session of a lobby, starting one for lobbies created before sessions were recorded.
+func findOpenLobbySession(db *gorm.DB, lobbyID uint) (models.LobbySession, error) {
+	var session models.LobbySession
+	err := db.Where("lobby_id = ? AND ended_at IS NULL", lobbyID).Order("id DESC").First(&session).Error
+	if err == nil {
+		return session, nil
+	}
+
+	var lobby models.Lobby
+	if err := db.First(&lobby, lobbyID).Error; err != nil {
+		return session, err
+	}
+	session = models.LobbySession{
+		LobbyID:   lobby.ID,
+		GameID:    lobby.GameID,
+		HostID:    lobby.HostID,
+		StartedAt: time.Now(),
+	}
+	return session, db.Create(&session).Error
+}
+
+// recordSessionJoin adds a participant record for a user entering the lobby.
+func recordSessionJoin(db *gorm.DB, lobbyID, userID uint) error {
+	session, err := findOpenLobbySession(db, lobbyID)
+	if err != nil {
+		return err
+	}
+	return db.Create(&models.LobbySessionParticipant{
+		SessionID: session.ID,
+		UserID:    userID,
+		JoinedAt:  time.Now(),
+	}).Error
+}
+
+// recordSessionLeave closes the participant record of a user leaving or removed from the lobby.
+func recordSessionLeave(db *gorm.DB, lobbyID, userID uint) error {
+	return db.Model(&models.LobbySessionParticipant{}).
+		Where("user_id = ? AND left_at IS NULL AND session_id IN (?)", userID,
+			db.Model(&models.LobbySession{}).Select("id").Where("lobby_id = ? AND ended_at IS NULL", lobbyID)).
+		Update("left_at", time.Now()).Error
+}
+
+// recordSessionGame keeps the session's game in sync with the lobby.
+func recordSessionGame(db *gorm.DB, lobbyID, gameID uint) error {
+	return db.Model(&models.LobbySession{}).
+		Where("lobby_id = ? AND ended_at IS NULL", lobbyID).
+		Update("game_id", gameID).Error
+}
+
+// endLobbySession closes the session of a deleted lobby along with any participant still marked as present.
+func endLobbySession(db *gorm.DB, lobbyID uint) error {
+	now := time.Now()
+	sessionIDs := db.Model(&models.LobbySession{}).Select("id").Where("lobby_id = ? AND ended_at IS NULL", lobbyID)
+	if err := db.Model(&models.LobbySessionParticipant{}).
+		Where("left_at IS NULL AND session_id IN (?)", sessionIDs).
+		Update("left_at", now).Error; err != nil {
+		return err
+	}
+	return db.Model(&models.LobbySession{}).
+		Where("lobby_id = ? AND ended_at IS NULL", lobbyID).
+		Update("ended_at", now).Error
+}
+
+// endregion
+
+// GetMyHistory godoc
+// @Summary      Get my lobby history
+// @Description  Retrieves a paginated list of the lobby sessions the current user took part in, newest first.
+// @Tags         history
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page  query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(10)
+// @Success      200 {object} PaginatedLobbySessionResponse
+// @Failure      401 {object} ErrorResponse
+// @Router       /users/me/history [get]
+func GetMyHistory(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100 // Max limit
+	}
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.LobbySession{}).
+		Where("id IN (?)", database.DB.Model(&models.LobbySessionParticipant{}).Select("session_id").Where("user_id = ?", userID))
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count sessions"})
+		return
+	}
+
+	var sessions []models.LobbySession
+	if err := query.Preload("Game.Tags").
+		Preload("Participants", func(db *gorm.DB) *gorm.DB { return db.Order("joined_at ASC") }).
+		Preload("Participants.User").
+		Order("started_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+
+	var response []LobbySessionResponse
+	for _, session := range sessions {
+		response = append(response, newLobbySessionResponse(session, userID.(uint)))
+	}
+
+	c.JSON(http.StatusOK, NewPaginatedResponse(response, totalItems, page, limit))
+}
+
+// GetPlayedWith godoc
+// @Summary      Get users recently played with
+// @Description  Lists the users who were in a lobby at the same time as the given user, most recent first.
+// @Tags         history
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path int true "User ID"
+// @Success      200 {array}  PlayedWithResponse
+// @Failure      400 {object} ErrorResponse
+// @Router       /users/{id}/played-with [get]
+func GetPlayedWith(c *gin.Context) {
+	viewerIDRaw, viewerOk := c.Get("userID")
+	var viewerID uint
+	if viewerOk {
+		viewerID = viewerIDRaw.(uint)
+	}
+
+	targetUserID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	type playedWithRow struct {
+		UserID           uint
+		LastPlayedAt     time.Time
+		SessionsTogether int64
+	}
+
+	// Two stays overlap when each one started before the other ended
+	var rows []playedWithRow
+	now := time.Now()
+	if err := database.DB.Table("lobby_session_participants AS mine").
+		Select("other.user_id AS user_id, "+
+			"MAX(LEAST(COALESCE(mine.left_at, ?), COALESCE(other.left_at, ?))) AS last_played_at, "+
+			"COUNT(DISTINCT mine.session_id) AS sessions_together", now, now).
+		Joins("JOIN lobby_session_participants AS other ON other.session_id = mine.session_id AND other.user_id <> mine.user_id").
+		Where("mine.user_id = ?", targetUserID).
+		Where("mine.joined_at < COALESCE(other.left_at, ?) AND other.joined_at < COALESCE(mine.left_at, ?)", now, now).
+		Group("other.user_id").
+		Order("last_played_at DESC").
+		Limit(playedWithLimit).
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve played with list"})
+		return
+	}
+
+	userIDs := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		userIDs = append(userIDs, row.UserID)
+	}
+	var users []models.User
+	if len(userIDs) > 0 {
+		database.DB.Find(&users, userIDs)
+	}
+	usersByID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	response := []PlayedWithResponse{}
+	for _, row := range rows {
+		user, ok := usersByID[row.UserID]
+		if !ok {
+			continue
+		}
+		response = append(response, PlayedWithResponse{
+			User:             buildPublicUserResponse(user, viewerID),
+			LastPlayedAt:     row.LastPlayedAt,
+			SessionsTogether: row.SessionsTogether,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// region --- DTOs ---
+
+// LobbyTemplateInput defines the structure for creating or updating a lobby template.
+type LobbyTemplateInput struct {
+	Name          string  `json:"name" binding:"required,max=100" example:"Evening squad"`
+	GameID        uint    `json:"game_id" binding:"required"`
+	Description   string  `json:"description"`
+	MaxPlayers    int     `json:"max_players" binding:"required,min=2,max=10"`
+	Region        string  `json:"region" binding:"max=20" example:"eu"`
+	Language      string  `json:"language" binding:"max=10" example:"en"`
+	MinReputation float64 `json:"min_reputation" binding:"min=0"`
+}
+
+// LobbyTemplateResponse describes a saved lobby template.
+type LobbyTemplateResponse struct {
+	ID            uint         `json:"id"`
+	Name          string       `json:"name"`
+	Game          GameResponse `json:"game"`
+	Description   string       `json:"description"`
+	MaxPlayers    int          `json:"max_players"`
+	Region        string       `json:"region,omitempty"`
+	Language      string       `json:"language,omitempty"`
+	MinReputation float64      `json:"min_reputation"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// RehostResponse describes a lobby re-hosted from a past session.
+type RehostResponse struct {
+	Lobby   LobbyResponse         `json:"lobby"`
+	Invites []LobbyInviteResponse `json:"invites"`
+}
+
+func newLobbyTemplateResponse(template models.LobbyTemplate) LobbyTemplateResponse {
+	return LobbyTemplateResponse{
+		ID:            template.ID,
+		Name:          template.Name,
+		Game:          newGameResponse(template.Game, nil),
+		Description:   template.Description,
+		MaxPlayers:    template.MaxPlayers,
+		Region:        template.Region,
+		Language:      template.Language,
+		MinReputation: template.MinReputation,
+		CreatedAt:     template.CreatedAt,
+		UpdatedAt:     template.UpdatedAt,
+	}
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// applyLobbyTemplateInput copies the input onto a template.
+func applyLobbyTemplateInput(template *models.LobbyTemplate, input LobbyTemplateInput) {
+	template.Name = input.Name
+	template.GameID = input.GameID
+	template.Description = input.Description
+	template.MaxPlayers = input.MaxPlayers
+	template.Region = normalizeLocaleCode(input.Region)
+	template.Language = normalizeLocaleCode(input.Language)
+	template.MinReputation = input.MinReputation
+}
+
+// findMyLobbyTemplate loads a template owned by the current user.
+func findMyLobbyTemplate(c *gin.Context) (*models.LobbyTemplate, bool) {
+	userID, _ := c.Get("userID")
+	templateID, err := strconv.ParseUint(c.Param("templateID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return nil, false
+	}
+
+	var template models.LobbyTemplate
+	if err := database.DB.Where("id = ? AND owner_id = ?", templateID, userID).First(&template).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return nil, false
+	}
+	return &template, true
+}
+
+// gameExists reports whether a game with the given ID exists.
+func gameExists(gameID uint) bool {
+	var count int64
+	database.DB.Model(&models.Game{}).Where("id = ?", gameID).Count(&count)
+	return count > 0
+}
+
+// endregion
+
+// GetMyLobbyTemplates godoc
+// @Summary      Get my lobby templates
+// @Description  Lists the lobby templates saved by the current user.
+// @Tags         lobby-templates
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} LobbyTemplateResponse
+// @Router       /users/me/lobby-templates [get]
+func GetMyLobbyTemplates(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var templates []models.LobbyTemplate
+	if err := database.DB.Preload("Game.Tags").Where("owner_id = ?", userID).Order("name ASC").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve templates"})
+		return
+	}
+
+	response := []LobbyTemplateResponse{}
+	for _, template := range templates {
+		response = append(response, newLobbyTemplateResponse(template))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateLobbyTemplate godoc
+// @Summary      Save a lobby template
+// @Description  Saves lobby settings under a name so the same lobby can be created again in one click.
+// @Tags         lobby-templates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body LobbyTemplateInput true "Template"
+// @Success      201 {object} LobbyTemplateResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Game not found"
+// @Router       /users/me/lobby-templates [post]
+func CreateLobbyTemplate(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input LobbyTemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !gameExists(input.GameID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
+		return
+	}
+
+	template := models.LobbyTemplate{OwnerID: userID.(uint)}
+	applyLobbyTemplateInput(&template, input)
+
+	if err := database.DB.Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save template"})
+		return
+	}
+
+	database.DB.Preload("Game.Tags").First(&template, template.ID)
+	c.JSON(http.StatusCreated, newLobbyTemplateResponse(template))
+}
+
+// UpdateLobbyTemplate godoc
+// @Summary      Update a lobby template
+// @Description  Replaces the settings of one of the current user's lobby templates.
+// @Tags         lobby-templates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        templateID path int                true "Template ID"
+// @Param        input      body LobbyTemplateInput true "Template"
+// @Success      200 {object} LobbyTemplateResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Template or game not found"
+// @Router       /users/me/lobby-templates/{templateID} [put]
+func UpdateLobbyTemplate(c *gin.Context) {
+	template, ok := findMyLobbyTemplate(c)
+	if !ok {
+		return
+	}
+
+	var input LobbyTemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !gameExists(input.GameID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
+		return
+	}
+
+	applyLobbyTemplateInput(template, input)
+	if err := database.DB.Omit("Game").Save(template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
+		return
+	}
+
+	database.DB.Preload("Game.Tags").First(template, template.ID)
+	c.JSON(http.StatusOK, newLobbyTemplateResponse(*template))
+}
+
+// DeleteLobbyTemplate godoc
+// @Summary      Delete a lobby template
+// @Description  Deletes one of the current user's lobby templates.
+// @Tags         lobby-templates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        templateID path int true "Template ID"
+// @Success      200 {object} map[string]string "{"message": "Template deleted"}"
+// @Failure      404 {object} ErrorResponse "Template not found"
+// @Router       /users/me/lobby-templates/{templateID} [delete]
+func DeleteLobbyTemplate(c *gin.Context) {
+	template, ok := findMyLobbyTemplate(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Delete(template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted"})
+}
+
+// CreateLobbyFromTemplate godoc
+// @Summary      Create a lobby from a template
+// @Description  Creates a new lobby with the settings of one of the current user's templates, making the user the host.
+// @Tags         lobby-templates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        templateID path int true "Template ID"
+// @Success      201 {object} LobbyResponse
+// @Failure      404 {object} ErrorResponse "Template not found"
+// @Failure      409 {object} ErrorResponse "User is already in a lobby"
+// @Router       /lobbies/from-template/{templateID} [post]
+func CreateLobbyFromTemplate(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.CurrentLobbyID != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+		return
+	}
+
+	template, ok := findMyLobbyTemplate(c)
+	if !ok {
+		return
+	}
+
+	lobby := models.Lobby{
+		GameID:        template.GameID,
+		Description:   template.Description,
+		MaxPlayers:    template.MaxPlayers,
+		Region:        template.Region,
+		Language:      template.Language,
+		MinReputation: template.MinReputation,
+	}
+	if err := createLobby(user, &lobby); err != nil {
+		if errors.Is(err, errInAnotherLobby) {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create lobby"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newLobbyResponse(lobby))
+}
+
+// RehostSession godoc
+// @Summary      Re-host a past session
+// @Description  Creates a new lobby with the settings of a past session's lobby and invites everyone who took part in it. A group-only lobby is re-hosted for the same group, which requires the user to still be a member of it.
+// @Tags         lobby-templates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sessionID path int true "Session ID"
+// @Success      201 {object} RehostResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "User is not a member of this lobby's group"
+// @Failure      404 {object} ErrorResponse "Session not found"
+// @Failure      409 {object} ErrorResponse "User is already in a lobby"
+// @Router       /users/me/history/{sessionID}/rehost [post]
+func RehostSession(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	sessionID, err := strconv.ParseUint(c.Param("sessionID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.CurrentLobbyID != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+		return
+	}
+
+	// Only participants may re-host a session
+	var session models.LobbySession
+	if err := database.DB.Preload("Participants").
+		Where("id = ? AND id IN (?)", sessionID,
+			database.DB.Model(&models.LobbySessionParticipant{}).Select("session_id").Where("user_id = ?", user.ID)).
+		First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	// The original lobby is usually deleted by now, but its settings are still there
+	var previous models.Lobby
+	if err := database.DB.Unscoped().First(&previous, session.LobbyID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session lobby not found"})
+		return
+	}
+
+	// A group-only lobby stays group-only, so only group members can re-host it
+	if previous.GroupID != nil {
+		if _, ok := findGroupMember(database.DB, *previous.GroupID, user.ID); !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "User is not a member of this lobby's group"})
+			return
+		}
+	}
+
+	lobby := models.Lobby{
+		GameID:        session.GameID,
+		Description:   previous.Description,
+		MaxPlayers:    previous.MaxPlayers,
+		Region:        previous.Region,
+		Language:      previous.Language,
+		GroupID:       previous.GroupID,
+		MinReputation: previous.MinReputation,
+	}
+	if err := createLobby(user, &lobby); err != nil {
+		if errors.Is(err, errInAnotherLobby) {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create lobby"})
+		return
+	}
+
+	if lobby.GroupID != nil {
+		sendToGroup(*lobby.GroupID, hub.Event{
+			Type:    EventGroupLobbyCreated,
+			Payload: newLobbyResponse(lobby),
+		})
+	}
+
+	invites := []LobbyInviteResponse{}
+	invited := map[uint]bool{user.ID: true}
+	for _, participant := range session.Participants {
+		if invited[participant.UserID] {
+			continue
+		}
+		invited[participant.UserID] = true
+
+		// Best-effort: a participant who cannot be invited is simply skipped
+		if invite, err := createLobbyInvite(lobby.ID, user, participant.UserID); err == nil {
+			invites = append(invites, newLobbyInviteResponse(*invite))
+		}
+	}
+
+	c.JSON(http.StatusCreated, RehostResponse{
+		Lobby:   newLobbyResponse(lobby),
+		Invites: invites,
+	})
+}
@@ -0,0 +1,398 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// waitlistOfferWindow is how long a waitlisted user has to accept an offered slot.
+const waitlistOfferWindow = 2 * time.Minute
+
+// waitlistSweepInterval is how often expired offers are released to the next user in line.
+const waitlistSweepInterval = 15 * time.Second
+
+var (
+	errNoWaitlistOffer      = errors.New("no pending waitlist offer")
+	errWaitlistOfferExpired = errors.New("waitlist offer expired")
+	errLobbyFull            = errors.New("lobby is full")
+)
+
+// lobbyAccessDenied is the reason, as given by lobbyAccessError, a user may not enter a lobby.
+type lobbyAccessDenied string
+
+func (e lobbyAccessDenied) Error() string { return string(e) }
+
+// region --- DTOs ---
+
+// WaitlistEntryResponse describes the current user's place on a lobby waitlist.
+type WaitlistEntryResponse struct {
+	LobbyID        uint                  `json:"lobby_id"`
+	Status         models.WaitlistStatus `json:"status"`
+	Position       int64                 `json:"position"` // 1-based position among waiting users, 0 once a slot is offered
+	OfferExpiresAt *time.Time            `json:"offer_expires_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
+
+func newWaitlistEntryResponse(entry models.LobbyWaitlistEntry, position int64) WaitlistEntryResponse {
+	return WaitlistEntryResponse{
+		LobbyID:        entry.LobbyID,
+		Status:         entry.Status,
+		Position:       position,
+		OfferExpiresAt: entry.OfferExpiresAt,
+		CreatedAt:      entry.CreatedAt,
+	}
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// waitlistPosition returns the 1-based position of a waiting entry in its lobby's queue, or 0 for an offered entry.
+func waitlistPosition(db *gorm.DB, entry models.LobbyWaitlistEntry) int64 {
+	if entry.Status != models.WaitlistStatusWaiting {
+		return 0
+	}
+
+	var ahead int64
+	db.Model(&models.LobbyWaitlistEntry{}).
+		Where("lobby_id = ? AND status = ? AND (created_at < ? OR (created_at = ? AND id < ?))",
+			entry.LobbyID, models.WaitlistStatusWaiting, entry.CreatedAt, entry.CreatedAt, entry.ID).
+		Count(&ahead)
+	return ahead + 1
+}
+
+// countReservedSlots returns the number of slots currently offered to waitlisted users other than exceptUserID.
+func countReservedSlots(db *gorm.DB, lobbyID, exceptUserID uint) int64 {
+	var reserved int64
+	db.Model(&models.LobbyWaitlistEntry{}).
+		Where("lobby_id = ? AND user_id <> ? AND status = ? AND offer_expires_at > ?",
+			lobbyID, exceptUserID, models.WaitlistStatusOffered, time.Now()).
+		Count(&reserved)
+	return reserved
+}
+
+// offerWaitlistSlots offers every free slot of the lobby to the next waiting users
+// and tells the users still waiting their new position.
+func offerWaitlistSlots(lobbyID uint) {
+	var lobby models.Lobby
+	if err := database.DB.First(&lobby, lobbyID).Error; err != nil {
+		return
+	}
+
+	var members int64
+	database.DB.Model(&models.User{}).Where("current_lobby_id = ?", lobbyID).Count(&members)
+
+	free := lobby.MaxPlayers - int(members) - int(countReservedSlots(database.DB, lobbyID, 0))
+	if free > 0 {
+		var next []models.LobbyWaitlistEntry
+		database.DB.Where("lobby_id = ? AND status = ?", lobbyID, models.WaitlistStatusWaiting).
+			Order("created_at ASC, id ASC").
+			Limit(free).
+			Find(&next)
+
+		for _, entry := range next {
+			expiresAt := time.Now().Add(waitlistOfferWindow)
+			entry.Status = models.WaitlistStatusOffered
+			entry.OfferExpiresAt = &expiresAt
+			if err := database.DB.Model(&entry).Select("Status", "OfferExpiresAt").Updates(&entry).Error; err != nil {
+				continue
+			}
+
+			hub.GlobalHub.SendToUser(entry.UserID, hub.Event{
+				Type:    EventWaitlistOffer,
+				Payload: newWaitlistEntryResponse(entry, 0),
+			})
+		}
+	}
+
+	notifyWaitlistPositions(lobbyID)
+}
+
+// notifyWaitlistPositions sends every waiting user of the lobby their current queue position.
+func notifyWaitlistPositions(lobbyID uint) {
+	var waiting []models.LobbyWaitlistEntry
+	database.DB.Where("lobby_id = ? AND status = ?", lobbyID, models.WaitlistStatusWaiting).
+		Order("created_at ASC, id ASC").
+		Find(&waiting)
+
+	for i, entry := range waiting {
+		hub.GlobalHub.SendToUser(entry.UserID, hub.Event{
+			Type:    EventWaitlistPosition,
+			Payload: newWaitlistEntryResponse(entry, int64(i+1)), // Already ordered, no need to count again
+		})
+	}
+}
+
+// closeWaitlist drops the waitlist of a deleted lobby and tells the queued users.
+func closeWaitlist(lobbyID uint) {
+	var entries []models.LobbyWaitlistEntry
+	database.DB.Where("lobby_id = ?", lobbyID).Find(&entries)
+	if len(entries) == 0 {
+		return
+	}
+
+	database.DB.Where("lobby_id = ?", lobbyID).Delete(&models.LobbyWaitlistEntry{})
+
+	for _, entry := range entries {
+		hub.GlobalHub.SendToUser(entry.UserID, hub.Event{
+			Type:    EventWaitlistClosed,
+			Payload: LobbyRefPayload{LobbyID: lobbyID},
+		})
+	}
+}
+
+// leaveWaitlists takes the user off every waitlist, passing their offered slots on to the next users in line.
+func leaveWaitlists(userID uint) {
+	var entries []models.LobbyWaitlistEntry
+	database.DB.Where("user_id = ?", userID).Find(&entries)
+	if len(entries) == 0 {
+		return
+	}
+
+	database.DB.Where("user_id = ?", userID).Delete(&models.LobbyWaitlistEntry{})
+	for _, entry := range entries {
+		offerWaitlistSlots(entry.LobbyID)
+	}
+}
+
+// expireWaitlistOffers removes offers that were not accepted in time and passes the slots on.
+func expireWaitlistOffers() {
+	var expired []models.LobbyWaitlistEntry
+	if err := database.DB.Where("status = ? AND offer_expires_at <= ?", models.WaitlistStatusOffered, time.Now()).
+		Find(&expired).Error; err != nil {
+		log.Printf("Failed to load expired waitlist offers: %v", err)
+		return
+	}
+
+	lobbyIDs := make(map[uint]bool)
+	for _, entry := range expired {
+		result := database.DB.Where("id = ? AND status = ?", entry.ID, models.WaitlistStatusOffered).Delete(&models.LobbyWaitlistEntry{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+
+		hub.GlobalHub.SendToUser(entry.UserID, hub.Event{
+			Type:    EventWaitlistOfferExpired,
+			Payload: LobbyRefPayload{LobbyID: entry.LobbyID},
+		})
+		lobbyIDs[entry.LobbyID] = true
+	}
+
+	for lobbyID := range lobbyIDs {
+		offerWaitlistSlots(lobbyID)
+	}
+}
+
+// acceptWaitlistOffer puts the user into the lobby whose slot was offered to them.
+// Access is checked again, since the user may have been banned or lost reputation while waiting:
+// such a user loses their place and the slot goes to the next in line.
+// When the lobby has no room any more, e.g. because the host lowered its size, the user goes back to waiting in their old place.
+func acceptWaitlistOffer(user models.User, lobbyID uint) error {
+	var entry models.LobbyWaitlistEntry
+	if err := database.DB.Where("lobby_id = ? AND user_id = ? AND status = ?", lobbyID, user.ID, models.WaitlistStatusOffered).
+		First(&entry).Error; err != nil {
+		return errNoWaitlistOffer
+	}
+	if entry.OfferExpiresAt == nil || entry.OfferExpiresAt.Before(time.Now()) {
+		return errWaitlistOfferExpired
+	}
+	if user.CurrentLobbyID != nil {
+		return errInAnotherLobby
+	}
+
+	var lobby models.Lobby
+	if err := database.DB.Preload("Members").First(&lobby, lobbyID).Error; err != nil {
+		return errNoWaitlistOffer
+	}
+	if reason := lobbyAccessError(database.DB, lobby, user.ID); reason != "" {
+		database.DB.Delete(&entry)
+		offerWaitlistSlots(lobby.ID)
+		return lobbyAccessDenied(reason)
+	}
+	if len(lobby.Members)+int(countReservedSlots(database.DB, lobby.ID, user.ID)) >= lobby.MaxPlayers {
+		database.DB.Model(&entry).Updates(map[string]interface{}{
+			"status":           models.WaitlistStatusWaiting,
+			"offer_expires_at": nil,
+		})
+		notifyWaitlistPositions(lobby.ID)
+		return errLobbyFull
+	}
+
+	if err := addUserToLobby(user, lobby.ID); err != nil {
+		return err
+	}
+	notifyWaitlistPositions(lobby.ID)
+	return nil
+}
+
+// StartWaitlistWorker periodically releases expired waitlist offers. It must be called once, after the database is connected.
+func StartWaitlistWorker() {
+	go func() {
+		ticker := time.NewTicker(waitlistSweepInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			expireWaitlistOffers()
+		}
+	}()
+}
+
+// endregion
+
+// JoinWaitlist godoc
+// @Summary      Join the waitlist of a full lobby
+// @Description  Queues the user for the next free slot of a full lobby. The waitlist does not count as the user's current lobby.
+// @Description  When a slot frees up, the first waiting user receives a `waitlist_offer` event on `/users/me/events` and must accept it in time.
+// @Tags         lobbies-waitlist
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Lobby ID"
+// @Success      201 {object} WaitlistEntryResponse
+// @Failure      403 {object} ErrorResponse "User is banned from this lobby or not in its group"
+// @Failure      404 {object} ErrorResponse "Lobby not found"
+// @Failure      409 {object} ErrorResponse "Lobby is not full, user is already in it or already waiting"
+// @Router       /lobbies/{id}/waitlist [post]
+func JoinWaitlist(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	lobbyID, _ := strconv.Atoi(c.Param("id"))
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var lobby models.Lobby
+	if err := database.DB.Preload("Members").First(&lobby, lobbyID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lobby not found"})
+		return
+	}
+	if user.CurrentLobbyID != nil && *user.CurrentLobbyID == lobby.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already in this lobby"})
+		return
+	}
+	if reason := lobbyAccessError(database.DB, lobby, user.ID); reason != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": reason})
+		return
+	}
+	if len(lobby.Members)+int(countReservedSlots(database.DB, lobby.ID, user.ID)) < lobby.MaxPlayers {
+		c.JSON(http.StatusConflict, gin.H{"error": "Lobby has free slots, join it directly"})
+		return
+	}
+
+	entry := models.LobbyWaitlistEntry{
+		LobbyID: lobby.ID,
+		UserID:  user.ID,
+		Status:  models.WaitlistStatusWaiting,
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already on this waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newWaitlistEntryResponse(entry, waitlistPosition(database.DB, entry)))
+}
+
+// GetWaitlistEntry godoc
+// @Summary      Get my place on a lobby waitlist
+// @Description  Returns the user's status and position on the waitlist of a lobby.
+// @Tags         lobbies-waitlist
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Lobby ID"
+// @Success      200 {object} WaitlistEntryResponse
+// @Failure      404 {object} ErrorResponse "User is not on this waitlist"
+// @Router       /lobbies/{id}/waitlist [get]
+func GetWaitlistEntry(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	lobbyID, _ := strconv.Atoi(c.Param("id"))
+
+	var entry models.LobbyWaitlistEntry
+	if err := database.DB.Where("lobby_id = ? AND user_id = ?", lobbyID, userID).First(&entry).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not on this waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newWaitlistEntryResponse(entry, waitlistPosition(database.DB, entry)))
+}
+
+// LeaveWaitlist godoc
+// @Summary      Leave a lobby waitlist
+// @Description  Removes the user from the waitlist of a lobby. A pending slot offer is declined and passed on to the next user.
+// @Tags         lobbies-waitlist
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Lobby ID"
+// @Success      200 {object} map[string]string "{"message": "Left waitlist"}"
+// @Failure      404 {object} ErrorResponse "User is not on this waitlist"
+// @Router       /lobbies/{id}/waitlist [delete]
+func LeaveWaitlist(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	lobbyID, _ := strconv.Atoi(c.Param("id"))
+
+	result := database.DB.Where("lobby_id = ? AND user_id = ?", lobbyID, userID).Delete(&models.LobbyWaitlistEntry{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave waitlist"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not on this waitlist"})
+		return
+	}
+
+	offerWaitlistSlots(uint(lobbyID))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left waitlist"})
+}
+
+// AcceptWaitlistOffer godoc
+// @Summary      Accept an offered lobby slot
+// @Description  Joins the lobby using the slot offered to the user from its waitlist. The offer must be accepted before it expires.
+// @Description  A user who was banned from the lobby or no longer meets its requirements loses their place on the waitlist. When the lobby has no room any more, the user goes back to waiting in their old place.
+// @Tags         lobbies-waitlist
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Lobby ID"
+// @Success      200 {object} map[string]string "{"message": "Joined lobby successfully"}"
+// @Failure      403 {object} ErrorResponse "User is banned from this lobby or no longer meets its requirements"
+// @Failure      404 {object} ErrorResponse "No pending offer for this lobby"
+// @Failure      409 {object} ErrorResponse "User is in another lobby or the lobby is full"
+// @Failure      410 {object} ErrorResponse "Offer expired"
+// @Router       /lobbies/{id}/waitlist/accept [post]
+func AcceptWaitlistOffer(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	lobbyID, _ := strconv.Atoi(c.Param("id"))
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	err := acceptWaitlistOffer(user, uint(lobbyID))
+	var accessErr lobbyAccessDenied
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Joined lobby successfully"})
+	case errors.Is(err, errNoWaitlistOffer):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending offer for this lobby"})
+	case errors.Is(err, errWaitlistOfferExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Offer expired"})
+	case errors.Is(err, errInAnotherLobby):
+		c.JSON(http.StatusConflict, gin.H{"error": "Leave your current lobby before accepting the offer"})
+	case errors.Is(err, errLobbyFull):
+		c.JSON(http.StatusConflict, gin.H{"error": "Lobby is full, you are back on its waitlist"})
+	case errors.As(err, &accessErr):
+		c.JSON(http.StatusForbidden, gin.H{"error": accessErr.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join lobby"})
+	}
+}
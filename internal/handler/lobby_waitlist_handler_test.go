@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"playmatch/backend/internal/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// createTestWaitlistEntry queues the user on the lobby's waitlist, with an offer expiring at offerExpiresAt if it is set.
+func createTestWaitlistEntry(t *testing.T, db *gorm.DB, lobbyID, userID uint, offerExpiresAt *time.Time) models.LobbyWaitlistEntry {
+	t.Helper()
+
+	entry := models.LobbyWaitlistEntry{LobbyID: lobbyID, UserID: userID, Status: models.WaitlistStatusWaiting}
+	if offerExpiresAt != nil {
+		entry.Status = models.WaitlistStatusOffered
+		entry.OfferExpiresAt = offerExpiresAt
+	}
+	if err := db.Create(&entry).Error; err != nil {
+		t.Fatalf("create waitlist entry: %v", err)
+	}
+	return entry
+}
+
+// waitlistStatuses returns the waitlist status of each user of the lobby, with "" for users no longer on it.
+func waitlistStatuses(db *gorm.DB, lobbyID uint, users []models.User) []models.WaitlistStatus {
+	statuses := make([]models.WaitlistStatus, len(users))
+	for i, user := range users {
+		var entry models.LobbyWaitlistEntry
+		if err := db.Where("lobby_id = ? AND user_id = ?", lobbyID, user.ID).First(&entry).Error; err == nil {
+			statuses[i] = entry.Status
+		}
+	}
+	return statuses
+}
+
+func TestOfferWaitlistSlots(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxPlayers int
+		members    int // Besides the host
+		want       []models.WaitlistStatus
+	}{
+		{
+			name:       "full lobby offers nothing",
+			maxPlayers: 2,
+			members:    1,
+			want:       []models.WaitlistStatus{models.WaitlistStatusWaiting, models.WaitlistStatusWaiting, models.WaitlistStatusWaiting},
+		},
+		{
+			name:       "one free slot goes to the first in line",
+			maxPlayers: 3,
+			members:    1,
+			want:       []models.WaitlistStatus{models.WaitlistStatusOffered, models.WaitlistStatusWaiting, models.WaitlistStatusWaiting},
+		},
+		{
+			name:       "every free slot is offered",
+			maxPlayers: 4,
+			members:    0,
+			want:       []models.WaitlistStatus{models.WaitlistStatusOffered, models.WaitlistStatusOffered, models.WaitlistStatusOffered},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+
+			host := createTestUser(t, db, "host")
+			var members []models.User
+			for i := 0; i < tt.members; i++ {
+				members = append(members, createTestUser(t, db, fmt.Sprintf("member%d", i)))
+			}
+			lobby := createTestLobby(t, db, models.Lobby{MaxPlayers: tt.maxPlayers}, host, members...)
+
+			var waiting []models.User
+			for i := 0; i < len(tt.want); i++ {
+				user := createTestUser(t, db, fmt.Sprintf("waiting%d", i))
+				createTestWaitlistEntry(t, db, lobby.ID, user.ID, nil)
+				waiting = append(waiting, user)
+			}
+
+			offerWaitlistSlots(lobby.ID)
+
+			got := waitlistStatuses(db, lobby.ID, waiting)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("user %d is %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExpireWaitlistOffers(t *testing.T) {
+	db := testDB(t)
+
+	host := createTestUser(t, db, "host")
+	lobby := createTestLobby(t, db, models.Lobby{MaxPlayers: 2}, host)
+
+	expiredAt := time.Now().Add(-time.Second)
+	slow := createTestUser(t, db, "slow")
+	createTestWaitlistEntry(t, db, lobby.ID, slow.ID, &expiredAt)
+	next := createTestUser(t, db, "next")
+	createTestWaitlistEntry(t, db, lobby.ID, next.ID, nil)
+
+	expireWaitlistOffers()
+
+	got := waitlistStatuses(db, lobby.ID, []models.User{slow, next})
+	want := []models.WaitlistStatus{"", models.WaitlistStatusOffered}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("user %d is %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAcceptWaitlistOffer(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, db *gorm.DB, lobby *models.Lobby, user *models.User)
+		expired    bool
+		wantErr    error
+		wantDenied bool                  // Whether access is denied
+		wantStatus models.WaitlistStatus // Status of the entry afterwards, "" once it is gone
+		wantJoined bool
+	}{
+		{
+			name:       "accepted",
+			wantJoined: true,
+		},
+		{
+			name:       "offer expired",
+			expired:    true,
+			wantErr:    errWaitlistOfferExpired,
+			wantStatus: models.WaitlistStatusOffered,
+		},
+		{
+			name: "banned while waiting",
+			setup: func(t *testing.T, db *gorm.DB, lobby *models.Lobby, user *models.User) {
+				ban := models.LobbyBan{LobbyID: lobby.ID, UserID: user.ID, BannedByID: lobby.HostID}
+				if err := db.Create(&ban).Error; err != nil {
+					t.Fatalf("create ban: %v", err)
+				}
+			},
+			wantDenied: true,
+		},
+		{
+			name: "reputation below the lobby minimum",
+			setup: func(t *testing.T, db *gorm.DB, lobby *models.Lobby, user *models.User) {
+				db.Model(lobby).Update("min_reputation", 10)
+			},
+			wantDenied: true,
+		},
+		{
+			name: "lobby shrank since the offer",
+			setup: func(t *testing.T, db *gorm.DB, lobby *models.Lobby, user *models.User) {
+				db.Model(lobby).Update("max_players", 1)
+			},
+			wantErr:    errLobbyFull,
+			wantStatus: models.WaitlistStatusWaiting,
+		},
+		{
+			name: "slot reserved for someone else",
+			setup: func(t *testing.T, db *gorm.DB, lobby *models.Lobby, user *models.User) {
+				db.Model(lobby).Update("max_players", 2)
+				other := createTestUser(t, db, "other")
+				expiresAt := time.Now().Add(time.Minute)
+				createTestWaitlistEntry(t, db, lobby.ID, other.ID, &expiresAt)
+			},
+			wantErr:    errLobbyFull,
+			wantStatus: models.WaitlistStatusWaiting,
+		},
+		{
+			name: "in another lobby",
+			setup: func(t *testing.T, db *gorm.DB, lobby *models.Lobby, user *models.User) {
+				otherHost := createTestUser(t, db, "otherhost")
+				other := createTestLobby(t, db, models.Lobby{GameID: lobby.GameID}, otherHost)
+				db.Model(user).Updates(joinLobbyColumns(other.ID))
+				user.CurrentLobbyID = &other.ID
+			},
+			wantErr:    errInAnotherLobby,
+			wantStatus: models.WaitlistStatusOffered,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+
+			host := createTestUser(t, db, "host")
+			lobby := createTestLobby(t, db, models.Lobby{MaxPlayers: 2}, host)
+			user := createTestUser(t, db, "waiting")
+			expiresAt := time.Now().Add(time.Minute)
+			if tt.expired {
+				expiresAt = time.Now().Add(-time.Second)
+			}
+			createTestWaitlistEntry(t, db, lobby.ID, user.ID, &expiresAt)
+			if tt.setup != nil {
+				tt.setup(t, db, &lobby, &user)
+			}
+
+			err := acceptWaitlistOffer(user, lobby.ID)
+
+			var denied lobbyAccessDenied
+			switch {
+			case tt.wantDenied:
+				if !errors.As(err, &denied) {
+					t.Fatalf("acceptWaitlistOffer error = %v, want access denied", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("acceptWaitlistOffer error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("acceptWaitlistOffer error = %v", err)
+			}
+
+			if got := waitlistStatuses(db, lobby.ID, []models.User{user})[0]; got != tt.wantStatus {
+				t.Errorf("waitlist entry is %q, want %q", got, tt.wantStatus)
+			}
+
+			var reloaded models.User
+			db.First(&reloaded, user.ID)
+			joined := reloaded.CurrentLobbyID != nil && *reloaded.CurrentLobbyID == lobby.ID
+			if joined != tt.wantJoined {
+				t.Errorf("joined = %v, want %v", joined, tt.wantJoined)
+			}
+		})
+	}
+}
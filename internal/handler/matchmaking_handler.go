@@ -0,0 +1,434 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// matchmakingInterval is how often the matcher runs over the whole queue.
+const matchmakingInterval = 10 * time.Second
+
+// matchmakingLockKey identifies the Postgres advisory lock held while the matcher runs,
+// so that only one server instance matches the queue at a time.
+const matchmakingLockKey = 7301
+
+// matchmakingWake asks the worker to run the matcher before the next interval, e.g. after a user queued.
+var matchmakingWake = make(chan struct{}, 1)
+
+// region --- DTOs ---
+
+// MatchmakingInput defines the preferences of a user entering the matchmaking queue.
+type MatchmakingInput struct {
+	GameIDs   []uint `json:"game_ids" binding:"required,min=1,max=10"`
+	PartySize int    `json:"party_size" binding:"required,min=2,max=10" example:"4"`
+	Region    string `json:"region" binding:"max=20" example:"eu"`
+	Language  string `json:"language" binding:"max=10" example:"en"`
+}
+
+// MatchmakingStatusResponse describes the current user's place in the matchmaking queue.
+type MatchmakingStatusResponse struct {
+	Games     []GameResponse `json:"games"`
+	PartySize int            `json:"party_size"`
+	Region    string         `json:"region,omitempty"`
+	Language  string         `json:"language,omitempty"`
+	QueuedAt  time.Time      `json:"queued_at"`
+}
+
+// MatchFoundPayload is the payload of the match_found event and the response of a successful enqueue.
+type MatchFoundPayload struct {
+	Lobby  LobbyResponse `json:"lobby"`
+	Formed bool          `json:"formed"` // True when a new lobby was formed for the group, false when placed into an open lobby
+}
+
+func newMatchmakingStatusResponse(tickets []models.MatchmakingTicket) MatchmakingStatusResponse {
+	response := MatchmakingStatusResponse{
+		Games:     []GameResponse{},
+		PartySize: tickets[0].PartySize,
+		Region:    tickets[0].Region,
+		Language:  tickets[0].Language,
+		QueuedAt:  tickets[0].CreatedAt,
+	}
+	for _, ticket := range tickets {
+		response.Games = append(response.Games, newGameResponse(ticket.Game, nil))
+	}
+	return response
+}
+
+// endregion
+
+// region --- Matcher ---
+
+// leaveMatchmaking removes all tickets of the user from the queue.
+func leaveMatchmaking(userID uint) {
+	database.DB.Where("user_id = ?", userID).Delete(&models.MatchmakingTicket{})
+}
+
+// matchmakingGroupThreshold returns how many compatible players are needed to form a new lobby:
+// half of the party, but at least two. The remaining slots are filled from the queue later on.
+func matchmakingGroupThreshold(partySize int) int {
+	threshold := (partySize + 1) / 2
+	if threshold < 2 {
+		threshold = 2
+	}
+	return threshold
+}
+
+// localeCompatible reports whether two region or language preferences can be matched. Empty means any.
+func localeCompatible(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
+
+// findOpenLobbyForTicket looks for an open lobby the ticket's user can be placed into,
+// preferring the oldest lobbies so that they fill up first.
+func findOpenLobbyForTicket(ticket models.MatchmakingTicket) (*models.Lobby, bool) {
+	query := database.DB.Preload("Members").
+		Where("game_id = ? AND max_players = ? AND group_id IS NULL", ticket.GameID, ticket.PartySize)
+	if ticket.Region != "" {
+		query = query.Where("region IN ?", []string{ticket.Region, ""})
+	}
+	if ticket.Language != "" {
+		query = query.Where("language IN ?", []string{ticket.Language, ""})
+	}
+
+	var candidates []models.Lobby
+	if err := query.Order("created_at ASC").Limit(50).Find(&candidates).Error; err != nil {
+		return nil, false
+	}
+
+	for i := range candidates {
+		lobby := &candidates[i]
+		if len(lobby.Members)+int(countReservedSlots(database.DB, lobby.ID, ticket.UserID)) >= lobby.MaxPlayers {
+			continue
+		}
+		if lobbyAccessError(database.DB, *lobby, ticket.UserID) != "" {
+			continue
+		}
+		return lobby, true
+	}
+	return nil, false
+}
+
+// collectCompatibleTickets greedily gathers up to a full party of queued users compatible with the seed ticket.
+// It returns the group together with the region and language the new lobby should use.
+func collectCompatibleTickets(seed models.MatchmakingTicket, tickets []models.MatchmakingTicket, matched map[uint]bool) ([]models.MatchmakingTicket, string, string) {
+	group := []models.MatchmakingTicket{seed}
+	region, language := seed.Region, seed.Language
+	inGroup := map[uint]bool{seed.UserID: true}
+
+	for _, candidate := range tickets {
+		if len(group) == seed.PartySize {
+			break
+		}
+		if matched[candidate.UserID] || inGroup[candidate.UserID] || candidate.User.CurrentLobbyID != nil {
+			continue
+		}
+		if candidate.GameID != seed.GameID || candidate.PartySize != seed.PartySize {
+			continue
+		}
+		if !localeCompatible(region, candidate.Region) || !localeCompatible(language, candidate.Language) {
+			continue
+		}
+
+		if region == "" {
+			region = candidate.Region
+		}
+		if language == "" {
+			language = candidate.Language
+		}
+		group = append(group, candidate)
+		inGroup[candidate.UserID] = true
+	}
+
+	return group, region, language
+}
+
+// formMatchmakingLobby creates a lobby for a group of queued users and returns who was placed into it.
+// The longest-waiting user becomes the host; it fails with errInAnotherLobby when that user is in a lobby already.
+// Other users of the group who entered a lobby in the meantime are left out.
+func formMatchmakingLobby(group []models.MatchmakingTicket, region, language string) (*models.Lobby, map[uint]bool, error) {
+	lobby := models.Lobby{
+		GameID:      group[0].GameID,
+		Description: "Group found by matchmaking",
+		MaxPlayers:  group[0].PartySize,
+		Region:      region,
+		Language:    language,
+	}
+	if err := createLobby(group[0].User, &lobby); err != nil {
+		return nil, nil, err
+	}
+
+	placed := map[uint]bool{group[0].UserID: true}
+	for _, ticket := range group[1:] {
+		err := addUserToLobby(ticket.User, lobby.ID)
+		switch {
+		case err == nil:
+			placed[ticket.UserID] = true
+		case errors.Is(err, errInAnotherLobby):
+			leaveMatchmaking(ticket.UserID)
+		default:
+			log.Printf("Failed to add user %d to matchmaking lobby %d: %v", ticket.UserID, lobby.ID, err)
+		}
+	}
+
+	database.DB.Preload("Game").Preload("Host").Preload("Members").First(&lobby, lobby.ID)
+	return &lobby, placed, nil
+}
+
+// notifyMatchFound tells a user which lobby the matcher placed them into.
+func notifyMatchFound(userID uint, lobby models.Lobby, formed bool) {
+	hub.GlobalHub.SendToUser(userID, hub.Event{
+		Type: EventMatchFound,
+		Payload: MatchFoundPayload{
+			Lobby:  newLobbyResponse(lobby),
+			Formed: formed,
+		},
+	})
+}
+
+// runMatchmaking runs the matcher unless another server instance is running it already.
+func runMatchmaking() {
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		log.Printf("Failed to get database connection for matchmaking: %v", err)
+		return
+	}
+
+	// Advisory locks belong to a session, so the lock is taken and released on one dedicated connection
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		log.Printf("Failed to get database connection for matchmaking: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", matchmakingLockKey).Scan(&locked); err != nil {
+		log.Printf("Failed to lock the matchmaking queue: %v", err)
+		return
+	}
+	if !locked {
+		return
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", matchmakingLockKey)
+
+	matchQueue()
+}
+
+// matchQueue processes the queue in arrival order. Each queued user is first placed into a compatible
+// open lobby; if there is none, a new lobby is formed once enough compatible users are queued.
+// Users who entered a lobby on their own since the queue was loaded are never moved: placing them fails and their tickets are dropped.
+func matchQueue() {
+	var tickets []models.MatchmakingTicket
+	if err := database.DB.Preload("User").Order("created_at ASC, id ASC").Find(&tickets).Error; err != nil {
+		log.Printf("Failed to load matchmaking queue: %v", err)
+		return
+	}
+
+	matched := make(map[uint]bool)
+	for _, ticket := range tickets {
+		if matched[ticket.UserID] {
+			continue
+		}
+
+		// The user joined a lobby on their own in the meantime
+		if ticket.User.CurrentLobbyID != nil {
+			leaveMatchmaking(ticket.UserID)
+			matched[ticket.UserID] = true
+			continue
+		}
+
+		if lobby, ok := findOpenLobbyForTicket(ticket); ok {
+			if err := addUserToLobby(ticket.User, lobby.ID); err != nil {
+				if errors.Is(err, errInAnotherLobby) {
+					leaveMatchmaking(ticket.UserID)
+					matched[ticket.UserID] = true
+					continue
+				}
+				log.Printf("Failed to place user %d into lobby %d: %v", ticket.UserID, lobby.ID, err)
+				continue
+			}
+			matched[ticket.UserID] = true
+			database.DB.Preload("Game").Preload("Host").Preload("Members").First(lobby, lobby.ID)
+			notifyMatchFound(ticket.UserID, *lobby, false)
+			continue
+		}
+
+		group, region, language := collectCompatibleTickets(ticket, tickets, matched)
+		if len(group) < matchmakingGroupThreshold(ticket.PartySize) {
+			continue
+		}
+
+		lobby, placed, err := formMatchmakingLobby(group, region, language)
+		if errors.Is(err, errInAnotherLobby) {
+			leaveMatchmaking(ticket.UserID)
+			matched[ticket.UserID] = true
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to form matchmaking lobby: %v", err)
+			continue
+		}
+		for _, member := range group {
+			matched[member.UserID] = true
+			if placed[member.UserID] {
+				notifyMatchFound(member.UserID, *lobby, true)
+			}
+		}
+	}
+}
+
+// wakeMatchmaking makes the worker run the matcher soon, without waiting for it.
+func wakeMatchmaking() {
+	select {
+	case matchmakingWake <- struct{}{}:
+	default: // A run is already pending
+	}
+}
+
+// StartMatchmakingWorker periodically runs the matcher, and whenever it is woken up.
+// It must be called once, after the database is connected.
+func StartMatchmakingWorker() {
+	go func() {
+		ticker := time.NewTicker(matchmakingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-matchmakingWake:
+			}
+			runMatchmaking()
+		}
+	}()
+}
+
+// endregion
+
+// EnqueueMatchmaking godoc
+// @Summary      Find me a group
+// @Description  Puts the user into the matchmaking queue for one or more games. The user is placed into a compatible open lobby,
+// @Description  or a new lobby is formed once enough compatible players are queued. The matcher runs in the background, shortly after the user queued
+// @Description  and then every 10 seconds; matches are announced with a `match_found` event on `/users/me/events`.
+// @Tags         matchmaking
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body MatchmakingInput true "Matchmaking preferences"
+// @Success      202 {object} MatchmakingStatusResponse "Queued"
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Game not found"
+// @Failure      409 {object} ErrorResponse "User is already in a lobby or in the queue"
+// @Router       /matchmaking/queue [post]
+func EnqueueMatchmaking(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input MatchmakingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.CurrentLobbyID != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already in a lobby"})
+		return
+	}
+
+	var queued int64
+	database.DB.Model(&models.MatchmakingTicket{}).Where("user_id = ?", user.ID).Count(&queued)
+	if queued > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already in the matchmaking queue"})
+		return
+	}
+
+	var games []models.Game
+	// Every requested game must exist; duplicates in the input count once
+	distinctIDs := map[uint]bool{}
+	for _, id := range input.GameIDs {
+		distinctIDs[id] = true
+	}
+	database.DB.Find(&games, input.GameIDs)
+	if len(games) != len(distinctIDs) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
+		return
+	}
+
+	tickets := make([]models.MatchmakingTicket, 0, len(games))
+	for _, game := range games {
+		tickets = append(tickets, models.MatchmakingTicket{
+			UserID:    user.ID,
+			GameID:    game.ID,
+			PartySize: input.PartySize,
+			Region:    normalizeLocaleCode(input.Region),
+			Language:  normalizeLocaleCode(input.Language),
+		})
+	}
+	if err := database.DB.Create(&tickets).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join the matchmaking queue"})
+		return
+	}
+
+	wakeMatchmaking()
+
+	database.DB.Preload("Game").Where("user_id = ?", user.ID).Order("id ASC").Find(&tickets)
+	c.JSON(http.StatusAccepted, newMatchmakingStatusResponse(tickets))
+}
+
+// GetMatchmakingStatus godoc
+// @Summary      Get my matchmaking status
+// @Description  Returns the games and preferences the user is queued with.
+// @Tags         matchmaking
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} MatchmakingStatusResponse
+// @Failure      404 {object} ErrorResponse "User is not in the matchmaking queue"
+// @Router       /matchmaking/queue [get]
+func GetMatchmakingStatus(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var tickets []models.MatchmakingTicket
+	database.DB.Preload("Game").Where("user_id = ?", userID).Order("id ASC").Find(&tickets)
+	if len(tickets) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in the matchmaking queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newMatchmakingStatusResponse(tickets))
+}
+
+// LeaveMatchmaking godoc
+// @Summary      Leave the matchmaking queue
+// @Description  Removes the user from the matchmaking queue for all games.
+// @Tags         matchmaking
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} map[string]string "{"message": "Left the matchmaking queue"}"
+// @Failure      404 {object} ErrorResponse "User is not in the matchmaking queue"
+// @Router       /matchmaking/queue [delete]
+func LeaveMatchmaking(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	result := database.DB.Where("user_id = ?", userID).Delete(&models.MatchmakingTicket{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave the matchmaking queue"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in the matchmaking queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left the matchmaking queue"})
+}
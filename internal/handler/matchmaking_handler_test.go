@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"fmt"
+	"playmatch/backend/internal/models"
+	"testing"
+)
+
+func TestMatchmakingGroupThreshold(t *testing.T) {
+	tests := []struct {
+		partySize int
+		want      int
+	}{
+		{partySize: 2, want: 2},
+		{partySize: 3, want: 2},
+		{partySize: 4, want: 2},
+		{partySize: 5, want: 3},
+		{partySize: 6, want: 3},
+		{partySize: 10, want: 5},
+	}
+
+	for _, tt := range tests {
+		if got := matchmakingGroupThreshold(tt.partySize); got != tt.want {
+			t.Errorf("matchmakingGroupThreshold(%d) = %d, want %d", tt.partySize, got, tt.want)
+		}
+	}
+}
+
+// testTicket returns a ticket of the user for game 1.
+func testTicket(userID uint, partySize int, region, language string) models.MatchmakingTicket {
+	return models.MatchmakingTicket{UserID: userID, GameID: 1, PartySize: partySize, Region: region, Language: language}
+}
+
+func TestCollectCompatibleTickets(t *testing.T) {
+	lobbyID := uint(7)
+	inLobby := testTicket(9, 4, "", "")
+	inLobby.User.CurrentLobbyID = &lobbyID
+	otherGame := testTicket(10, 4, "", "")
+	otherGame.GameID = 2
+
+	tests := []struct {
+		name         string
+		seed         models.MatchmakingTicket
+		tickets      []models.MatchmakingTicket
+		matched      map[uint]bool
+		wantUsers    []uint
+		wantRegion   string
+		wantLanguage string
+	}{
+		{
+			name:         "same preferences",
+			seed:         testTicket(1, 4, "eu", "en"),
+			tickets:      []models.MatchmakingTicket{testTicket(1, 4, "eu", "en"), testTicket(2, 4, "eu", "en")},
+			wantUsers:    []uint{1, 2},
+			wantRegion:   "eu",
+			wantLanguage: "en",
+		},
+		{
+			name:         "open seed takes the locale of the first candidate",
+			seed:         testTicket(1, 4, "", ""),
+			tickets:      []models.MatchmakingTicket{testTicket(2, 4, "eu", ""), testTicket(3, 4, "us", "de"), testTicket(4, 4, "", "de")},
+			wantUsers:    []uint{1, 2, 4},
+			wantRegion:   "eu",
+			wantLanguage: "de",
+		},
+		{
+			name:         "different region or language is skipped",
+			seed:         testTicket(1, 4, "eu", "en"),
+			tickets:      []models.MatchmakingTicket{testTicket(2, 4, "us", "en"), testTicket(3, 4, "eu", "fr"), testTicket(4, 4, "", "")},
+			wantUsers:    []uint{1, 4},
+			wantRegion:   "eu",
+			wantLanguage: "en",
+		},
+		{
+			name:      "stops at the party size",
+			seed:      testTicket(1, 3, "", ""),
+			tickets:   []models.MatchmakingTicket{testTicket(2, 3, "", ""), testTicket(3, 3, "", ""), testTicket(4, 3, "", "")},
+			wantUsers: []uint{1, 2, 3},
+		},
+		{
+			name:      "other game or party size is skipped",
+			seed:      testTicket(1, 4, "", ""),
+			tickets:   []models.MatchmakingTicket{otherGame, testTicket(11, 5, "", ""), testTicket(12, 4, "", "")},
+			wantUsers: []uint{1, 12},
+		},
+		{
+			name:      "matched users and users in a lobby are skipped",
+			seed:      testTicket(1, 4, "", ""),
+			tickets:   []models.MatchmakingTicket{testTicket(2, 4, "", ""), inLobby, testTicket(3, 4, "", "")},
+			matched:   map[uint]bool{2: true},
+			wantUsers: []uint{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched := tt.matched
+			if matched == nil {
+				matched = map[uint]bool{}
+			}
+
+			group, region, language := collectCompatibleTickets(tt.seed, tt.tickets, matched)
+
+			var users []uint
+			for _, ticket := range group {
+				users = append(users, ticket.UserID)
+			}
+			if fmt.Sprint(users) != fmt.Sprint(tt.wantUsers) {
+				t.Errorf("group = %v, want %v", users, tt.wantUsers)
+			}
+			if region != tt.wantRegion || language != tt.wantLanguage {
+				t.Errorf("locale = %q/%q, want %q/%q", region, language, tt.wantRegion, tt.wantLanguage)
+			}
+		})
+	}
+}
+
+func TestFindOpenLobbyForTicket(t *testing.T) {
+	tests := []struct {
+		name      string
+		lobby     models.Lobby
+		members   int // Besides the host
+		groupOnly bool
+		ticket    models.MatchmakingTicket // UserID and GameID are filled in
+		want      bool
+	}{
+		{
+			name:   "matching lobby",
+			lobby:  models.Lobby{MaxPlayers: 4, Region: "eu", Language: "en"},
+			ticket: models.MatchmakingTicket{PartySize: 4, Region: "eu", Language: "en"},
+			want:   true,
+		},
+		{
+			name:   "lobby open to any locale",
+			lobby:  models.Lobby{MaxPlayers: 4},
+			ticket: models.MatchmakingTicket{PartySize: 4, Region: "eu", Language: "en"},
+			want:   true,
+		},
+		{
+			name:   "other region",
+			lobby:  models.Lobby{MaxPlayers: 4, Region: "us"},
+			ticket: models.MatchmakingTicket{PartySize: 4, Region: "eu"},
+		},
+		{
+			name:   "other party size",
+			lobby:  models.Lobby{MaxPlayers: 5},
+			ticket: models.MatchmakingTicket{PartySize: 4},
+		},
+		{
+			name:    "full lobby",
+			lobby:   models.Lobby{MaxPlayers: 2},
+			members: 1,
+			ticket:  models.MatchmakingTicket{PartySize: 2},
+		},
+		{
+			name:   "reputation below minimum",
+			lobby:  models.Lobby{MaxPlayers: 4, MinReputation: 50},
+			ticket: models.MatchmakingTicket{PartySize: 4},
+		},
+		{
+			name:      "group-only lobby",
+			lobby:     models.Lobby{MaxPlayers: 4},
+			groupOnly: true,
+			ticket:    models.MatchmakingTicket{PartySize: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+
+			host := createTestUser(t, db, "host")
+			if tt.groupOnly {
+				group := models.Group{Name: "squad", OwnerID: host.ID}
+				if err := db.Create(&group).Error; err != nil {
+					t.Fatalf("create group: %v", err)
+				}
+				tt.lobby.GroupID = &group.ID
+			}
+			var members []models.User
+			for i := 0; i < tt.members; i++ {
+				members = append(members, createTestUser(t, db, fmt.Sprintf("member%d", i)))
+			}
+			lobby := createTestLobby(t, db, tt.lobby, host, members...)
+
+			tt.ticket.UserID = createTestUser(t, db, "queued").ID
+			tt.ticket.GameID = lobby.GameID
+
+			found, ok := findOpenLobbyForTicket(tt.ticket)
+			if ok != tt.want {
+				t.Fatalf("found = %v, want %v", ok, tt.want)
+			}
+			if ok && found.ID != lobby.ID {
+				t.Errorf("found lobby %d, want %d", found.ID, lobby.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,1125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// messageEditWindow is how long after posting authors can edit or delete their messages.
+	messageEditWindow = 15 * time.Minute
+
+	// replyPreviewLength caps the characters of the parent message shown with a reply.
+	replyPreviewLength = 100
+
+	// maxReactionEmojis caps the distinct emojis a single message can collect.
+	maxReactionEmojis = 20
+)
+
+// mentionPattern matches @nickname mentions in message content.
+var mentionPattern = regexp.MustCompile(`@([\w.-]+)`)
+
+var (
+	errNotInLobby    = errors.New("user is not in a lobby")
+	errReplyNotFound = errors.New("replied message not found in this chat")
+)
+
+// region --- DTOs ---
+
+// MessageEditResponse describes an earlier version of an edited message.
+type MessageEditResponse struct {
+	PreviousContent string    `json:"previous_content"`
+	EditedAt        time.Time `json:"edited_at"`
+}
+
+// MessageDeletedPayload is broadcast when a message is deleted by its author or removed by a moderator.
+type MessageDeletedPayload struct {
+	MessageID      uint  `json:"message_id"`
+	LobbyID        *uint `json:"lobby_id,omitempty"`
+	GroupID        *uint `json:"group_id,omitempty"`
+	ConversationID *uint `json:"conversation_id,omitempty"`
+	Removed        bool  `json:"removed"` // True when a moderator removed the message
+}
+
+// MessageCursorMeta describes a window of messages fetched with a message ID cursor.
+type MessageCursorMeta struct {
+	HasMore  bool  `json:"has_more"`         // More messages exist beyond the window in the requested direction
+	Before   *uint `json:"before,omitempty"` // Cursor for older messages, the ID of the oldest message in the window
+	After    *uint `json:"after,omitempty"`  // Cursor for newer messages, the ID of the newest message in the window
+	PageSize int   `json:"page_size"`
+}
+
+// MessageCursorResponse defines the structure for a window of messages fetched with a message ID cursor.
+type MessageCursorResponse struct {
+	Data   []MessageResponse `json:"data"`
+	Meta   MessageCursorMeta `json:"meta"`
+	Pinned []MessageResponse `json:"pinned,omitempty"` // Pinned messages of a lobby chat
+}
+
+// ReplyPreviewResponse describes the message a reply refers to.
+type ReplyPreviewResponse struct {
+	ID       uint   `json:"id"`
+	UserID   *uint  `json:"user_id,omitempty"`
+	Nickname string `json:"nickname,omitempty"`
+	Content  string `json:"content,omitempty"` // Shortened to the first 100 characters
+	Deleted  bool   `json:"deleted"`           // True when the parent message has been deleted
+}
+
+// MentionResponse describes an @nickname mention inside a message.
+type MentionResponse struct {
+	UserID uint `json:"user_id"`
+	Offset int  `json:"offset"` // Position of the @ sign, in characters
+	Length int  `json:"length"` // Length including the @ sign, in characters
+}
+
+// ReactionResponse describes the users who reacted to a message with an emoji.
+type ReactionResponse struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	UserIDs []uint `json:"user_ids"`
+}
+
+// ReactionInput defines the emoji to react with.
+type ReactionInput struct {
+	Emoji string `json:"emoji" binding:"required,max=32"`
+}
+
+// ReactionsUpdatedPayload is broadcast when the reactions of a message change.
+type ReactionsUpdatedPayload struct {
+	MessageID      uint               `json:"message_id"`
+	LobbyID        *uint              `json:"lobby_id,omitempty"`
+	GroupID        *uint              `json:"group_id,omitempty"`
+	ConversationID *uint              `json:"conversation_id,omitempty"`
+	Reactions      []ReactionResponse `json:"reactions"`
+}
+
+// MentionNotificationResponse describes a mention of the current user, with the message it appears in.
+type MentionNotificationResponse struct {
+	ID        uint            `json:"id"`
+	Message   MessageResponse `json:"message"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// PaginatedMentionResponse defines the structure for a paginated list of mentions.
+type PaginatedMentionResponse struct {
+	Data []MentionNotificationResponse `json:"data"`
+	Meta PaginationMeta                `json:"meta"`
+}
+
+// MarkMentionsReadInput defines the mentions to mark as read.
+type MarkMentionsReadInput struct {
+	IDs []uint `json:"ids"` // Leave empty to mark all mentions as read
+}
+
+func newReplyPreviewResponse(message models.Message) *ReplyPreviewResponse {
+	if message.ReplyToID == nil {
+		return nil
+	}
+	if message.ReplyTo == nil {
+		return &ReplyPreviewResponse{ID: *message.ReplyToID, Deleted: true}
+	}
+
+	content := message.ReplyTo.Content
+	if utf8.RuneCountInString(content) > replyPreviewLength {
+		content = string([]rune(content)[:replyPreviewLength]) + "…"
+	}
+	return &ReplyPreviewResponse{
+		ID:       message.ReplyTo.ID,
+		UserID:   message.ReplyTo.UserID,
+		Nickname: message.ReplyTo.User.Nickname,
+		Content:  content,
+	}
+}
+
+func newMentionResponses(mentions []models.MessageMention) []MentionResponse {
+	response := []MentionResponse{}
+	for _, mention := range mentions {
+		response = append(response, MentionResponse{
+			UserID: mention.UserID,
+			Offset: mention.Offset,
+			Length: mention.Length,
+		})
+	}
+	return response
+}
+
+// newReactionResponses groups reactions by emoji, in the order each emoji was first used.
+func newReactionResponses(reactions []models.MessageReaction) []ReactionResponse {
+	response := []ReactionResponse{}
+	index := map[string]int{}
+	for _, reaction := range reactions {
+		i, ok := index[reaction.Emoji]
+		if !ok {
+			i = len(response)
+			index[reaction.Emoji] = i
+			response = append(response, ReactionResponse{Emoji: reaction.Emoji, UserIDs: []uint{}})
+		}
+		response[i].Count++
+		response[i].UserIDs = append(response[i].UserIDs, reaction.UserID)
+	}
+	return response
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// chatScope identifies the chat a request works on and what the current user may do in it.
+type chatScope struct {
+	LobbyID        *uint
+	GroupID        *uint
+	ConversationID *uint
+	UserID         uint
+	CanModerate    bool // Hosts, co-hosts, group owners and officers, and admins can remove any message
+}
+
+// lobbyChatScope resolves the chat of the current user's lobby.
+func lobbyChatScope(c *gin.Context) (chatScope, bool) {
+	userID, _ := c.Get("userID")
+
+	scope, err := loadLobbyChatScope(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+		return chatScope{}, false
+	}
+	return scope, true
+}
+
+// loadLobbyChatScope resolves the chat of the user's current lobby.
+func loadLobbyChatScope(userID uint) (chatScope, error) {
+	var user models.User
+	if err := database.DB.Preload("CurrentLobby").First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
+		return chatScope{}, errNotInLobby
+	}
+
+	return chatScope{
+		LobbyID:     user.CurrentLobbyID,
+		UserID:      user.ID,
+		CanModerate: canManageLobby(user.CurrentLobby, user) || user.Role == "admin",
+	}, nil
+}
+
+// groupChatScope resolves the chat of the group in the path.
+func groupChatScope(c *gin.Context) (chatScope, bool) {
+	group, member, ok := loadGroupAsMember(c)
+	if !ok {
+		return chatScope{}, false
+	}
+
+	var user models.User
+	database.DB.First(&user, member.UserID)
+
+	return chatScope{
+		GroupID:     &group.ID,
+		UserID:      member.UserID,
+		CanModerate: canManageGroup(member) || user.Role == "admin",
+	}, true
+}
+
+// messageChatScope returns the chat a message belongs to, as seen by the user.
+func messageChatScope(message models.Message, userID uint) chatScope {
+	return chatScope{
+		LobbyID:        message.LobbyID,
+		GroupID:        message.GroupID,
+		ConversationID: message.ConversationID,
+		UserID:         userID,
+	}
+}
+
+// scopeMessages restricts a query to the messages of the chat.
+func (scope chatScope) scopeMessages(db *gorm.DB) *gorm.DB {
+	if scope.LobbyID != nil {
+		return db.Where("lobby_id = ?", *scope.LobbyID)
+	}
+	if scope.ConversationID != nil {
+		return db.Where("conversation_id = ?", *scope.ConversationID)
+	}
+	return db.Where("group_id = ?", *scope.GroupID)
+}
+
+// publish delivers an event to everyone following the chat.
+func (scope chatScope) publish(event hub.Event) {
+	if scope.LobbyID != nil {
+		hub.GlobalHub.Broadcast(*scope.LobbyID, event)
+		return
+	}
+	if scope.ConversationID != nil {
+		sendToConversation(*scope.ConversationID, event)
+		return
+	}
+	sendToGroup(*scope.GroupID, event)
+}
+
+// findChatMessage loads the message from the path within the chat.
+func findChatMessage(c *gin.Context, scope chatScope) (*models.Message, bool) {
+	messageID, err := strconv.ParseUint(c.Param("messageID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+		return nil, false
+	}
+
+	var message models.Message
+	if err := preloadMessageDetails(scope.scopeMessages(database.DB)).First(&message, messageID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		return nil, false
+	}
+	return &message, true
+}
+
+// isOwnMessage reports whether the user wrote the message.
+func isOwnMessage(message *models.Message, userID uint) bool {
+	return message.UserID != nil && *message.UserID == userID
+}
+
+// preloadMessageDetails loads everything a MessageResponse shows besides the message itself.
+func preloadMessageDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").
+		Preload("ReplyTo.User").
+		Preload("Reactions", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Mentions", func(db *gorm.DB) *gorm.DB { return db.Order("\"offset\" ASC") })
+}
+
+// scopeParticipants restricts a user query to the users taking part in the chat.
+func (scope chatScope) scopeParticipants(db *gorm.DB) *gorm.DB {
+	if scope.LobbyID != nil {
+		return db.Where("current_lobby_id = ?", *scope.LobbyID)
+	}
+	if scope.ConversationID != nil {
+		return db.Where("id IN (SELECT user_id FROM conversation_members WHERE conversation_id = ?)", *scope.ConversationID)
+	}
+	return db.Where("id IN (SELECT user_id FROM group_members WHERE group_id = ?)", *scope.GroupID)
+}
+
+// mentionCandidate is an @nickname in message content, positioned in characters.
+type mentionCandidate struct {
+	Nickname string
+	Offset   int
+	Length   int // Including the @
+}
+
+// findMentionCandidates returns the @nickname mentions in the content.
+// Trailing dots and dashes are punctuation, as in "thanks @bob.", so they are not part of the nickname.
+func findMentionCandidates(content string) []mentionCandidate {
+	var candidates []mentionCandidate
+	for _, match := range mentionPattern.FindAllStringSubmatchIndex(content, -1) {
+		nickname := strings.TrimRight(content[match[2]:match[3]], ".-")
+		if nickname == "" {
+			continue
+		}
+		candidates = append(candidates, mentionCandidate{
+			Nickname: nickname,
+			Offset:   utf8.RuneCountInString(content[:match[0]]),
+			Length:   utf8.RuneCountInString(nickname) + 1,
+		})
+	}
+	return candidates
+}
+
+// parseMentions finds the @nickname mentions of chat participants in the content.
+// Offsets are counted in characters. Authors mentioning themselves are ignored.
+func parseMentions(scope chatScope, content string) []models.MessageMention {
+	candidates := findMentionCandidates(content)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	nicknames := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		nicknames = append(nicknames, candidate.Nickname)
+	}
+	var users []models.User
+	scope.scopeParticipants(database.DB).Where("nickname IN ?", nicknames).Find(&users)
+	userIDs := map[string]uint{}
+	for _, user := range users {
+		userIDs[user.Nickname] = user.ID
+	}
+
+	var mentions []models.MessageMention
+	for _, candidate := range candidates {
+		userID, ok := userIDs[candidate.Nickname]
+		if !ok || userID == scope.UserID {
+			continue
+		}
+		mentions = append(mentions, models.MessageMention{
+			UserID: userID,
+			Offset: candidate.Offset,
+			Length: candidate.Length,
+		})
+	}
+	return mentions
+}
+
+// notifyMentions sends a `mention` event to each mentioned user except those in skip.
+// Mentions are stored, so users who are not streaming find them in their mentions inbox.
+func notifyMentions(message models.Message, skip map[uint]bool) {
+	notified := map[uint]bool{}
+	for _, mention := range message.Mentions {
+		if skip[mention.UserID] || notified[mention.UserID] {
+			continue
+		}
+		notified[mention.UserID] = true
+		hub.GlobalHub.SendToUser(mention.UserID, hub.Event{
+			Type: EventMention,
+			Payload: MentionNotificationResponse{
+				ID:        mention.ID,
+				Message:   newMessageResponse(message),
+				CreatedAt: mention.CreatedAt,
+			},
+		})
+	}
+}
+
+// listChatMessages returns the chat history, oldest first.
+// With a `before` or `after` message ID it returns the window next to that message, otherwise a page counted from the latest message.
+func listChatMessages(c *gin.Context, scope chatScope) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 {
+		limit = 50
+	}
+
+	before, after := c.Query("before"), c.Query("after")
+	if before == "" && after == "" {
+		listChatMessagePage(c, scope, limit)
+		return
+	}
+
+	query := preloadMessageDetails(scope.scopeMessages(database.DB))
+	if before != "" {
+		cursor, err := strconv.ParseUint(before, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before cursor"})
+			return
+		}
+		query = query.Where("id < ?", cursor)
+	}
+	if after != "" {
+		cursor, err := strconv.ParseUint(after, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid after cursor"})
+			return
+		}
+		query = query.Where("id > ?", cursor)
+	}
+
+	// Walk away from the cursor and fetch one extra message to know whether more follow
+	order := "id DESC"
+	if before == "" {
+		order = "id ASC"
+	}
+	var messages []models.Message
+	if err := query.Order(order).Limit(limit + 1).Find(&messages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages"})
+		return
+	}
+	hasMore := len(messages) > limit
+	if hasMore {
+		messages = messages[:limit]
+	}
+	if before != "" {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+
+	response := MessageCursorResponse{
+		Data:   []MessageResponse{},
+		Meta:   MessageCursorMeta{HasMore: hasMore, PageSize: limit},
+		Pinned: scope.pinnedMessages(),
+	}
+	for _, msg := range messages {
+		response.Data = append(response.Data, newMessageResponse(msg))
+	}
+	if len(messages) > 0 {
+		response.Meta.Before = &messages[0].ID
+		response.Meta.After = &messages[len(messages)-1].ID
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// listChatMessagePage returns a page of the chat history counted from the latest message.
+func listChatMessagePage(c *gin.Context, scope chatScope, limit int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	query := scope.scopeMessages(database.DB.Model(&models.Message{}))
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count messages"})
+		return
+	}
+
+	var messages []models.Message
+	if err := preloadMessageDetails(query).
+		Order("created_at DESC"). // Latest messages first
+		Limit(limit).Offset(offset).
+		Find(&messages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages"})
+		return
+	}
+
+	// Reverse messages to show oldest first in the paginated set
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	var data []MessageResponse
+	for _, msg := range messages {
+		data = append(data, newMessageResponse(msg))
+	}
+
+	c.JSON(http.StatusOK, PaginatedMessageResponse{
+		Data:   data,
+		Meta:   NewPaginatedResponse(data, totalItems, page, limit).Meta,
+		Pinned: scope.pinnedMessages(),
+	})
+}
+
+// createChatMessage stores a message with its mentions in the chat, publishes it as eventType and notifies mentioned users.
+func createChatMessage(scope chatScope, input MessageInput, eventType hub.EventType) (models.Message, error) {
+	if input.ReplyToID != nil {
+		var parent models.Message
+		if err := scope.scopeMessages(database.DB).First(&parent, *input.ReplyToID).Error; err != nil {
+			return models.Message{}, errReplyNotFound
+		}
+	}
+	content, err := guardChatMessage(scope, input.Content)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	newMessage := models.Message{
+		LobbyID:        scope.LobbyID,
+		GroupID:        scope.GroupID,
+		ConversationID: scope.ConversationID,
+		UserID:         &scope.UserID, // User-sent message
+		Type:           models.MessageTypeText,
+		Content:        content,
+		ReplyToID:      input.ReplyToID,
+		Mentions:       parseMentions(scope, content),
+	}
+	if err := database.DB.Create(&newMessage).Error; err != nil {
+		return models.Message{}, err
+	}
+	if scope.ConversationID != nil {
+		touchConversation(*scope.ConversationID, newMessage)
+	}
+
+	preloadMessageDetails(database.DB).First(&newMessage, newMessage.ID)
+
+	scope.publish(hub.Event{
+		Type:    eventType,
+		Payload: newMessageResponse(newMessage),
+	})
+	notifyMentions(newMessage, nil)
+
+	return newMessage, nil
+}
+
+// postChatMessage posts the message from the request body to the chat and publishes it as eventType.
+func postChatMessage(c *gin.Context, scope chatScope, eventType hub.EventType) {
+	var input MessageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if scope.LobbyID != nil && isChatCommand(input.Content) {
+		response, err := runChatCommand(scope, input.Content)
+		var commandErr chatCommandError
+		switch {
+		case errors.As(err, &commandErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": commandErr.Error()})
+			return
+		case errors.Is(err, errNotLobbyManager):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the host or a co-host can use this command"})
+			return
+		case errors.Is(err, errNotInLobby):
+			c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+			return
+		case err != nil && respondChatGuardError(c, err):
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run command"})
+			return
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	if scope.LobbyID != nil {
+		input.Content = unescapeChatText(input.Content)
+	}
+
+	newMessage, err := createChatMessage(scope, input, eventType)
+	if errors.Is(err, errReplyNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Replied message not found in this chat"})
+		return
+	}
+	if err != nil && respondChatGuardError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post message"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newMessageResponse(newMessage))
+}
+
+// editChatMessage lets the author change a message within the edit window, keeping the previous content.
+func editChatMessage(c *gin.Context, scope chatScope) {
+	message, ok := findChatMessage(c, scope)
+	if !ok {
+		return
+	}
+	if !isOwnMessage(message, scope.UserID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only edit your own messages"})
+		return
+	}
+	if time.Since(message.CreatedAt) > messageEditWindow {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Messages can only be edited within 15 minutes"})
+		return
+	}
+
+	var input MessageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	content, err := filterChatContent(input.Content, chatLanguage(scope))
+	if err != nil {
+		respondChatGuardError(c, err)
+		return
+	}
+	input.Content = content
+	if input.Content == message.Content {
+		c.JSON(http.StatusOK, newMessageResponse(*message))
+		return
+	}
+
+	// Users mentioned before the edit keep their read state and are not notified again
+	previouslyMentioned := map[uint]bool{}
+	readAt := map[uint]*time.Time{}
+	for _, mention := range message.Mentions {
+		previouslyMentioned[mention.UserID] = true
+		readAt[mention.UserID] = mention.ReadAt
+	}
+	mentions := parseMentions(scope, input.Content)
+	for i := range mentions {
+		mentions[i].MessageID = message.ID
+		mentions[i].ReadAt = readAt[mentions[i].UserID]
+	}
+
+	now := time.Now()
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.MessageEdit{
+			MessageID:       message.ID,
+			PreviousContent: message.Content,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("message_id = ?", message.ID).Delete(&models.MessageMention{}).Error; err != nil {
+			return err
+		}
+		if len(mentions) > 0 {
+			if err := tx.Create(&mentions).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(message).Updates(map[string]interface{}{
+			"content":   input.Content,
+			"edited_at": now,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to edit message"})
+		return
+	}
+	message.Content = input.Content
+	message.EditedAt = &now
+	message.Mentions = mentions
+
+	scope.publish(hub.Event{
+		Type:    EventMessageUpdated,
+		Payload: newMessageResponse(*message),
+	})
+	notifyMentions(*message, previouslyMentioned)
+
+	c.JSON(http.StatusOK, newMessageResponse(*message))
+}
+
+// deleteChatMessage lets the author delete a message within the edit window and moderators remove any message.
+func deleteChatMessage(c *gin.Context, scope chatScope) {
+	message, ok := findChatMessage(c, scope)
+	if !ok {
+		return
+	}
+
+	ownWithinWindow := isOwnMessage(message, scope.UserID) && time.Since(message.CreatedAt) <= messageEditWindow
+	if !ownWithinWindow && !scope.CanModerate {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot delete this message"})
+		return
+	}
+
+	// Moderators removing someone else's message are recorded
+	var removedByID *uint
+	if !isOwnMessage(message, scope.UserID) {
+		removedByID = &scope.UserID
+	}
+	if err := deleteMessage(scope, message, removedByID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message deleted"})
+}
+
+// deleteMessage soft-deletes the message and tells the chat.
+// removedByID is the moderator who removed someone else's message, nil when authors delete their own.
+func deleteMessage(scope chatScope, message *models.Message, removedByID *uint) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if removedByID != nil {
+			if err := tx.Model(message).Update("removed_by_id", *removedByID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(message).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	scope.publish(hub.Event{
+		Type: EventMessageDeleted,
+		Payload: MessageDeletedPayload{
+			MessageID:      message.ID,
+			LobbyID:        message.LobbyID,
+			GroupID:        message.GroupID,
+			ConversationID: message.ConversationID,
+			Removed:        removedByID != nil,
+		},
+	})
+	return nil
+}
+
+// getChatMessageEdits lists the earlier versions of a message, oldest first.
+func getChatMessageEdits(c *gin.Context, scope chatScope) {
+	message, ok := findChatMessage(c, scope)
+	if !ok {
+		return
+	}
+
+	var edits []models.MessageEdit
+	if err := database.DB.Where("message_id = ?", message.ID).Order("created_at ASC").Find(&edits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve edit history"})
+		return
+	}
+
+	response := []MessageEditResponse{}
+	for _, edit := range edits {
+		response = append(response, MessageEditResponse{
+			PreviousContent: edit.PreviousContent,
+			EditedAt:        edit.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// publishReactions reloads the reactions of a message and publishes them to the chat.
+func publishReactions(c *gin.Context, scope chatScope, message *models.Message) {
+	var reactions []models.MessageReaction
+	database.DB.Where("message_id = ?", message.ID).Order("created_at ASC").Find(&reactions)
+
+	payload := ReactionsUpdatedPayload{
+		MessageID:      message.ID,
+		LobbyID:        message.LobbyID,
+		GroupID:        message.GroupID,
+		ConversationID: message.ConversationID,
+		Reactions:      newReactionResponses(reactions),
+	}
+	scope.publish(hub.Event{
+		Type:    EventMessageReactionsUpdated,
+		Payload: payload,
+	})
+
+	c.JSON(http.StatusOK, payload)
+}
+
+// addChatReaction adds an emoji reaction of the current user to a message.
+func addChatReaction(c *gin.Context, scope chatScope) {
+	message, ok := findChatMessage(c, scope)
+	if !ok {
+		return
+	}
+
+	var input ReactionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var emojis int64
+	database.DB.Model(&models.MessageReaction{}).
+		Where("message_id = ? AND emoji <> ?", message.ID, input.Emoji).
+		Distinct("emoji").Count(&emojis)
+	if emojis >= maxReactionEmojis {
+		c.JSON(http.StatusConflict, gin.H{"error": "Message has too many different reactions"})
+		return
+	}
+
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.MessageReaction{
+		MessageID: message.ID,
+		UserID:    scope.UserID,
+		Emoji:     input.Emoji,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reaction"})
+		return
+	}
+
+	publishReactions(c, scope, message)
+}
+
+// removeChatReaction removes an emoji reaction of the current user from a message.
+func removeChatReaction(c *gin.Context, scope chatScope) {
+	message, ok := findChatMessage(c, scope)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Where("message_id = ? AND user_id = ? AND emoji = ?", message.ID, scope.UserID, c.Param("emoji")).
+		Delete(&models.MessageReaction{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove reaction"})
+		return
+	}
+
+	publishReactions(c, scope, message)
+}
+
+// endregion
+
+// region --- Lobby Chat Handlers ---
+
+// EditLobbyMessage godoc
+// @Summary      Edit my lobby chat message
+// @Description  Changes the content of one of the current user's messages within 15 minutes of posting. The previous content is kept in the edit history and a `message_updated` event is broadcast.
+// @Tags         lobbies-chat
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        messageID path int          true "Message ID"
+// @Param        input     body MessageInput true "New content"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Not the author or edit window has passed"
+// @Failure      404 {object} ErrorResponse "User is not in a lobby or message not found"
+// @Router       /lobbies/me/messages/{messageID} [put]
+func EditLobbyMessage(c *gin.Context) {
+	if scope, ok := lobbyChatScope(c); ok {
+		editChatMessage(c, scope)
+	}
+}
+
+// DeleteLobbyMessage godoc
+// @Summary      Delete a lobby chat message
+// @Description  Deletes one of the current user's messages within 15 minutes of posting. The host, co-hosts and admins can remove any message. A `message_deleted` event is broadcast.
+// @Tags         lobbies-chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        messageID path int true "Message ID"
+// @Success      200 {object} map[string]string "{"message": "Message deleted"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "User is not in a lobby or message not found"
+// @Router       /lobbies/me/messages/{messageID} [delete]
+func DeleteLobbyMessage(c *gin.Context) {
+	if scope, ok := lobbyChatScope(c); ok {
+		deleteChatMessage(c, scope)
+	}
+}
+
+// GetLobbyMessageEdits godoc
+// @Summary      Get the edit history of a lobby chat message
+// @Description  Lists the earlier versions of an edited message, oldest first.
+// @Tags         lobbies-chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        messageID path int true "Message ID"
+// @Success      200 {array}  MessageEditResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "User is not in a lobby or message not found"
+// @Router       /lobbies/me/messages/{messageID}/edits [get]
+func GetLobbyMessageEdits(c *gin.Context) {
+	if scope, ok := lobbyChatScope(c); ok {
+		getChatMessageEdits(c, scope)
+	}
+}
+
+// AddLobbyMessageReaction godoc
+// @Summary      React to a lobby chat message
+// @Description  Adds an emoji reaction of the current user to a message. A `message_reactions_updated` event is broadcast. A message can collect up to 20 different emojis.
+// @Tags         lobbies-chat
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        messageID path int           true "Message ID"
+// @Param        input     body ReactionInput true "Emoji"
+// @Success      200 {object} ReactionsUpdatedPayload
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "User is not in a lobby or message not found"
+// @Failure      409 {object} ErrorResponse "Too many different reactions"
+// @Router       /lobbies/me/messages/{messageID}/reactions [post]
+func AddLobbyMessageReaction(c *gin.Context) {
+	if scope, ok := lobbyChatScope(c); ok {
+		addChatReaction(c, scope)
+	}
+}
+
+// RemoveLobbyMessageReaction godoc
+// @Summary      Remove my reaction from a lobby chat message
+// @Description  Removes an emoji reaction of the current user from a message. A `message_reactions_updated` event is broadcast.
+// @Tags         lobbies-chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        messageID path int    true "Message ID"
+// @Param        emoji     path string true "Emoji (URL-encoded)"
+// @Success      200 {object} ReactionsUpdatedPayload
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "User is not in a lobby or message not found"
+// @Router       /lobbies/me/messages/{messageID}/reactions/{emoji} [delete]
+func RemoveLobbyMessageReaction(c *gin.Context) {
+	if scope, ok := lobbyChatScope(c); ok {
+		removeChatReaction(c, scope)
+	}
+}
+
+// endregion
+
+// region --- Group Chat Handlers ---
+
+// EditGroupMessage godoc
+// @Summary      Edit my group chat message
+// @Description  Changes the content of one of the current user's group messages within 15 minutes of posting. Members receive a `message_updated` event.
+// @Tags         groups-chat
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path int          true "Group ID"
+// @Param        messageID path int          true "Message ID"
+// @Param        input     body MessageInput true "New content"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Not the author or edit window has passed"
+// @Failure      404 {object} ErrorResponse "Group or message not found"
+// @Router       /groups/{id}/messages/{messageID} [put]
+func EditGroupMessage(c *gin.Context) {
+	if scope, ok := groupChatScope(c); ok {
+		editChatMessage(c, scope)
+	}
+}
+
+// DeleteGroupMessage godoc
+// @Summary      Delete a group chat message
+// @Description  Deletes one of the current user's group messages within 15 minutes of posting. The owner, officers and admins can remove any message. Members receive a `message_deleted` event.
+// @Tags         groups-chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path int true "Group ID"
+// @Param        messageID path int true "Message ID"
+// @Success      200 {object} map[string]string "{"message": "Message deleted"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group or message not found"
+// @Router       /groups/{id}/messages/{messageID} [delete]
+func DeleteGroupMessage(c *gin.Context) {
+	if scope, ok := groupChatScope(c); ok {
+		deleteChatMessage(c, scope)
+	}
+}
+
+// GetGroupMessageEdits godoc
+// @Summary      Get the edit history of a group chat message
+// @Description  Lists the earlier versions of an edited group message, oldest first.
+// @Tags         groups-chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path int true "Group ID"
+// @Param        messageID path int true "Message ID"
+// @Success      200 {array}  MessageEditResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group or message not found"
+// @Router       /groups/{id}/messages/{messageID}/edits [get]
+func GetGroupMessageEdits(c *gin.Context) {
+	if scope, ok := groupChatScope(c); ok {
+		getChatMessageEdits(c, scope)
+	}
+}
+
+// AddGroupMessageReaction godoc
+// @Summary      React to a group chat message
+// @Description  Adds an emoji reaction of the current user to a group message. Members receive a `message_reactions_updated` event. A message can collect up to 20 different emojis.
+// @Tags         groups-chat
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path int           true "Group ID"
+// @Param        messageID path int           true "Message ID"
+// @Param        input     body ReactionInput true "Emoji"
+// @Success      200 {object} ReactionsUpdatedPayload
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group or message not found"
+// @Failure      409 {object} ErrorResponse "Too many different reactions"
+// @Router       /groups/{id}/messages/{messageID}/reactions [post]
+func AddGroupMessageReaction(c *gin.Context) {
+	if scope, ok := groupChatScope(c); ok {
+		addChatReaction(c, scope)
+	}
+}
+
+// RemoveGroupMessageReaction godoc
+// @Summary      Remove my reaction from a group chat message
+// @Description  Removes an emoji reaction of the current user from a group message. Members receive a `message_reactions_updated` event.
+// @Tags         groups-chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path int    true "Group ID"
+// @Param        messageID path int    true "Message ID"
+// @Param        emoji     path string true "Emoji (URL-encoded)"
+// @Success      200 {object} ReactionsUpdatedPayload
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "Group or message not found"
+// @Router       /groups/{id}/messages/{messageID}/reactions/{emoji} [delete]
+func RemoveGroupMessageReaction(c *gin.Context) {
+	if scope, ok := groupChatScope(c); ok {
+		removeChatReaction(c, scope)
+	}
+}
+
+// endregion
+
+// region --- Mention Handlers ---
+
+// GetMyMentions godoc
+// @Summary      Get my mentions
+// @Description  Lists the messages that mention the current user, newest first. Mentions are kept while the user is offline, so they can be caught up on here.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page        query int  false "Page number" default(1)
+// @Param        limit       query int  false "Items per page" default(20)
+// @Param        unread_only query bool false "Only list unread mentions"
+// @Success      200 {object} PaginatedMentionResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/mentions [get]
+func GetMyMentions(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	// Mentions in deleted messages are hidden
+	query := database.DB.Model(&models.MessageMention{}).
+		Where("user_id = ?", userID).
+		Where("message_id IN (SELECT id FROM messages WHERE deleted_at IS NULL)")
+	if c.Query("unread_only") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count mentions"})
+		return
+	}
+
+	var mentions []models.MessageMention
+	if err := query.Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&mentions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve mentions"})
+		return
+	}
+
+	messageIDs := make([]uint, 0, len(mentions))
+	for _, mention := range mentions {
+		messageIDs = append(messageIDs, mention.MessageID)
+	}
+	var messages []models.Message
+	preloadMessageDetails(database.DB).Where("id IN ?", messageIDs).Find(&messages)
+	messagesByID := map[uint]models.Message{}
+	for _, message := range messages {
+		messagesByID[message.ID] = message
+	}
+
+	response := []MentionNotificationResponse{}
+	for _, mention := range mentions {
+		response = append(response, MentionNotificationResponse{
+			ID:        mention.ID,
+			Message:   newMessageResponse(messagesByID[mention.MessageID]),
+			ReadAt:    mention.ReadAt,
+			CreatedAt: mention.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, NewPaginatedResponse(response, totalItems, page, limit))
+}
+
+// MarkMentionsRead godoc
+// @Summary      Mark my mentions as read
+// @Description  Marks the given mentions of the current user as read, or all of them when no IDs are given.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body MarkMentionsReadInput false "Mention IDs"
+// @Success      200 {object} map[string]string "{"message": "Mentions marked as read"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/mentions/read [post]
+func MarkMentionsRead(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input MarkMentionsReadInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	query := database.DB.Model(&models.MessageMention{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(input.IDs) > 0 {
+		query = query.Where("id IN ?", input.IDs)
+	}
+	if err := query.Update("read_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark mentions as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Mentions marked as read"})
+}
+
+// endregion
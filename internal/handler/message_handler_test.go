@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindMentionCandidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []mentionCandidate
+	}{
+		{
+			name:    "no mentions",
+			content: "hello everyone",
+			want:    nil,
+		},
+		{
+			name:    "single mention",
+			content: "hi @bob",
+			want:    []mentionCandidate{{Nickname: "bob", Offset: 3, Length: 4}},
+		},
+		{
+			name:    "trailing dot ends the sentence",
+			content: "thanks @bob.",
+			want:    []mentionCandidate{{Nickname: "bob", Offset: 7, Length: 4}},
+		},
+		{
+			name:    "trailing dash",
+			content: "@bob- are you there",
+			want:    []mentionCandidate{{Nickname: "bob", Offset: 0, Length: 4}},
+		},
+		{
+			name:    "trailing ellipsis",
+			content: "waiting for @bob...",
+			want:    []mentionCandidate{{Nickname: "bob", Offset: 12, Length: 4}},
+		},
+		{
+			name:    "inner dots and dashes are kept",
+			content: "@mr.bob-smith, ready?",
+			want:    []mentionCandidate{{Nickname: "mr.bob-smith", Offset: 0, Length: 13}},
+		},
+		{
+			name:    "offsets count characters",
+			content: "привет @bob и @alice.",
+			want: []mentionCandidate{
+				{Nickname: "bob", Offset: 7, Length: 4},
+				{Nickname: "alice", Offset: 14, Length: 6},
+			},
+		},
+		{
+			name:    "punctuation only",
+			content: "@... and @-",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findMentionCandidates(tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findMentionCandidates(%q) = %+v, want %+v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// region --- DTOs ---
+
+// NotificationResponse describes an entry of the notification inbox.
+type NotificationResponse struct {
+	ID        uint                    `json:"id"`
+	Type      models.NotificationType `json:"type"`
+	Payload   json.RawMessage         `json:"payload" swaggertype:"object"`
+	Read      bool                    `json:"read"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+// NotificationInboxResponse defines the structure for a paginated notification inbox.
+type NotificationInboxResponse struct {
+	Data        []NotificationResponse `json:"data"`
+	Meta        PaginationMeta         `json:"meta"`
+	UnreadCount int64                  `json:"unread_count"`
+}
+
+// MarkNotificationsReadInput defines the notifications to mark as read.
+type MarkNotificationsReadInput struct {
+	IDs []uint `json:"ids"` // Leave empty to mark all notifications as read
+}
+
+// LobbyKickNotification is the payload of a lobby_kick notification.
+type LobbyKickNotification struct {
+	LobbyID  uint               `json:"lobby_id"`
+	KickedBy PublicUserResponse `json:"kicked_by"`
+	Banned   bool               `json:"banned"`
+	Reason   string             `json:"reason,omitempty"`
+}
+
+func newNotificationResponse(notification models.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Payload:   json.RawMessage(notification.Payload),
+		Read:      notification.ReadAt != nil,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// notifyUser stores a notification in the user's inbox and pushes it as a `notification` event,
+// so users who are not streaming see it the next time they open the inbox.
+func notifyUser(userID uint, notificationType models.NotificationType, payload interface{}) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("notifications: failed to encode %s payload: %v", notificationType, err)
+		return
+	}
+
+	notification := models.Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		Payload: string(encoded),
+	}
+	if err := database.DB.Create(&notification).Error; err != nil {
+		log.Printf("notifications: failed to store %s notification: %v", notificationType, err)
+		return
+	}
+
+	hub.GlobalHub.SendToUser(userID, hub.Event{
+		Type:    EventNotification,
+		Payload: newNotificationResponse(notification),
+	})
+}
+
+// endregion
+
+// region --- Notification Handlers ---
+
+// GetMyNotifications godoc
+// @Summary      Get my notifications
+// @Description  Lists the current user's notifications, newest first, with the number of unread ones. New notifications are also pushed as `notification` events on `/users/me/events`.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page        query int  false "Page number" default(1)
+// @Param        limit       query int  false "Items per page" default(20)
+// @Param        unread_only query bool false "Only list unread notifications"
+// @Success      200 {object} NotificationInboxResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/notifications [get]
+func GetMyNotifications(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	var unreadCount int64
+	if err := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&unreadCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+
+	query := database.DB.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if c.Query("unread_only") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC, id DESC").
+		Limit(limit).Offset(offset).
+		Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifications"})
+		return
+	}
+
+	response := []NotificationResponse{}
+	for _, notification := range notifications {
+		response = append(response, newNotificationResponse(notification))
+	}
+
+	paginated := NewPaginatedResponse(response, totalItems, page, limit)
+	c.JSON(http.StatusOK, NotificationInboxResponse{
+		Data:        paginated.Data,
+		Meta:        paginated.Meta,
+		UnreadCount: unreadCount,
+	})
+}
+
+// MarkNotificationsRead godoc
+// @Summary      Mark my notifications as read
+// @Description  Marks the given notifications of the current user as read, or all of them when no IDs are given.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body MarkNotificationsReadInput false "Notification IDs"
+// @Success      200 {object} map[string]string "{"message": "Notifications marked as read"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/notifications/read [post]
+func MarkNotificationsRead(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input MarkNotificationsReadInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	query := database.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(input.IDs) > 0 {
+		query = query.Where("id IN ?", input.IDs)
+	}
+	if err := query.Update("read_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notifications marked as read"})
+}
+
+// endregion
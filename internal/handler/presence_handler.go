@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	presenceTouchInterval   = 30 * time.Second // Activity timestamps are written at most this often
+	presenceOfflineAfter    = 2 * time.Minute  // Without requests or stream heartbeats for this long a user is offline
+	presenceAwayAfter       = 5 * time.Minute  // Without requests or commands for this long a user is away
+	presenceRefreshInterval = 30 * time.Second
+)
+
+// region --- DTOs ---
+
+// PresenceResponse describes what a user is doing right now.
+type PresenceResponse struct {
+	Status     models.PresenceStatus `json:"status" example:"online"`
+	LastSeenAt *time.Time            `json:"last_seen_at,omitempty"`
+}
+
+// PresenceChangedPayload is sent to friends when a user's presence changes.
+type PresenceChangedPayload struct {
+	UserID uint `json:"user_id"`
+	PresenceResponse
+}
+
+// PresenceVisibilityInput defines who can see the current user's presence.
+type PresenceVisibilityInput struct {
+	Visibility models.PresenceVisibility `json:"visibility" binding:"required,oneof=everyone friends nobody"`
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// presenceStatusOf derives the user's status from their activity timestamps and lobby.
+func presenceStatusOf(user models.User, now time.Time) models.PresenceStatus {
+	lobbyReady := false
+	if user.CurrentLobbyID != nil {
+		var notReady int64
+		database.DB.Model(&models.User{}).
+			Where("current_lobby_id = ? AND lobby_ready = ?", *user.CurrentLobbyID, false).
+			Count(&notReady)
+		lobbyReady = notReady == 0
+	}
+	return derivePresenceStatus(user, now, lobbyReady)
+}
+
+// currentPresenceStatus derives the user's status without querying the database, for user responses.
+// Whether the user's lobby is ready is taken from the status last recorded by refreshPresence,
+// which runs for the whole lobby whenever its members or their readiness change.
+func currentPresenceStatus(user models.User, now time.Time) models.PresenceStatus {
+	return derivePresenceStatus(user, now, user.PresenceStatus == models.PresenceInGame)
+}
+
+// derivePresenceStatus derives the user's status from their activity timestamps and whether everyone in their lobby is ready.
+func derivePresenceStatus(user models.User, now time.Time, lobbyReady bool) models.PresenceStatus {
+	if user.LastSeenAt == nil || now.Sub(*user.LastSeenAt) > presenceOfflineAfter {
+		return models.PresenceOffline
+	}
+	if user.CurrentLobbyID != nil {
+		if lobbyReady {
+			return models.PresenceInGame
+		}
+		return models.PresenceInLobby
+	}
+	if user.LastActiveAt == nil || now.Sub(*user.LastActiveAt) > presenceAwayAfter {
+		return models.PresenceAway
+	}
+	return models.PresenceOnline
+}
+
+// canSeePresence reports whether the viewer, who may be a friend of the user, may see the user's presence.
+// Users always see their own.
+func canSeePresence(user models.User, viewerID uint, friends bool) bool {
+	if viewerID == user.ID {
+		return true
+	}
+	switch user.PresenceVisibility {
+	case models.PresenceVisibleEveryone:
+		return true
+	case models.PresenceVisibleFriends:
+		return viewerID != 0 && friends
+	}
+	return false
+}
+
+// buildPresenceResponse returns the user's presence, or nil when the viewer may not see it.
+// It runs for every user in lists, so it must not query the database.
+func buildPresenceResponse(user models.User, viewerID uint, friends bool) *PresenceResponse {
+	if !canSeePresence(user, viewerID, friends) {
+		return nil
+	}
+	return &PresenceResponse{
+		Status:     currentPresenceStatus(user, time.Now()),
+		LastSeenAt: user.LastSeenAt,
+	}
+}
+
+// friendIDs returns the IDs of the user's accepted friends.
+func friendIDs(userID uint) []uint {
+	var relations []models.UserRelation
+	database.DB.Where("(from_user_id = ? OR to_user_id = ?) AND status = ?", userID, userID, models.StatusAccepted).
+		Find(&relations)
+
+	ids := make([]uint, 0, len(relations))
+	for _, relation := range relations {
+		if relation.FromUserID == userID {
+			ids = append(ids, relation.ToUserID)
+		} else {
+			ids = append(ids, relation.FromUserID)
+		}
+	}
+	return ids
+}
+
+// sendPresenceToFriends pushes a presence_changed event to the user's friends.
+func sendPresenceToFriends(userID uint, presence PresenceResponse) {
+	event := hub.Event{
+		Type:    EventPresenceChanged,
+		Payload: PresenceChangedPayload{UserID: userID, PresenceResponse: presence},
+	}
+	for _, friendID := range friendIDs(userID) {
+		hub.GlobalHub.SendToUser(friendID, event)
+	}
+}
+
+// refreshPresence recomputes the user's status and announces it to their friends when it changed.
+func refreshPresence(userID uint) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return
+	}
+
+	status := presenceStatusOf(user, time.Now())
+	if status == user.PresenceStatus {
+		return
+	}
+
+	// Only the instance that records the change announces it
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND presence_status = ?", user.ID, user.PresenceStatus).
+		Update("presence_status", status)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return
+	}
+
+	if user.PresenceVisibility != models.PresenceVisibleNobody {
+		sendPresenceToFriends(user.ID, PresenceResponse{Status: status, LastSeenAt: user.LastSeenAt})
+	}
+}
+
+// refreshLobbyPresence refreshes the presence of every member of a lobby, whose in-game status depends on each other.
+func refreshLobbyPresence(lobbyID uint) {
+	var memberIDs []uint
+	database.DB.Model(&models.User{}).Where("current_lobby_id = ?", lobbyID).Pluck("id", &memberIDs)
+	for _, memberID := range memberIDs {
+		refreshPresence(memberID)
+	}
+}
+
+// touchPresence records that the user was seen, and was active if they made a request or sent a command.
+// Timestamps are written at most every presenceTouchInterval.
+func touchPresence(userID uint, active bool) {
+	now := time.Now()
+	column := "last_seen_at"
+	columns := map[string]interface{}{"last_seen_at": now}
+	if active {
+		column = "last_active_at"
+		columns["last_active_at"] = now
+	}
+
+	result := database.DB.Model(&models.User{}).
+		Where("id = ?", userID).
+		Where(column+" IS NULL OR "+column+" < ?", now.Add(-presenceTouchInterval)).
+		Updates(columns)
+	if result.Error == nil && result.RowsAffected > 0 {
+		refreshPresence(userID)
+	}
+}
+
+// TrackActivity is a middleware that records the activity of authenticated users.
+// Event streams only keep users online through their heartbeats, they do not make them active.
+func TrackActivity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		userID := c.GetUint("userID")
+		if userID == 0 || c.IsWebsocket() || c.Writer.Header().Get("Content-Type") == "text/event-stream" {
+			return
+		}
+		touchPresence(userID, true)
+	}
+}
+
+// StartPresenceWorker periodically moves users who stopped sending requests and heartbeats to away and offline.
+func StartPresenceWorker() {
+	go func() {
+		ticker := time.NewTicker(presenceRefreshInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			var userIDs []uint
+			if err := database.DB.Model(&models.User{}).
+				Where("presence_status <> ?", models.PresenceOffline).
+				Pluck("id", &userIDs).Error; err != nil {
+				log.Printf("presence: failed to load present users: %v", err)
+				continue
+			}
+			for _, userID := range userIDs {
+				refreshPresence(userID)
+			}
+		}
+	}()
+}
+
+// endregion
+
+// region --- Presence Handlers ---
+
+// UpdatePresenceVisibility godoc
+// @Summary      Set who can see my presence
+// @Description  Sets whether everyone (`everyone`), only friends (`friends`, the default) or nobody (`nobody`) can see the current user's online status and last seen time. Friends are sent `presence_changed` events only while they can see it; hiding the presence announces the user as offline.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body PresenceVisibilityInput true "Presence visibility"
+// @Success      200 {object} PrivateUserResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "User not found"
+// @Router       /users/me/presence-visibility [put]
+func UpdatePresenceVisibility(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var input PresenceVisibilityInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	wasHidden := user.PresenceVisibility == models.PresenceVisibleNobody
+	if err := database.DB.Model(&user).Update("presence_visibility", input.Visibility).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update presence visibility"})
+		return
+	}
+
+	// Friends saw the user go offline when the presence was hidden, and see them return when it is shown again
+	isHidden := input.Visibility == models.PresenceVisibleNobody
+	if isHidden && !wasHidden {
+		sendPresenceToFriends(user.ID, PresenceResponse{Status: models.PresenceOffline})
+	} else if wasHidden && !isHidden {
+		sendPresenceToFriends(user.ID, PresenceResponse{Status: presenceStatusOf(user, time.Now()), LastSeenAt: user.LastSeenAt})
+	}
+
+	c.JSON(http.StatusOK, buildPrivateUserResponse(user))
+}
+
+// endregion
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"playmatch/backend/internal/models"
+	"testing"
+	"time"
+)
+
+func TestCurrentPresenceStatus(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+	lobbyID := uint(3)
+
+	tests := []struct {
+		name string
+		user models.User
+		want models.PresenceStatus
+	}{
+		{
+			name: "never seen",
+			user: models.User{},
+			want: models.PresenceOffline,
+		},
+		{
+			name: "seen too long ago",
+			user: models.User{LastSeenAt: ago(presenceOfflineAfter + time.Second), LastActiveAt: ago(time.Minute)},
+			want: models.PresenceOffline,
+		},
+		{
+			name: "streaming but idle",
+			user: models.User{LastSeenAt: ago(time.Second), LastActiveAt: ago(presenceAwayAfter + time.Second)},
+			want: models.PresenceAway,
+		},
+		{
+			name: "active",
+			user: models.User{LastSeenAt: ago(time.Second), LastActiveAt: ago(time.Minute)},
+			want: models.PresenceOnline,
+		},
+		{
+			name: "in a lobby",
+			user: models.User{LastSeenAt: ago(time.Second), CurrentLobbyID: &lobbyID, PresenceStatus: models.PresenceOnline},
+			want: models.PresenceInLobby,
+		},
+		{
+			name: "in a ready lobby",
+			user: models.User{LastSeenAt: ago(time.Second), CurrentLobbyID: &lobbyID, PresenceStatus: models.PresenceInGame},
+			want: models.PresenceInGame,
+		},
+		{
+			name: "left a ready lobby",
+			user: models.User{LastSeenAt: ago(time.Second), LastActiveAt: ago(time.Second), PresenceStatus: models.PresenceInGame},
+			want: models.PresenceOnline,
+		},
+		{
+			name: "offline in a ready lobby",
+			user: models.User{LastSeenAt: ago(time.Hour), CurrentLobbyID: &lobbyID, PresenceStatus: models.PresenceInGame},
+			want: models.PresenceOffline,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := currentPresenceStatus(tt.user, now); got != tt.want {
+				t.Errorf("currentPresenceStatus = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublicUserCurrentLobby(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility models.PresenceVisibility
+		friends    bool
+		anonymous  bool
+		want       bool
+	}{
+		{name: "visible to everyone", visibility: models.PresenceVisibleEveryone, want: true},
+		{name: "visible to everyone, anonymous viewer", visibility: models.PresenceVisibleEveryone, anonymous: true, want: true},
+		{name: "friends only, friend", visibility: models.PresenceVisibleFriends, friends: true, want: true},
+		{name: "friends only, stranger", visibility: models.PresenceVisibleFriends},
+		{name: "friends only, anonymous viewer", visibility: models.PresenceVisibleFriends, anonymous: true},
+		{name: "hidden from friends", visibility: models.PresenceVisibleNobody, friends: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+
+			user := createTestUser(t, db, "player")
+			viewer := createTestUser(t, db, "viewer")
+			createTestLobby(t, db, models.Lobby{MaxPlayers: 4}, user)
+			if err := db.Model(&user).Update("presence_visibility", tt.visibility).Error; err != nil {
+				t.Fatalf("set visibility: %v", err)
+			}
+			if tt.friends {
+				relation := models.UserRelation{FromUserID: viewer.ID, ToUserID: user.ID, Status: models.StatusAccepted}
+				if err := db.Create(&relation).Error; err != nil {
+					t.Fatalf("create friendship: %v", err)
+				}
+			}
+			db.First(&user, user.ID)
+
+			viewerID := viewer.ID
+			if tt.anonymous {
+				viewerID = 0
+			}
+			response := buildPublicUserResponse(user, viewerID)
+			if got := response.CurrentLobbyID != nil; got != tt.want {
+				t.Errorf("current lobby shown = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
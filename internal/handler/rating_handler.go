@@ -0,0 +1,462 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// region --- DTOs ---
+
+// RatingScaleInput defines the structure for creating or updating a rating scale.
+type RatingScaleInput struct {
+	Name        string                 `json:"name" binding:"required,max=100" example:"Aim"`
+	Description string                 `json:"description"`
+	Kind        models.RatingScaleKind `json:"kind" binding:"required,oneof=hard soft" example:"hard"`
+	TagID       *uint                  `json:"tag_id"` // Optional, limits the scale to games with this tag
+}
+
+// RatingScaleResponse describes a rating scale.
+type RatingScaleResponse struct {
+	ID          uint                   `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Kind        models.RatingScaleKind `json:"kind"`
+	Tag         *TagResponse           `json:"tag,omitempty"`
+}
+
+// RateUserInput defines the score given to a user on a scale.
+type RateUserInput struct {
+	Score int `json:"score" binding:"required,min=1,max=5" example:"4"`
+}
+
+// UserRatingResponse describes a rating the current user gave.
+type UserRatingResponse struct {
+	RateeID   uint      `json:"ratee_id"`
+	ScaleID   uint      `json:"scale_id"`
+	Score     int       `json:"score"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RatingBreakdownResponse describes how a user is rated on one scale.
+type RatingBreakdownResponse struct {
+	Scale   RatingScaleResponse `json:"scale"`
+	Average float64             `json:"average"`
+	Count   int64               `json:"count"`
+	MyScore *int                `json:"my_score,omitempty"` // Set when the viewer rated the user on this scale
+}
+
+func newRatingScaleResponse(scale models.RatingScale) RatingScaleResponse {
+	response := RatingScaleResponse{
+		ID:          scale.ID,
+		Name:        scale.Name,
+		Description: scale.Description,
+		Kind:        scale.Kind,
+	}
+	if scale.Tag != nil {
+		tag := newTagResponse(*scale.Tag)
+		response.Tag = &tag
+	}
+	return response
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// userRatingAggregates returns the average hard-skill and soft-skill scores of a user and how many ratings they received.
+// Averages are nil while the user has no ratings of that kind.
+func userRatingAggregates(userID uint) (hard, soft *float64, count int64) {
+	type aggregateRow struct {
+		Kind    models.RatingScaleKind
+		Average float64
+		Count   int64
+	}
+
+	var rows []aggregateRow
+	database.DB.Table("user_ratings").
+		Select("rating_scales.kind AS kind, AVG(user_ratings.score) AS average, COUNT(*) AS count").
+		Joins("JOIN rating_scales ON rating_scales.id = user_ratings.scale_id AND rating_scales.deleted_at IS NULL").
+		Where("user_ratings.ratee_id = ?", userID).
+		Group("rating_scales.kind").
+		Scan(&rows)
+
+	for _, row := range rows {
+		average := row.Average
+		switch row.Kind {
+		case models.RatingScaleHard:
+			hard = &average
+		case models.RatingScaleSoft:
+			soft = &average
+		}
+		count += row.Count
+	}
+	return hard, soft, count
+}
+
+// sharedSessionGameIDs returns the games of the lobby sessions in which both users were present at the same time.
+func sharedSessionGameIDs(userID, otherID uint) []uint {
+	var gameIDs []uint
+	now := time.Now()
+	database.DB.Table("lobby_session_participants AS mine").
+		Distinct("lobby_sessions.game_id").
+		Joins("JOIN lobby_session_participants AS other ON other.session_id = mine.session_id").
+		Joins("JOIN lobby_sessions ON lobby_sessions.id = mine.session_id").
+		Where("mine.user_id = ? AND other.user_id = ?", userID, otherID).
+		Where("mine.joined_at < COALESCE(other.left_at, ?) AND other.joined_at < COALESCE(mine.left_at, ?)", now, now).
+		Pluck("lobby_sessions.game_id", &gameIDs)
+	return gameIDs
+}
+
+// canRateOnScale reports whether a rater who shared the given games with someone may rate them on the scale.
+// Tag-scoped scales need a shared game carrying the tag.
+func canRateOnScale(scale models.RatingScale, sharedGameIDs []uint) bool {
+	if len(sharedGameIDs) == 0 {
+		return false
+	}
+	if scale.TagID == nil {
+		return true
+	}
+
+	var count int64
+	database.DB.Table("game_tags").Where("tag_id = ? AND game_id IN ?", *scale.TagID, sharedGameIDs).Count(&count)
+	return count > 0
+}
+
+// findRatingScale loads the scale from the given path parameter.
+func findRatingScale(c *gin.Context, param string) (*models.RatingScale, bool) {
+	scaleID, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scale ID"})
+		return nil, false
+	}
+
+	var scale models.RatingScale
+	if err := database.DB.Preload("Tag").First(&scale, scaleID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rating scale not found"})
+		return nil, false
+	}
+	return &scale, true
+}
+
+// tagExists reports whether a tag with the given ID exists.
+func tagExists(tagID uint) bool {
+	var count int64
+	database.DB.Model(&models.Tag{}).Where("id = ?", tagID).Count(&count)
+	return count > 0
+}
+
+// endregion
+
+// region --- Rating Scale Handlers ---
+
+// GetRatingScales godoc
+// @Summary      Get rating scales
+// @Description  Lists the rating scales. With `game_id`, only the scales that apply to that game are returned: untagged scales and scales tagged with one of the game's tags.
+// @Tags         ratings
+// @Produce      json
+// @Param        game_id query int false "Only scales applying to this game"
+// @Success      200 {array} RatingScaleResponse
+// @Router       /rating-scales [get]
+func GetRatingScales(c *gin.Context) {
+	query := database.DB.Preload("Tag")
+	if gameID := c.Query("game_id"); gameID != "" {
+		query = query.Where("tag_id IS NULL OR tag_id IN (?)",
+			database.DB.Table("game_tags").Select("tag_id").Where("game_id = ?", gameID))
+	}
+
+	var scales []models.RatingScale
+	if err := query.Order("kind ASC, name ASC").Find(&scales).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rating scales"})
+		return
+	}
+
+	response := []RatingScaleResponse{}
+	for _, scale := range scales {
+		response = append(response, newRatingScaleResponse(scale))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateRatingScale godoc
+// @Summary      Create a rating scale
+// @Description  Creates a hard-skill or soft-skill rating scale, optionally limited to games with a tag.
+// @Tags         admin-ratings
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body RatingScaleInput true "Scale Info"
+// @Success      201 {object} RatingScaleResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Tag not found"
+// @Failure      409 {object} ErrorResponse "Rating scale already exists"
+// @Router       /admin/rating-scales [post]
+func CreateRatingScale(c *gin.Context) {
+	var input RatingScaleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.TagID != nil && !tagExists(*input.TagID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+
+	scale := models.RatingScale{
+		Name:        input.Name,
+		Description: input.Description,
+		Kind:        input.Kind,
+		TagID:       input.TagID,
+	}
+	if err := database.DB.Create(&scale).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Rating scale already exists or another error occurred"})
+		return
+	}
+
+	database.DB.Preload("Tag").First(&scale, scale.ID)
+	c.JSON(http.StatusCreated, newRatingScaleResponse(scale))
+}
+
+// UpdateRatingScale godoc
+// @Summary      Update a rating scale
+// @Description  Updates an existing rating scale. Ratings already given on it are kept.
+// @Tags         admin-ratings
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int              true "Scale ID"
+// @Param        input body RatingScaleInput true "Scale Info"
+// @Success      200 {object} RatingScaleResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Rating scale or tag not found"
+// @Router       /admin/rating-scales/{id} [put]
+func UpdateRatingScale(c *gin.Context) {
+	scale, ok := findRatingScale(c, "id")
+	if !ok {
+		return
+	}
+
+	var input RatingScaleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.TagID != nil && !tagExists(*input.TagID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+
+	scale.Name = input.Name
+	scale.Description = input.Description
+	scale.Kind = input.Kind
+	scale.TagID = input.TagID
+	if err := database.DB.Omit("Tag").Save(scale).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Rating scale already exists or another error occurred"})
+		return
+	}
+
+	database.DB.Preload("Tag").First(scale, scale.ID)
+	c.JSON(http.StatusOK, newRatingScaleResponse(*scale))
+}
+
+// DeleteRatingScale godoc
+// @Summary      Delete a rating scale
+// @Description  Deletes a rating scale. Its ratings no longer count towards users' scores.
+// @Tags         admin-ratings
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Scale ID"
+// @Success      200 {object} map[string]string "{"message": "Rating scale deleted"}"
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Rating scale not found"
+// @Router       /admin/rating-scales/{id} [delete]
+func DeleteRatingScale(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	result := database.DB.Delete(&models.RatingScale{}, id)
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rating scale not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rating scale deleted"})
+}
+
+// endregion
+
+// region --- User Rating Handlers ---
+
+// RateUser godoc
+// @Summary      Rate a user on a scale
+// @Description  Gives or changes the current user's score for another user on a scale. Users can only rate people they shared a lobby with; tag-scoped scales additionally need a shared game with that tag.
+// @Tags         ratings
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path int           true "User ID"
+// @Param        scaleID path int           true "Scale ID"
+// @Param        input   body RateUserInput true "Score"
+// @Success      200 {object} UserRatingResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse "Users did not play together"
+// @Failure      404 {object} ErrorResponse "User or rating scale not found"
+// @Router       /users/{id}/ratings/{scaleID} [put]
+func RateUser(c *gin.Context) {
+	raterID, _ := c.Get("userID")
+
+	rateeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if uint(rateeID) == raterID.(uint) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot rate yourself"})
+		return
+	}
+
+	var ratee models.User
+	if err := database.DB.First(&ratee, rateeID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	scale, ok := findRatingScale(c, "scaleID")
+	if !ok {
+		return
+	}
+
+	var input RateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !canRateOnScale(*scale, sharedSessionGameIDs(raterID.(uint), ratee.ID)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only rate users you played with on this scale"})
+		return
+	}
+
+	rating := models.UserRating{
+		RaterID: raterID.(uint),
+		RateeID: ratee.ID,
+		ScaleID: scale.ID,
+		Score:   input.Score,
+	}
+	if err := database.DB.Omit("Rater", "Ratee", "Scale").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "rater_id"}, {Name: "ratee_id"}, {Name: "scale_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"score", "updated_at"}),
+	}).Create(&rating).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rating"})
+		return
+	}
+	if rating.Score >= reputationPositiveScore {
+		if err := creditPositiveRating(rating.RaterID, rating.RateeID); err != nil {
+			log.Printf("reputation: failed to credit positive rating: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, UserRatingResponse{
+		RateeID:   rating.RateeID,
+		ScaleID:   rating.ScaleID,
+		Score:     rating.Score,
+		UpdatedAt: rating.UpdatedAt,
+	})
+}
+
+// GetUserRatings godoc
+// @Summary      Get a user's rating breakdown
+// @Description  Returns the average score and number of ratings a user received on each scale, with the viewer's own score when present.
+// @Tags         ratings
+// @Produce      json
+// @Param        id path int true "User ID"
+// @Success      200 {array}  RatingBreakdownResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "User not found"
+// @Router       /users/{id}/ratings [get]
+func GetUserRatings(c *gin.Context) {
+	viewerIDRaw, viewerOk := c.Get("userID")
+
+	rateeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var ratee models.User
+	if err := database.DB.First(&ratee, rateeID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	type breakdownRow struct {
+		ScaleID uint
+		Average float64
+		Count   int64
+	}
+
+	var rows []breakdownRow
+	if err := database.DB.Model(&models.UserRating{}).
+		Select("scale_id, AVG(score) AS average, COUNT(*) AS count").
+		Where("ratee_id = ?", ratee.ID).
+		Group("scale_id").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ratings"})
+		return
+	}
+
+	scaleIDs := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		scaleIDs = append(scaleIDs, row.ScaleID)
+	}
+	var scales []models.RatingScale
+	if len(scaleIDs) > 0 {
+		database.DB.Preload("Tag").Find(&scales, scaleIDs)
+	}
+	scalesByID := make(map[uint]models.RatingScale, len(scales))
+	for _, scale := range scales {
+		scalesByID[scale.ID] = scale
+	}
+
+	myScores := make(map[uint]int)
+	if viewerOk {
+		var myRatings []models.UserRating
+		database.DB.Where("rater_id = ? AND ratee_id = ?", viewerIDRaw, ratee.ID).Find(&myRatings)
+		for _, rating := range myRatings {
+			myScores[rating.ScaleID] = rating.Score
+		}
+	}
+
+	response := []RatingBreakdownResponse{}
+	for _, row := range rows {
+		scale, ok := scalesByID[row.ScaleID]
+		if !ok {
+			continue // Deleted scale
+		}
+		breakdown := RatingBreakdownResponse{
+			Scale:   newRatingScaleResponse(scale),
+			Average: row.Average,
+			Count:   row.Count,
+		}
+		if score, rated := myScores[scale.ID]; rated {
+			breakdown.MyScore = &score
+		}
+		response = append(response, breakdown)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// endregion
@@ -18,7 +18,7 @@ import (
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id        path      int     true   "Target User ID"
-// @Param        status    query     string  false  "Filter by status (pending, accepted)"
+// @Param        status    query     string  false  "Filter by status (pending, accepted, blocked)"
 // @Param        direction query     string  false  "Filter by direction (incoming, outgoing)"
 // @Success      200       {array}   PublicUserResponse
 // @Failure      400       {object}  ErrorResponse
@@ -52,6 +52,8 @@ func GetUserRelationsByID(c *gin.Context) {
 	if statusFilter != "" {
 		query = query.Where("status = ?", statusFilter)
 	}
+	// Blocks are private to the user who made them
+	query = query.Where("status <> ?", models.StatusBlocked)
 
 	// Preload the user data we need
 	if directionFilter == "incoming" {
@@ -91,7 +93,7 @@ func GetUserRelationsByID(c *gin.Context) {
 // @Tags         friendship
 // @Produce      json
 // @Security     BearerAuth
-// @Param        status    query     string  false  "Filter by status (pending, accepted)"
+// @Param        status    query     string  false  "Filter by status (pending, accepted, blocked)"
 // @Param        direction query     string  false  "Filter by direction (incoming, outgoing)"
 // @Success      200       {array}   PublicUserResponse
 // @Failure      400       {object}  ErrorResponse
@@ -118,6 +120,8 @@ func GetRelations(c *gin.Context) {
 	if statusFilter != "" {
 		query = query.Where("status = ?", statusFilter)
 	}
+	// Users see whom they blocked, but not who blocked them
+	query = query.Where("status <> ? OR from_user_id = ?", models.StatusBlocked, viewerID)
 
 	// Preload the user data we need
 	if directionFilter == "incoming" {
@@ -157,7 +161,7 @@ func GetRelations(c *gin.Context) {
 
 // SendRequest godoc
 // @Summary      Send friend request
-// @Description  Sends a friend request to another user (subscribes).
+// @Description  Sends a friend request to another user (subscribes). The target receives a `friend_request` notification.
 // @Tags         friendship
 // @Produce      json
 // @Security     BearerAuth
@@ -166,6 +170,7 @@ func GetRelations(c *gin.Context) {
 // @Failure      400  {object}  ErrorResponse
 // @Failure      401  {object}  ErrorResponse
 // @Failure      404  {object}  ErrorResponse "Target user not found"
+// @Failure      403  {object}  ErrorResponse "One of the users blocked the other"
 // @Failure      409  {object}  ErrorResponse "Relation already exists"
 // @Failure      500  {object}  ErrorResponse
 // @Router       /users/{id}/request [post]
@@ -184,6 +189,11 @@ func SendRequest(c *gin.Context) {
 		return
 	}
 
+	if isBlocked(database.DB, viewerID.(uint), uint(targetUserID)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot send request to this user"})
+		return
+	}
+
 	// Check if relation already exists
 	var existingRelation models.UserRelation
 	err = database.DB.Where("from_user_id = ? AND to_user_id = ?", viewerID, targetUserID).First(&existingRelation).Error
@@ -204,12 +214,16 @@ func SendRequest(c *gin.Context) {
 		return
 	}
 
+	var requester models.User
+	database.DB.First(&requester, viewerID)
+	notifyUser(newRelation.ToUserID, models.NotificationFriendRequest, buildPublicUserResponse(requester, newRelation.ToUserID))
+
 	c.JSON(http.StatusCreated, gin.H{"message": "Request sent successfully"})
 }
 
 // AcceptRequest godoc
 // @Summary      Accept friend request
-// @Description  Accepts a pending friend request from another user.
+// @Description  Accepts a pending friend request from another user. The requester receives a `friend_accepted` notification.
 // @Tags         friendship
 // @Produce      json
 // @Security     BearerAuth
@@ -244,6 +258,10 @@ func AcceptRequest(c *gin.Context) {
 		return
 	}
 
+	var accepter models.User
+	database.DB.First(&accepter, viewerID)
+	notifyUser(request.FromUserID, models.NotificationFriendAccepted, buildPublicUserResponse(accepter, request.FromUserID))
+
 	c.JSON(http.StatusOK, gin.H{"message": "Request accepted"})
 }
 
@@ -320,3 +338,90 @@ func RemoveRelation(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Relation removed"})
 }
+
+// BlockUser godoc
+// @Summary      Block user
+// @Description  Blocks another user. Any friendship or request between the two is removed, and neither can message the other or send friend requests until the block is lifted.
+// @Tags         friendship
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Target User ID"
+// @Success      200  {object}  map[string]string "{"message": "User blocked"}"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse "Target user not found"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /users/{id}/block [post]
+func BlockUser(c *gin.Context) {
+	viewerID, _ := c.Get("userID")
+	targetUserID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target user ID"})
+		return
+	}
+
+	if viewerID.(uint) == uint(targetUserID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot block yourself"})
+		return
+	}
+
+	var targetUser models.User
+	if err := database.DB.First(&targetUser, targetUserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Target user not found"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// The block replaces our own relation; a block the other user made stays in place
+		if err := tx.Where("from_user_id = ? AND to_user_id = ?", viewerID, targetUser.ID).
+			Or("from_user_id = ? AND to_user_id = ? AND status <> ?", targetUser.ID, viewerID, models.StatusBlocked).
+			Delete(&models.UserRelation{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UserRelation{
+			FromUserID: viewerID.(uint),
+			ToUserID:   targetUser.ID,
+			Status:     models.StatusBlocked,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User blocked"})
+}
+
+// UnblockUser godoc
+// @Summary      Unblock user
+// @Description  Lifts a block the current user placed on another user. The previous friendship is not restored.
+// @Tags         friendship
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Target User ID"
+// @Success      200  {object}  map[string]string "{"message": "User unblocked"}"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse "Block not found"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /users/{id}/unblock [post]
+func UnblockUser(c *gin.Context) {
+	viewerID, _ := c.Get("userID")
+	targetUserID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target user ID"})
+		return
+	}
+
+	result := database.DB.Where("from_user_id = ? AND to_user_id = ? AND status = ?", viewerID, targetUserID, models.StatusBlocked).Delete(&models.UserRelation{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock user"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Block not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unblocked"})
+}
@@ -0,0 +1,672 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reportContextSize is how many chat messages before and after a reported message are kept in its snapshot.
+const reportContextSize = 10
+
+// region --- DTOs ---
+
+// CreateReportInput defines a report against a user, a chat message or a lobby.
+type CreateReportInput struct {
+	TargetType models.ReportTarget `json:"target_type" binding:"required,oneof=user message lobby" example:"message"`
+	TargetID   uint                `json:"target_id" binding:"required" example:"42"` // ID of the user, message or lobby
+	Reason     models.ReportReason `json:"reason" binding:"required,oneof=toxicity harassment spam offensive_content cheating griefing no_show other" example:"harassment"`
+	Comment    string              `json:"comment" binding:"max=1000"`
+}
+
+// AssignReportInput defines the moderator who takes a report.
+type AssignReportInput struct {
+	AssigneeID *uint `json:"assignee_id"` // Leave empty to take the report yourself
+}
+
+// ResolveReportInput defines the outcome of a report and the actions to take.
+type ResolveReportInput struct {
+	Resolution       string `json:"resolution" binding:"max=1000" example:"Insults in lobby chat"`
+	RemoveMessage    bool   `json:"remove_message"`                                    // Remove the reported message, only for message reports
+	Ban              bool   `json:"ban"`                                               // Ban the reported user from the platform
+	BanDurationHours int    `json:"ban_duration_hours" binding:"min=0" example:"72"`   // 0 bans permanently
+	BanReason        string `json:"ban_reason" binding:"max=500" example:"Harassment"` // Shown to the banned user, defaults to the resolution
+}
+
+// DismissReportInput defines why a report was dismissed.
+type DismissReportInput struct {
+	Resolution string `json:"resolution" binding:"max=1000" example:"No violation found"`
+}
+
+// ReportUserResponse identifies a user involved in a report.
+type ReportUserResponse struct {
+	ID       uint   `json:"id"`
+	Nickname string `json:"nickname"`
+}
+
+// ReportSnapshot is the reported content as it was when the report was filed.
+type ReportSnapshot struct {
+	User    ReportUserResponse `json:"user"`
+	Message *MessageResponse   `json:"message,omitempty"` // The reported message
+	Context []MessageResponse  `json:"context,omitempty"` // Messages around the reported one, oldest first
+	Lobby   *LobbyResponse     `json:"lobby,omitempty"`   // The reported lobby
+}
+
+// ReportResponse describes a report filed by the current user.
+type ReportResponse struct {
+	ID             uint                `json:"id"`
+	TargetType     models.ReportTarget `json:"target_type"`
+	ReportedUserID uint                `json:"reported_user_id"`
+	MessageID      *uint               `json:"message_id,omitempty"`
+	LobbyID        *uint               `json:"lobby_id,omitempty"`
+	SessionID      *uint               `json:"session_id,omitempty"`
+	Reason         models.ReportReason `json:"reason"`
+	Comment        string              `json:"comment,omitempty"`
+	Status         models.ReportStatus `json:"status"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+// AdminReportResponse describes a report in the moderation queue.
+type AdminReportResponse struct {
+	ReportResponse
+	Reporter     ReportUserResponse  `json:"reporter"`
+	ReportedUser ReportUserResponse  `json:"reported_user"`
+	Banned       bool                `json:"banned"` // Whether the reported user is currently banned
+	Assignee     *ReportUserResponse `json:"assignee,omitempty"`
+	Snapshot     *ReportSnapshot     `json:"snapshot,omitempty"`
+	Resolution   string              `json:"resolution,omitempty"`
+	ResolvedByID *uint               `json:"resolved_by_id,omitempty"`
+	ResolvedAt   *time.Time          `json:"resolved_at,omitempty"`
+}
+
+// PaginatedReportResponse defines the structure for a paginated list of reports.
+type PaginatedReportResponse struct {
+	Data []AdminReportResponse `json:"data"`
+	Meta PaginationMeta        `json:"meta"`
+}
+
+// ReportClosedNotification is the payload of a report_closed notification.
+type ReportClosedNotification struct {
+	ReportID uint                `json:"report_id"`
+	Status   models.ReportStatus `json:"status"`
+}
+
+func newReportUserResponse(user models.User) ReportUserResponse {
+	return ReportUserResponse{ID: user.ID, Nickname: user.Nickname}
+}
+
+func newReportResponse(report models.Report) ReportResponse {
+	return ReportResponse{
+		ID:             report.ID,
+		TargetType:     report.TargetType,
+		ReportedUserID: report.ReportedUserID,
+		MessageID:      report.MessageID,
+		LobbyID:        report.LobbyID,
+		SessionID:      report.SessionID,
+		Reason:         report.Reason,
+		Comment:        report.Comment,
+		Status:         report.Status,
+		CreatedAt:      report.CreatedAt,
+	}
+}
+
+func newAdminReportResponse(report models.Report) AdminReportResponse {
+	response := AdminReportResponse{
+		ReportResponse: newReportResponse(report),
+		Reporter:       newReportUserResponse(report.Reporter),
+		ReportedUser:   newReportUserResponse(report.ReportedUser),
+		Banned:         report.ReportedUser.IsBanned(time.Now()),
+		Resolution:     report.Resolution,
+		ResolvedByID:   report.ResolvedByID,
+		ResolvedAt:     report.ResolvedAt,
+	}
+	if report.Assignee != nil {
+		assignee := newReportUserResponse(*report.Assignee)
+		response.Assignee = &assignee
+	}
+	if report.Snapshot != "" {
+		var snapshot ReportSnapshot
+		if err := json.Unmarshal([]byte(report.Snapshot), &snapshot); err == nil {
+			response.Snapshot = &snapshot
+		}
+	}
+	return response
+}
+
+// endregion
+
+// region --- Helpers ---
+
+var (
+	errReportClosed        = errors.New("report is already closed")
+	errNotMessageReport    = errors.New("only message reports can remove a message")
+	errReportedMessageGone = errors.New("reported message was already deleted")
+)
+
+// encodeReportSnapshot serializes a snapshot for storage.
+func encodeReportSnapshot(snapshot ReportSnapshot) string {
+	encoded, _ := json.Marshal(snapshot)
+	return string(encoded)
+}
+
+// canSeeMessage reports whether the user is a participant of the chat the message belongs to.
+func canSeeMessage(userID uint, message models.Message) bool {
+	switch {
+	case message.LobbyID != nil:
+		var count int64
+		database.DB.Model(&models.User{}).Where("id = ? AND current_lobby_id = ?", userID, *message.LobbyID).Count(&count)
+		return count > 0
+	case message.GroupID != nil:
+		_, ok := findGroupMember(database.DB, *message.GroupID, userID)
+		return ok
+	case message.ConversationID != nil:
+		var count int64
+		database.DB.Model(&models.Conversation{}).
+			Where("id = ? AND (user_low_id = ? OR user_high_id = ?)", *message.ConversationID, userID, userID).
+			Count(&count)
+		return count > 0
+	}
+	return false
+}
+
+// messageReportSnapshot captures the reported message with the chat messages around it.
+func messageReportSnapshot(message models.Message, userID uint) ReportSnapshot {
+	scope := messageChatScope(message, userID)
+
+	var before, after []models.Message
+	preloadMessageDetails(scope.scopeMessages(database.DB)).
+		Where("id < ?", message.ID).Order("id DESC").Limit(reportContextSize).Find(&before)
+	preloadMessageDetails(scope.scopeMessages(database.DB)).
+		Where("id > ?", message.ID).Order("id ASC").Limit(reportContextSize).Find(&after)
+
+	context := make([]MessageResponse, 0, len(before)+len(after))
+	for i := len(before) - 1; i >= 0; i-- {
+		context = append(context, newMessageResponse(before[i]))
+	}
+	for _, msg := range after {
+		context = append(context, newMessageResponse(msg))
+	}
+
+	messageResponse := newMessageResponse(message)
+	return ReportSnapshot{
+		User:    newReportUserResponse(message.User),
+		Message: &messageResponse,
+		Context: context,
+	}
+}
+
+// loadReport loads the report from the path with the users involved.
+func loadReport(c *gin.Context) (*models.Report, bool) {
+	reportID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return nil, false
+	}
+
+	var report models.Report
+	if err := database.DB.Preload("Reporter").Preload("ReportedUser").Preload("Assignee").
+		First(&report, reportID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return nil, false
+	}
+	return &report, true
+}
+
+// isReportClosed reports whether the report was already resolved or dismissed.
+func isReportClosed(report models.Report) bool {
+	return report.Status == models.ReportStatusResolved || report.Status == models.ReportStatusDismissed
+}
+
+// closeReport records the moderator's decision and tells the reporter that the report was handled.
+func closeReport(report *models.Report, moderatorID uint, status models.ReportStatus, resolution string) error {
+	now := time.Now()
+	result := database.DB.Model(&models.Report{}).
+		Where("id = ? AND status IN ?", report.ID, []models.ReportStatus{models.ReportStatusOpen, models.ReportStatusInReview}).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"resolution":     resolution,
+			"resolved_by_id": moderatorID,
+			"resolved_at":    now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errReportClosed // Closed by another moderator in the meantime
+	}
+
+	report.Status = status
+	report.Resolution = resolution
+	report.ResolvedByID = &moderatorID
+	report.ResolvedAt = &now
+
+	notifyUser(report.ReporterID, models.NotificationReportClosed, ReportClosedNotification{
+		ReportID: report.ID,
+		Status:   status,
+	})
+	return nil
+}
+
+// removeReportedMessage removes the message of a message report on behalf of the moderator.
+func removeReportedMessage(report models.Report, moderatorID uint) error {
+	if report.MessageID == nil {
+		return errNotMessageReport
+	}
+
+	var message models.Message
+	if err := database.DB.First(&message, *report.MessageID).Error; err != nil {
+		return errReportedMessageGone
+	}
+	return deleteMessage(messageChatScope(message, moderatorID), &message, &moderatorID)
+}
+
+// banUser bans the user from the platform, taking them out of their lobby and matchmaking.
+// A duration of 0 bans permanently.
+func banUser(userID uint, duration time.Duration, reason string) error {
+	var user models.User
+	if err := database.DB.Preload("CurrentLobby").First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var until *time.Time
+	if duration > 0 {
+		bannedUntil := now.Add(duration)
+		until = &bannedUntil
+	}
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"banned_at":    now,
+		"banned_until": until,
+		"ban_reason":   reason,
+	}).Error; err != nil {
+		return err
+	}
+
+	leaveMatchmaking(user.ID)
+	leaveWaitlists(user.ID)
+	if user.CurrentLobbyID != nil {
+		return leaveLobby(user)
+	}
+	return nil
+}
+
+// endregion
+
+// region --- Report Handlers ---
+
+// CreateReport godoc
+// @Summary      Report a user, message or lobby
+// @Description  Reports a user, a chat message or a lobby to the moderators. The report keeps a snapshot of the reported content: the user's nickname, the message with up to 10 chat messages before and after it, or the lobby with its members. Messages can only be reported by participants of their chat. A user can have one pending report per target.
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        input body CreateReportInput true "Report"
+// @Success      201 {object} ReportResponse
+// @Failure      400 {object} ErrorResponse "Invalid input or reporting yourself"
+// @Failure      404 {object} ErrorResponse "User, message or lobby not found"
+// @Failure      409 {object} ErrorResponse "Already reported"
+// @Router       /reports [post]
+func CreateReport(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input CreateReportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report := models.Report{
+		ReporterID: userID,
+		TargetType: input.TargetType,
+		Reason:     input.Reason,
+		Comment:    input.Comment,
+		Status:     models.ReportStatusOpen,
+	}
+	pending := database.DB.Model(&models.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND status IN ?", userID, input.TargetType,
+			[]models.ReportStatus{models.ReportStatusOpen, models.ReportStatusInReview})
+
+	switch input.TargetType {
+	case models.ReportTargetUser:
+		var reported models.User
+		if err := database.DB.First(&reported, input.TargetID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		report.ReportedUserID = reported.ID
+		report.Snapshot = encodeReportSnapshot(ReportSnapshot{User: newReportUserResponse(reported)})
+		pending = pending.Where("reported_user_id = ?", reported.ID)
+
+	case models.ReportTargetMessage:
+		var message models.Message
+		if err := preloadMessageDetails(database.DB).First(&message, input.TargetID).Error; err != nil ||
+			message.UserID == nil || !canSeeMessage(userID, message) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		}
+		report.ReportedUserID = *message.UserID
+		report.MessageID = &message.ID
+		report.Snapshot = encodeReportSnapshot(messageReportSnapshot(message, userID))
+		pending = pending.Where("message_id = ?", message.ID)
+
+	case models.ReportTargetLobby:
+		var lobby models.Lobby
+		if err := database.DB.Preload("Game").Preload("Host").Preload("Members").First(&lobby, input.TargetID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Lobby not found"})
+			return
+		}
+		lobbyResponse := newLobbyResponse(lobby)
+		report.ReportedUserID = lobby.HostID
+		report.LobbyID = &lobby.ID
+		report.Snapshot = encodeReportSnapshot(ReportSnapshot{User: newReportUserResponse(lobby.Host), Lobby: &lobbyResponse})
+		pending = pending.Where("lobby_id = ?", lobby.ID)
+	}
+
+	if report.ReportedUserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot report yourself"})
+		return
+	}
+
+	var count int64
+	pending.Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You already reported this"})
+		return
+	}
+
+	if err := database.DB.Omit("Reporter", "ReportedUser", "Assignee").Create(&report).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit report"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newReportResponse(report))
+}
+
+// endregion
+
+// region --- Admin Report Handlers ---
+
+// GetReports godoc
+// @Summary      List reports
+// @Description  Lists the moderation queue, oldest reports first. Without a status filter only pending reports (`open` and `in_review`) are listed.
+// @Tags         admin-reports
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status           query string false "Filter by status" Enums(open, in_review, resolved, dismissed)
+// @Param        target_type      query string false "Filter by target type" Enums(user, message, lobby)
+// @Param        assignee         query string false "Filter by assignee ID, `me` for the current moderator or `none` for unassigned reports"
+// @Param        reported_user_id query int    false "Filter by reported user"
+// @Param        page             query int    false "Page number" default(1)
+// @Param        limit            query int    false "Items per page" default(20)
+// @Success      200 {object} PaginatedReportResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Router       /admin/reports [get]
+func GetReports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := database.DB.Model(&models.Report{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	} else {
+		query = query.Where("status IN ?", []models.ReportStatus{models.ReportStatusOpen, models.ReportStatusInReview})
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	switch assignee := c.Query("assignee"); assignee {
+	case "":
+	case "me":
+		query = query.Where("assignee_id = ?", c.GetUint("userID"))
+	case "none":
+		query = query.Where("assignee_id IS NULL")
+	default:
+		query = query.Where("assignee_id = ?", assignee)
+	}
+	if reportedUserID := c.Query("reported_user_id"); reportedUserID != "" {
+		query = query.Where("reported_user_id = ?", reportedUserID)
+	}
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count reports"})
+		return
+	}
+
+	var reports []models.Report
+	if err := query.Preload("Reporter").Preload("ReportedUser").Preload("Assignee").
+		Order("created_at ASC").Limit(limit).Offset((page - 1) * limit).
+		Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reports"})
+		return
+	}
+
+	response := make([]AdminReportResponse, 0, len(reports))
+	for _, report := range reports {
+		response = append(response, newAdminReportResponse(report))
+	}
+	c.JSON(http.StatusOK, NewPaginatedResponse(response, totalItems, page, limit))
+}
+
+// GetReport godoc
+// @Summary      Get a report
+// @Description  Returns a report with the snapshot of the reported content.
+// @Tags         admin-reports
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Report ID"
+// @Success      200 {object} AdminReportResponse
+// @Failure      404 {object} ErrorResponse "Report not found"
+// @Router       /admin/reports/{id} [get]
+func GetReport(c *gin.Context) {
+	report, ok := loadReport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newAdminReportResponse(*report))
+}
+
+// AssignReport godoc
+// @Summary      Assign a report
+// @Description  Assigns a pending report to a moderator, the current one by default, and moves it to `in_review`.
+// @Tags         admin-reports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int               true "Report ID"
+// @Param        input body AssignReportInput false "Assignee"
+// @Success      200 {object} AdminReportResponse
+// @Failure      400 {object} ErrorResponse "Assignee is not a moderator"
+// @Failure      404 {object} ErrorResponse "Report not found"
+// @Failure      409 {object} ErrorResponse "Report is already closed"
+// @Router       /admin/reports/{id}/assign [post]
+func AssignReport(c *gin.Context) {
+	report, ok := loadReport(c)
+	if !ok {
+		return
+	}
+
+	var input AssignReportInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	assigneeID := c.GetUint("userID")
+	if input.AssigneeID != nil {
+		assigneeID = *input.AssigneeID
+	}
+
+	var assignee models.User
+	if err := database.DB.First(&assignee, assigneeID).Error; err != nil || assignee.Role != "admin" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Assignee is not a moderator"})
+		return
+	}
+	if isReportClosed(*report) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Report is already closed"})
+		return
+	}
+
+	if err := database.DB.Model(report).Updates(map[string]interface{}{
+		"assignee_id": assignee.ID,
+		"status":      models.ReportStatusInReview,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign report"})
+		return
+	}
+	report.AssigneeID = &assignee.ID
+	report.Assignee = &assignee
+	report.Status = models.ReportStatusInReview
+
+	c.JSON(http.StatusOK, newAdminReportResponse(*report))
+}
+
+// ResolveReport godoc
+// @Summary      Resolve a report
+// @Description  Closes a report as `resolved`, optionally removing the reported message and banning the reported user. A banned user is taken out of their lobby and matchmaking and cannot log in or use the API until the ban ends. The reporter is notified with a `report_closed` notification.
+// @Tags         admin-reports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int                true "Report ID"
+// @Param        input body ResolveReportInput true "Resolution"
+// @Success      200 {object} AdminReportResponse
+// @Failure      400 {object} ErrorResponse "Only message reports can remove a message"
+// @Failure      404 {object} ErrorResponse "Report not found"
+// @Failure      409 {object} ErrorResponse "Report is already closed"
+// @Router       /admin/reports/{id}/resolve [post]
+func ResolveReport(c *gin.Context) {
+	moderatorID := c.GetUint("userID")
+
+	report, ok := loadReport(c)
+	if !ok {
+		return
+	}
+
+	var input ResolveReportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if isReportClosed(*report) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Report is already closed"})
+		return
+	}
+	if input.RemoveMessage && report.MessageID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only message reports can remove a message"})
+		return
+	}
+
+	// The actions run before the report is closed, so a failed action leaves it pending
+	// and the reporter is only notified once the actions took effect
+	if input.RemoveMessage {
+		// An already deleted message needs no removal
+		if err := removeReportedMessage(*report, moderatorID); err != nil && !errors.Is(err, errReportedMessageGone) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove message"})
+			return
+		}
+	}
+	if input.Ban {
+		reason := input.BanReason
+		if reason == "" {
+			reason = input.Resolution
+		}
+		if err := banUser(report.ReportedUserID, time.Duration(input.BanDurationHours)*time.Hour, reason); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to ban user"})
+			return
+		}
+		database.DB.First(&report.ReportedUser, report.ReportedUserID)
+	}
+
+	if err := closeReport(report, moderatorID, models.ReportStatusResolved, input.Resolution); err != nil {
+		if errors.Is(err, errReportClosed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Report is already closed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newAdminReportResponse(*report))
+}
+
+// DismissReport godoc
+// @Summary      Dismiss a report
+// @Description  Closes a report as `dismissed` without taking action. The reporter is notified with a `report_closed` notification.
+// @Tags         admin-reports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path int                true  "Report ID"
+// @Param        input body DismissReportInput false "Reason"
+// @Success      200 {object} AdminReportResponse
+// @Failure      404 {object} ErrorResponse "Report not found"
+// @Failure      409 {object} ErrorResponse "Report is already closed"
+// @Router       /admin/reports/{id}/dismiss [post]
+func DismissReport(c *gin.Context) {
+	report, ok := loadReport(c)
+	if !ok {
+		return
+	}
+
+	var input DismissReportInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := closeReport(report, c.GetUint("userID"), models.ReportStatusDismissed, input.Resolution); err != nil {
+		if errors.Is(err, errReportClosed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Report is already closed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dismiss report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newAdminReportResponse(*report))
+}
+
+// LiftUserBan godoc
+// @Summary      Lift a user's ban
+// @Description  Lets a banned user log in and use the API again.
+// @Tags         admin-reports
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Success      204 "No Content"
+// @Failure      404 {object} ErrorResponse "User is not banned"
+// @Router       /admin/users/{id}/ban [delete]
+func LiftUserBan(c *gin.Context) {
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND banned_at IS NOT NULL", c.Param("id")).
+		Updates(map[string]interface{}{"banned_at": nil, "banned_until": nil, "ban_reason": ""})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lift ban"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not banned"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// endregion
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"playmatch/backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// postReport calls CreateReport as the reporter and returns the response.
+func postReport(t *testing.T, reporterID uint, input CreateReportInput) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, _ := json.Marshal(input)
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/reports", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", reporterID)
+
+	CreateReport(c)
+	return w
+}
+
+func TestCreateReportMessageSnapshot(t *testing.T) {
+	db := testDB(t)
+
+	author := createTestUser(t, db, "author")
+	reporter := createTestUser(t, db, "reporter")
+	lobby := createTestLobby(t, db, models.Lobby{MaxPlayers: 4}, author, reporter)
+
+	// The reported message has more than reportContextSize messages on each side
+	var messages []models.Message
+	for i := 0; i < 2*reportContextSize+5; i++ {
+		message := models.Message{LobbyID: &lobby.ID, UserID: &author.ID, Content: fmt.Sprintf("message %d", i)}
+		if err := db.Create(&message).Error; err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+		messages = append(messages, message)
+	}
+	target := messages[reportContextSize+2]
+
+	w := postReport(t, reporter.ID, CreateReportInput{TargetType: models.ReportTargetMessage, TargetID: target.ID, Reason: models.ReportReasonSpam})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+
+	var report models.Report
+	if err := db.Where("reporter_id = ?", reporter.ID).First(&report).Error; err != nil {
+		t.Fatalf("load report: %v", err)
+	}
+	var snapshot ReportSnapshot
+	if err := json.Unmarshal([]byte(report.Snapshot), &snapshot); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+
+	if report.ReportedUserID != author.ID || snapshot.User.Nickname != author.Nickname {
+		t.Errorf("reported user = %d %q, want the author", report.ReportedUserID, snapshot.User.Nickname)
+	}
+	if snapshot.Message == nil || snapshot.Message.ID != target.ID {
+		t.Fatalf("snapshot message = %+v, want message %d", snapshot.Message, target.ID)
+	}
+	var want, got []uint
+	for _, message := range messages[2 : 2*reportContextSize+3] {
+		if message.ID != target.ID {
+			want = append(want, message.ID)
+		}
+	}
+	for _, message := range snapshot.Context {
+		got = append(got, message.ID)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("context = %v, want %v", got, want)
+	}
+}
+
+func TestCreateReportPendingDuplicate(t *testing.T) {
+	db := testDB(t)
+
+	reporter := createTestUser(t, db, "reporter")
+	reported := createTestUser(t, db, "reported")
+	input := CreateReportInput{TargetType: models.ReportTargetUser, TargetID: reported.ID, Reason: models.ReportReasonToxicity}
+
+	if w := postReport(t, reporter.ID, input); w.Code != http.StatusCreated {
+		t.Fatalf("first report: status = %d, want 201", w.Code)
+	}
+	if w := postReport(t, reporter.ID, input); w.Code != http.StatusConflict {
+		t.Fatalf("report while pending: status = %d, want 409", w.Code)
+	}
+
+	// Another reporter is not affected
+	other := createTestUser(t, db, "other")
+	if w := postReport(t, other.ID, input); w.Code != http.StatusCreated {
+		t.Errorf("report by another user: status = %d, want 201", w.Code)
+	}
+
+	// Once the report was handled, the user can be reported again
+	db.Model(&models.Report{}).Where("reporter_id = ?", reporter.ID).Update("status", models.ReportStatusDismissed)
+	if w := postReport(t, reporter.ID, input); w.Code != http.StatusCreated {
+		t.Errorf("report after the first was closed: status = %d, want 201", w.Code)
+	}
+}
+
+func TestBanUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		duration  time.Duration
+		permanent bool
+	}{
+		{name: "temporary", duration: 72 * time.Hour},
+		{name: "permanent", permanent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+
+			user := createTestUser(t, db, "offender")
+			host := createTestUser(t, db, "host")
+			lobby := createTestLobby(t, db, models.Lobby{MaxPlayers: 2}, host)
+			createTestWaitlistEntry(t, db, lobby.ID, user.ID, nil)
+			ticket := models.MatchmakingTicket{UserID: user.ID, GameID: lobby.GameID, PartySize: 4}
+			if err := db.Create(&ticket).Error; err != nil {
+				t.Fatalf("create ticket: %v", err)
+			}
+
+			if err := banUser(user.ID, tt.duration, "spam"); err != nil {
+				t.Fatalf("banUser: %v", err)
+			}
+
+			db.First(&user, user.ID)
+			if !user.IsBanned(time.Now()) || user.BanReason != "spam" {
+				t.Errorf("banned = %v, reason %q, want a ban for spam", user.IsBanned(time.Now()), user.BanReason)
+			}
+			if tt.permanent != (user.BannedUntil == nil) {
+				t.Errorf("banned until %v, want permanent = %v", user.BannedUntil, tt.permanent)
+			}
+			if !tt.permanent && user.IsBanned(time.Now().Add(tt.duration+time.Minute)) {
+				t.Error("ban does not end after its duration")
+			}
+
+			var tickets, entries int64
+			db.Model(&models.MatchmakingTicket{}).Where("user_id = ?", user.ID).Count(&tickets)
+			db.Model(&models.LobbyWaitlistEntry{}).Where("user_id = ?", user.ID).Count(&entries)
+			if tickets != 0 || entries != 0 {
+				t.Errorf("%d tickets and %d waitlist entries left, want none", tickets, entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// reputationHalfLife is the age at which ledger points count for half their value.
+	reputationHalfLife = 30 * 24 * time.Hour
+
+	// reputationMinStay is how long a user has to stay in a lobby for it to count.
+	reputationMinStay = 10 * time.Minute
+
+	// reputationCompletionGrace is how close to the end of a session a user may leave and still complete it.
+	reputationCompletionGrace = time.Minute
+
+	// reputationSweepInterval is how often stays are credited and scores decayed.
+	reputationSweepInterval = 5 * time.Minute
+
+	// reputationNewAccountAge is the account age under which a rater's ratings are dampened.
+	reputationNewAccountAge = 7 * 24 * time.Hour
+
+	// reputationHistoryLimit caps the ledger entries returned with a score.
+	reputationHistoryLimit = 50
+
+	reputationStayPoints           = 1.0
+	reputationSessionPoints        = 2.0
+	reputationPositiveRatingPoints = 3.0
+
+	// reputationPositiveScore is the lowest score that counts as a positive rating.
+	reputationPositiveScore = 4
+
+	// Dampening factors applied to the points of a positive rating.
+	reputationNewRaterFactor = 0.25
+	reputationMutualFactor   = 0.5
+)
+
+// region --- DTOs ---
+
+// ReputationEventResponse describes a reputation ledger entry.
+type ReputationEventResponse struct {
+	Type      models.ReputationEventType `json:"type"`
+	Points    float64                    `json:"points"` // Points at the time of crediting, before decay
+	CreatedAt time.Time                  `json:"created_at"`
+}
+
+// ReputationResponse describes the current user's reputation score and latest ledger entries.
+type ReputationResponse struct {
+	Score  float64                   `json:"score"`
+	Events []ReputationEventResponse `json:"events"`
+}
+
+// endregion
+
+// region --- Ledger ---
+
+// decayedReputationSQL sums a user's ledger with exponentially decaying points. It expects the user ID as argument.
+var decayedReputationSQL = fmt.Sprintf(
+	"SELECT COALESCE(SUM(points * POWER(0.5, EXTRACT(EPOCH FROM (NOW() - created_at)) / %d)), 0) FROM reputation_events WHERE user_id = ?",
+	int64(reputationHalfLife.Seconds()),
+)
+
+// creditReputation adds a ledger entry unless the source was already credited, then refreshes the user's score.
+func creditReputation(db *gorm.DB, userID uint, eventType models.ReputationEventType, points float64, sourceKey string) error {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ReputationEvent{
+		UserID:    userID,
+		Type:      eventType,
+		Points:    points,
+		SourceKey: sourceKey,
+	})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return refreshReputationScore(db, userID)
+}
+
+// refreshReputationScore recomputes the cached score of a user from their ledger.
+func refreshReputationScore(db *gorm.DB, userID uint) error {
+	return db.Model(&models.User{}).Where("id = ?", userID).
+		Update("reputation_score", gorm.Expr("("+decayedReputationSQL+")", userID)).Error
+}
+
+// creditPositiveRating credits the ratee the first time the rater rates them positively.
+// Ratings from brand-new accounts and mutual positive ratings are dampened to blunt throwaway accounts and rating rings.
+func creditPositiveRating(raterID, rateeID uint) error {
+	var rater models.User
+	if err := database.DB.First(&rater, raterID).Error; err != nil {
+		return err
+	}
+
+	points := reputationPositiveRatingPoints
+	if time.Since(rater.CreatedAt) < reputationNewAccountAge {
+		points *= reputationNewRaterFactor
+	}
+	var mutual int64
+	database.DB.Model(&models.UserRating{}).
+		Where("rater_id = ? AND ratee_id = ? AND score >= ?", rateeID, raterID, reputationPositiveScore).
+		Count(&mutual)
+	if mutual > 0 {
+		points *= reputationMutualFactor
+	}
+
+	return creditReputation(database.DB, rateeID, models.ReputationPositiveRating, points,
+		fmt.Sprintf("rating:%d:%d", raterID, rateeID))
+}
+
+// creditLobbyStays credits users who joined an existing lobby and stayed long enough, once per session.
+// The host's own stay starts with the session and does not count.
+func creditLobbyStays(now time.Time) {
+	type stayRow struct {
+		SessionID uint
+		UserID    uint
+	}
+
+	var rows []stayRow
+	database.DB.Table("lobby_session_participants AS p").
+		Distinct("p.session_id", "p.user_id").
+		Joins("JOIN lobby_sessions AS s ON s.id = p.session_id").
+		Where("p.joined_at > s.started_at").
+		Where("COALESCE(p.left_at, ?) >= p.joined_at + ? * INTERVAL '1 second'", now, reputationMinStay.Seconds()).
+		Where("NOT EXISTS (SELECT 1 FROM reputation_events AS e WHERE e.source_key = CONCAT('stay:', p.session_id, ':', p.user_id))").
+		Scan(&rows)
+
+	for _, row := range rows {
+		if err := creditReputation(database.DB, row.UserID, models.ReputationLobbyStay, reputationStayPoints,
+			fmt.Sprintf("stay:%d:%d", row.SessionID, row.UserID)); err != nil {
+			log.Printf("reputation: failed to credit lobby stay: %v", err)
+		}
+	}
+}
+
+// creditCompletedSessions credits users who were still in a lobby when its session ended.
+// Only sessions that lasted long enough and had more than one participant count.
+func creditCompletedSessions() {
+	type completionRow struct {
+		SessionID uint
+		UserID    uint
+	}
+
+	var rows []completionRow
+	database.DB.Table("lobby_session_participants AS p").
+		Distinct("p.session_id", "p.user_id").
+		Joins("JOIN lobby_sessions AS s ON s.id = p.session_id").
+		Where("s.ended_at IS NOT NULL").
+		Where("s.ended_at >= s.started_at + ? * INTERVAL '1 second'", reputationMinStay.Seconds()).
+		Where("p.left_at >= s.ended_at - ? * INTERVAL '1 second'", reputationCompletionGrace.Seconds()).
+		Where("(SELECT COUNT(DISTINCT o.user_id) FROM lobby_session_participants AS o WHERE o.session_id = s.id) > 1").
+		Where("NOT EXISTS (SELECT 1 FROM reputation_events AS e WHERE e.source_key = CONCAT('session:', p.session_id, ':', p.user_id))").
+		Scan(&rows)
+
+	for _, row := range rows {
+		if err := creditReputation(database.DB, row.UserID, models.ReputationSessionCompleted, reputationSessionPoints,
+			fmt.Sprintf("session:%d:%d", row.SessionID, row.UserID)); err != nil {
+			log.Printf("reputation: failed to credit completed session: %v", err)
+		}
+	}
+}
+
+// decayReputationScores recomputes the cached score of every user with ledger entries so old points fade out.
+func decayReputationScores() {
+	err := database.DB.Exec(
+		"UPDATE users SET reputation_score = ("+decayedReputationSQL+") WHERE id IN (SELECT DISTINCT user_id FROM reputation_events)",
+		gorm.Expr("users.id"),
+	).Error
+	if err != nil {
+		log.Printf("reputation: failed to decay scores: %v", err)
+	}
+}
+
+// StartReputationWorker periodically credits lobby stays and completed sessions and decays reputation scores.
+func StartReputationWorker() {
+	go func() {
+		ticker := time.NewTicker(reputationSweepInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			creditLobbyStays(now)
+			creditCompletedSessions()
+			decayReputationScores()
+		}
+	}()
+}
+
+// endregion
+
+// GetMyReputation godoc
+// @Summary      Get my reputation
+// @Description  Returns the current user's reputation score and latest ledger entries. Points are earned for joining lobbies and staying in them, completing sessions and positive ratings, and fade out over time.
+// @Tags         ratings
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} ReputationResponse
+// @Failure      404 {object} ErrorResponse "User not found"
+// @Router       /users/me/reputation [get]
+func GetMyReputation(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var events []models.ReputationEvent
+	if err := database.DB.Where("user_id = ?", user.ID).
+		Order("created_at DESC").
+		Limit(reputationHistoryLimit).
+		Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reputation"})
+		return
+	}
+
+	response := ReputationResponse{
+		Score:  user.ReputationScore,
+		Events: []ReputationEventResponse{},
+	}
+	for _, event := range events {
+		response.Events = append(response.Events, ReputationEventResponse{
+			Type:      event.Type,
+			Points:    event.Points,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"playmatch/backend/internal/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// reputationScore returns the cached reputation score of the user.
+func reputationScore(t *testing.T, db *gorm.DB, userID uint) float64 {
+	t.Helper()
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	return user.ReputationScore
+}
+
+// reputationPoints returns the points credited to the user by source key, or -1 if nothing was credited.
+func reputationPoints(db *gorm.DB, userID uint, sourceKey string) float64 {
+	var event models.ReputationEvent
+	if err := db.Where("user_id = ? AND source_key = ?", userID, sourceKey).First(&event).Error; err != nil {
+		return -1
+	}
+	return event.Points
+}
+
+func TestDecayReputationScores(t *testing.T) {
+	db := testDB(t)
+
+	user := createTestUser(t, db, "veteran")
+	now := time.Now()
+	for i, age := range []time.Duration{0, reputationHalfLife, 2 * reputationHalfLife} {
+		event := models.ReputationEvent{
+			UserID:    user.ID,
+			Type:      models.ReputationLobbyStay,
+			Points:    4,
+			SourceKey: fmt.Sprintf("test:%d", i),
+			CreatedAt: now.Add(-age),
+		}
+		if err := db.Create(&event).Error; err != nil {
+			t.Fatalf("create reputation event: %v", err)
+		}
+	}
+
+	decayReputationScores()
+
+	// 4 points each, at zero, one and two half-lives of age
+	if got, want := reputationScore(t, db, user.ID), 4.0+2.0+1.0; math.Abs(got-want) > 0.01 {
+		t.Errorf("score = %v, want %v", got, want)
+	}
+}
+
+func TestCreditPositiveRating(t *testing.T) {
+	tests := []struct {
+		name       string
+		raterAge   time.Duration
+		mutual     bool
+		wantPoints float64
+	}{
+		{name: "established rater", raterAge: 2 * reputationNewAccountAge, wantPoints: reputationPositiveRatingPoints},
+		{name: "new rater", raterAge: time.Hour, wantPoints: reputationPositiveRatingPoints * reputationNewRaterFactor},
+		{name: "mutual rating", raterAge: 2 * reputationNewAccountAge, mutual: true, wantPoints: reputationPositiveRatingPoints * reputationMutualFactor},
+		{name: "new rater and mutual rating", raterAge: time.Hour, mutual: true, wantPoints: reputationPositiveRatingPoints * reputationNewRaterFactor * reputationMutualFactor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+
+			rater := createTestUser(t, db, "rater")
+			ratee := createTestUser(t, db, "ratee")
+			if err := db.Model(&rater).Update("created_at", time.Now().Add(-tt.raterAge)).Error; err != nil {
+				t.Fatalf("age rater: %v", err)
+			}
+			if tt.mutual {
+				scale := models.RatingScale{Name: "Friendliness", Kind: models.RatingScaleSoft}
+				if err := db.Create(&scale).Error; err != nil {
+					t.Fatalf("create scale: %v", err)
+				}
+				rating := models.UserRating{RaterID: ratee.ID, RateeID: rater.ID, ScaleID: scale.ID, Score: reputationPositiveScore}
+				if err := db.Create(&rating).Error; err != nil {
+					t.Fatalf("create mutual rating: %v", err)
+				}
+			}
+
+			// Rating again must not credit twice
+			for i := 0; i < 2; i++ {
+				if err := creditPositiveRating(rater.ID, ratee.ID); err != nil {
+					t.Fatalf("creditPositiveRating: %v", err)
+				}
+			}
+
+			var credited int64
+			db.Model(&models.ReputationEvent{}).Where("user_id = ?", ratee.ID).Count(&credited)
+			if credited != 1 {
+				t.Fatalf("credited %d times, want once", credited)
+			}
+			if got := reputationScore(t, db, ratee.ID); math.Abs(got-tt.wantPoints) > 0.01 {
+				t.Errorf("score = %v, want %v", got, tt.wantPoints)
+			}
+		})
+	}
+}
+
+// createTestSession stores a lobby session of a new game that started at startedAt and ended at endedAt, if set.
+func createTestSession(t *testing.T, db *gorm.DB, host models.User, startedAt time.Time, endedAt *time.Time) models.LobbySession {
+	t.Helper()
+
+	session := models.LobbySession{
+		LobbyID:   1,
+		GameID:    createTestGame(t, db, "session-"+host.Nickname).ID,
+		HostID:    host.ID,
+		StartedAt: startedAt,
+		EndedAt:   endedAt,
+	}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	return session
+}
+
+// createTestParticipant records that the user was in the session from joinedAt until leftAt, if set.
+func createTestParticipant(t *testing.T, db *gorm.DB, sessionID, userID uint, joinedAt time.Time, leftAt *time.Time) {
+	t.Helper()
+
+	participant := models.LobbySessionParticipant{SessionID: sessionID, UserID: userID, JoinedAt: joinedAt, LeftAt: leftAt}
+	if err := db.Create(&participant).Error; err != nil {
+		t.Fatalf("create participant: %v", err)
+	}
+}
+
+func TestCreditLobbyStays(t *testing.T) {
+	db := testDB(t)
+
+	now := time.Now()
+	start := now.Add(-time.Hour)
+	host := createTestUser(t, db, "host")
+	stayed := createTestUser(t, db, "stayed")
+	staying := createTestUser(t, db, "staying")
+	leftEarly := createTestUser(t, db, "leftearly")
+	justJoined := createTestUser(t, db, "justjoined")
+
+	session := createTestSession(t, db, host, start, nil)
+	createTestParticipant(t, db, session.ID, host.ID, start, nil)
+	stayedUntil := start.Add(5*time.Minute + reputationMinStay)
+	createTestParticipant(t, db, session.ID, stayed.ID, start.Add(5*time.Minute), &stayedUntil)
+	createTestParticipant(t, db, session.ID, staying.ID, now.Add(-2*reputationMinStay), nil)
+	leftAt := start.Add(5*time.Minute + reputationMinStay/2)
+	createTestParticipant(t, db, session.ID, leftEarly.ID, start.Add(5*time.Minute), &leftAt)
+	createTestParticipant(t, db, session.ID, justJoined.ID, now.Add(-time.Minute), nil)
+
+	// A second sweep must not credit anyone again
+	creditLobbyStays(now)
+	creditLobbyStays(now)
+
+	tests := []struct {
+		user models.User
+		want bool
+	}{
+		{user: host, want: false},
+		{user: stayed, want: true},
+		{user: staying, want: true},
+		{user: leftEarly, want: false},
+		{user: justJoined, want: false},
+	}
+	for _, tt := range tests {
+		var credited int64
+		db.Model(&models.ReputationEvent{}).Where("user_id = ? AND type = ?", tt.user.ID, models.ReputationLobbyStay).Count(&credited)
+		if got := credited == 1; got != tt.want || credited > 1 {
+			t.Errorf("%s credited %d times, want credited = %v", tt.user.Nickname, credited, tt.want)
+		}
+		if tt.want {
+			if got := reputationPoints(db, tt.user.ID, fmt.Sprintf("stay:%d:%d", session.ID, tt.user.ID)); got != reputationStayPoints {
+				t.Errorf("%s got %v points, want %v", tt.user.Nickname, got, reputationStayPoints)
+			}
+		}
+	}
+}
+
+func TestCreditCompletedSessions(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration // Of the session
+		leftAt   time.Duration // Of the second participant, before the end of the session
+		solo     bool          // The host is the only participant
+		want     bool
+	}{
+		{name: "stayed until the end", duration: time.Hour, want: true},
+		{name: "left within the grace period", duration: time.Hour, leftAt: reputationCompletionGrace / 2, want: true},
+		{name: "left before the end", duration: time.Hour, leftAt: 10 * time.Minute},
+		{name: "session too short", duration: reputationMinStay / 2},
+		{name: "alone in the lobby", duration: time.Hour, solo: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+
+			end := time.Now().Add(-time.Minute)
+			start := end.Add(-tt.duration)
+			host := createTestUser(t, db, "host")
+			session := createTestSession(t, db, host, start, &end)
+			createTestParticipant(t, db, session.ID, host.ID, start, &end)
+
+			user := host
+			if !tt.solo {
+				user = createTestUser(t, db, "player")
+				leftAt := end.Add(-tt.leftAt)
+				createTestParticipant(t, db, session.ID, user.ID, start, &leftAt)
+			}
+
+			creditCompletedSessions()
+			creditCompletedSessions()
+
+			var credited int64
+			db.Model(&models.ReputationEvent{}).Where("user_id = ? AND type = ?", user.ID, models.ReputationSessionCompleted).Count(&credited)
+			if got := credited == 1; got != tt.want || credited > 1 {
+				t.Errorf("credited %d times, want credited = %v", credited, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"playmatch/backend/internal/asyncapi"
+	"playmatch/backend/internal/hub"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lastEventID reads the ID of the last event a reconnecting client received.
+// Browsers send it as the Last-Event-ID header; clients that cannot set headers may use the last_event_id query parameter.
+func lastEventID(c *gin.Context) (uint64, bool) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	return id, err == nil
+}
+
+// streamEvents writes the missed messages and then everything the client receives as Server-Sent Events
+// until the request ends or the hub closes the client. Numbered messages carry their ID so the client can resume after them.
+// While idle, a comment is written every heartbeat interval so proxies keep the connection open,
+// and each heartbeat keeps the user online.
+func streamEvents(c *gin.Context, client hub.Client, missed []hub.Message) {
+	userID := c.GetUint("userID")
+	touchPresence(userID, false)
+
+	write := func(w io.Writer, message hub.Message) {
+		if message.ID != 0 {
+			fmt.Fprintf(w, "id:%d\n", message.ID)
+		}
+		c.SSEvent("message", string(message.Data))
+	}
+
+	heartbeat := time.NewTicker(hub.GlobalHub.HeartbeatInterval())
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		if len(missed) > 0 {
+			write(w, missed[0])
+			missed = missed[1:]
+			return true
+		}
+
+		select {
+		case message, ok := <-client:
+			if !ok {
+				return false
+			}
+			write(w, message)
+			return true
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			touchPresence(userID, false)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// GetEventSchema godoc
+// @Summary      Get the real-time event schema
+// @Description  Returns the AsyncAPI 2.6 document describing every event of the lobby and user streams: the envelope (`id`, `version`, `type`, `timestamp`, `lobby_id`, `payload`) and the payload of each event type. The same document is written by `make asyncapi-gen`.
+// @Tags         events
+// @Produce      json
+// @Success      200 {object} object
+// @Router       /events/schema [get]
+func GetEventSchema(c *gin.Context) {
+	c.JSON(http.StatusOK, asyncapi.Document(hub.EventVersion, EventCatalog))
+}
+
+// GetHubStats godoc
+// @Summary      Get real-time hub statistics
+// @Description  Returns the number of connected event streams, how many slow clients were evicted and how many events were dropped because a client's queue was full, by lobby.
+// @Tags         admin-hub
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} hub.Stats
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Router       /admin/hub/stats [get]
+func GetHubStats(c *gin.Context) {
+	c.JSON(http.StatusOK, hub.GlobalHub.Stats())
+}
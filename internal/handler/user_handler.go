@@ -4,9 +4,11 @@ import (
 	"errors"
 	"net/http"
 	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
 	"playmatch/backend/internal/models"
 	"playmatch/backend/pkg/jwt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -30,25 +32,35 @@ type LoginInput struct {
 
 // PublicUserResponse defines the structure for a user's public profile.
 type PublicUserResponse struct {
-	ID             uint                     `json:"id" example:"1"`
-	Nickname       string                   `json:"nickname" example:"testuser"`
-	FriendsCount   int64                    `json:"friends_count"`
-	FollowersCount int64                    `json:"followers_count"`
-	FollowingCount int64                    `json:"following_count"`
-	RelationToMe   *models.FriendshipStatus `json:"relation_to_me,omitempty"`
-	MeToRelation   *models.FriendshipStatus `json:"me_to_relation,omitempty"`
-	CurrentLobbyID *uint                    `json:"current_lobby_id,omitempty"`
+	ID             uint                              `json:"id" example:"1"`
+	Nickname       string                            `json:"nickname" example:"testuser"`
+	FriendsCount   int64                             `json:"friends_count"`
+	FollowersCount int64                             `json:"followers_count"`
+	FollowingCount int64                             `json:"following_count"`
+	RelationToMe   *models.FriendshipStatus          `json:"relation_to_me,omitempty"`
+	MeToRelation   *models.FriendshipStatus          `json:"me_to_relation,omitempty"`
+	CurrentLobbyID *uint                             `json:"current_lobby_id,omitempty"` // Only shown to viewers who may see the user's presence
+	HardSkillScore *float64                          `json:"hard_skill_score,omitempty"` // Average of hard-skill ratings
+	SoftSkillScore *float64                          `json:"soft_skill_score,omitempty"` // Average of soft-skill ratings
+	RatingsCount   int64                             `json:"ratings_count"`
+	Reputation     float64                           `json:"reputation"`
+	Endorsements   map[models.EndorsementBadge]int64 `json:"endorsements"`       // Times each badge was received
+	Presence       *PresenceResponse                 `json:"presence,omitempty"` // Omitted when the user hides it from the viewer
 }
 
 // PrivateUserResponse defines the structure for the authenticated user's own profile.
 type PrivateUserResponse struct {
-	ID             uint   `json:"id" example:"1"`
-	Nickname       string `json:"nickname" example:"testuser"`
-	Email          string `json:"email" example:"test@example.com"`
-	FriendsCount   int64  `json:"friends_count"`
-	FollowersCount int64  `json:"followers_count"`
-	FollowingCount int64  `json:"following_count"`
-	CurrentLobbyID *uint  `json:"current_lobby_id,omitempty"`
+	ID             uint            `json:"id" example:"1"`
+	Nickname       string          `json:"nickname" example:"testuser"`
+	Email          string          `json:"email" example:"test@example.com"`
+	FriendsCount   int64           `json:"friends_count"`
+	FollowersCount int64           `json:"followers_count"`
+	FollowingCount int64           `json:"following_count"`
+	CurrentLobbyID *uint           `json:"current_lobby_id,omitempty"`
+	Reputation     float64         `json:"reputation"`
+	DMPolicy       models.DMPolicy `json:"dm_policy"`
+
+	PresenceVisibility models.PresenceVisibility `json:"presence_visibility"`
 }
 
 // ErrorResponse represents a generic error response.
@@ -127,6 +139,7 @@ func RegisterUser(c *gin.Context) {
 // @Success      200  {object}  map[string]string "{"token": "..."}"
 // @Failure      400  {object}  ErrorResponse "Invalid input"
 // @Failure      401  {object}  ErrorResponse "Invalid credentials"
+// @Failure      403  {object}  ErrorResponse "Account is banned"
 // @Failure      404  {object}  ErrorResponse "User not found"
 // @Failure      500  {object}  ErrorResponse "Internal server error"
 // @Router       /auth/login [post]
@@ -147,6 +160,14 @@ func LoginUser(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
+	if user.IsBanned(time.Now()) {
+		response := gin.H{"error": "Account is banned", "reason": user.BanReason}
+		if user.BannedUntil != nil {
+			response["banned_until"] = user.BannedUntil
+		}
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
 
 	token, err := jwt.GenerateToken(user.ID)
 	if err != nil {
@@ -296,6 +317,28 @@ func GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// SubscribeToUserEvents godoc
+// @Summary      Subscribe to my personal events (SSE)
+// @Description  Establishes a Server-Sent Events connection to receive real-time events addressed to the current user, such as waitlist offers.
+// @Tags         users
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Success      200 {string} string "Event stream"
+// @Failure      401 {object} ErrorResponse
+// @Router       /users/me/events [get]
+func SubscribeToUserEvents(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	clientChan := hub.GlobalHub.NewClient()
+	hub.GlobalHub.SubscribeUser(userID.(uint), clientChan)
+
+	defer func() {
+		hub.GlobalHub.UnsubscribeUser(userID.(uint), clientChan)
+	}()
+
+	streamEvents(c, clientChan, nil)
+}
+
 // endregion
 
 // region --- Helpers ---
@@ -314,10 +357,12 @@ func buildPublicUserResponse(targetUser models.User, viewerID uint) PublicUserRe
 
 	// Get relationship status between viewer and target, only if a viewer is present
 	var relationToMeStatus, meToRelationStatus *models.FriendshipStatus
+	var friends bool
 	if viewerID != 0 {
 		var relationToMe, meToRelation models.UserRelation
 		err := database.DB.Where("from_user_id = ? AND to_user_id = ?", targetUser.ID, viewerID).First(&relationToMe).Error
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
+		// Users are not told that someone blocked them
+		if !errors.Is(err, gorm.ErrRecordNotFound) && relationToMe.Status != models.StatusBlocked {
 			relationToMeStatus = &relationToMe.Status
 		}
 
@@ -325,6 +370,16 @@ func buildPublicUserResponse(targetUser models.User, viewerID uint) PublicUserRe
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			meToRelationStatus = &meToRelation.Status
 		}
+
+		friends = relationToMe.Status == models.StatusAccepted || meToRelation.Status == models.StatusAccepted
+	}
+
+	hardSkillScore, softSkillScore, ratingsCount := userRatingAggregates(targetUser.ID)
+
+	// The current lobby reveals presence as much as the status does
+	var currentLobbyID *uint
+	if canSeePresence(targetUser, viewerID, friends) {
+		currentLobbyID = targetUser.CurrentLobbyID
 	}
 
 	return PublicUserResponse{
@@ -335,7 +390,13 @@ func buildPublicUserResponse(targetUser models.User, viewerID uint) PublicUserRe
 		FollowingCount: followingCount,
 		RelationToMe:   relationToMeStatus,
 		MeToRelation:   meToRelationStatus,
-		CurrentLobbyID: targetUser.CurrentLobbyID,
+		CurrentLobbyID: currentLobbyID,
+		HardSkillScore: hardSkillScore,
+		SoftSkillScore: softSkillScore,
+		RatingsCount:   ratingsCount,
+		Reputation:     targetUser.ReputationScore,
+		Endorsements:   userEndorsementCounts(targetUser.ID),
+		Presence:       buildPresenceResponse(targetUser, viewerID, friends),
 	}
 }
 
@@ -358,6 +419,10 @@ func buildPrivateUserResponse(user models.User) PrivateUserResponse {
 		FollowersCount: followersCount,
 		FollowingCount: followingCount,
 		CurrentLobbyID: user.CurrentLobbyID,
+		Reputation:     user.ReputationScore,
+		DMPolicy:       user.DMPolicy,
+
+		PresenceVisibility: user.PresenceVisibility,
 	}
 }
 
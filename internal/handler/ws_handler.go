@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"playmatch/backend/internal/config"
+	"playmatch/backend/internal/database"
+	"playmatch/backend/internal/hub"
+	"playmatch/backend/internal/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gorilla/websocket"
+)
+
+// Commands a WebSocket client can send.
+const (
+	wsCommandSendMessage = "send_message"
+	wsCommandTyping      = "typing"
+	wsCommandReady       = "ready"
+	wsCommandAck         = "ack"
+)
+
+var wsUpgrader = websocket.Upgrader{CheckOrigin: checkWSOrigin}
+
+// wsWriteTimeout bounds a single frame write, so a stalled connection cannot hold the writer forever.
+const wsWriteTimeout = 10 * time.Second
+
+// wsAcks remembers the last lobby event each user acknowledged, so a new connection can resume after it.
+// Like the event IDs themselves, acks are kept per instance and only resume connections to the same instance.
+// An entry is dropped when the user leaves the lobby.
+var wsAcks = struct {
+	sync.Mutex
+	byUser map[uint]wsAck
+}{byUser: make(map[uint]wsAck)}
+
+type wsAck struct {
+	LobbyID uint
+	EventID uint64
+}
+
+// region --- DTOs ---
+
+// WSCommand is a command sent by a WebSocket client.
+type WSCommand struct {
+	ID      string          `json:"id"`   // Chosen by the client and echoed in the result
+	Type    string          `json:"type"` // send_message, typing, ready or ack
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+}
+
+// WSCommandResult answers a WebSocket command. It is sent as a `command_result` event.
+type WSCommandResult struct {
+	ID    string      `json:"id"`
+	OK    bool        `json:"ok"`
+	Error string      `json:"error,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+// WSAckInput is the payload of the ack command.
+type WSAckInput struct {
+	EventID uint64 `json:"event_id" binding:"required"`
+}
+
+// endregion
+
+// region --- Helpers ---
+
+// checkWSOrigin accepts browser connections from the server's own origin and from WS_ALLOWED_ORIGINS ("*" allows any).
+// Clients that send no Origin header, i.e. non-browser clients, are always accepted.
+func checkWSOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if config.AppConfig != nil {
+		for _, allowed := range config.AppConfig.WSAllowedOrigins {
+			if allowed == "*" || strings.EqualFold(allowed, origin) {
+				return true
+			}
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// forgetWSAck drops the user's acknowledged event of a lobby they left.
+func forgetWSAck(userID, lobbyID uint) {
+	wsAcks.Lock()
+	defer wsAcks.Unlock()
+	if ack, ok := wsAcks.byUser[userID]; ok && ack.LobbyID == lobbyID {
+		delete(wsAcks.byUser, userID)
+	}
+}
+
+// wsConnection serializes writes to a WebSocket, which allows only one writer at a time.
+type wsConnection struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (ws *wsConnection) write(data []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return ws.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// ping sends a keepalive the client answers with a pong.
+func (ws *wsConnection) ping() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+}
+
+func (ws *wsConnection) reply(result WSCommandResult) {
+	data, err := json.Marshal(hub.Event{
+		Version:   hub.EventVersion,
+		Type:      EventCommandResult,
+		Timestamp: time.Now().UTC(),
+		Payload:   result,
+	})
+	if err != nil {
+		return
+	}
+	ws.write(data)
+}
+
+// wsCommandError is a command failure whose message can be shown to the client.
+type wsCommandError string
+
+func (e wsCommandError) Error() string { return string(e) }
+
+// decodeWSPayload decodes and validates the payload of a command like a request body.
+func decodeWSPayload(payload json.RawMessage, target interface{}) error {
+	if len(payload) == 0 {
+		payload = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(payload, target); err != nil {
+		return wsCommandError("Invalid payload")
+	}
+	if err := binding.Validator.ValidateStruct(target); err != nil {
+		return wsCommandError(err.Error())
+	}
+	return nil
+}
+
+// runWSCommand executes a command for the user and returns the data to reply with.
+func runWSCommand(userID uint, command WSCommand) (interface{}, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
+		return nil, wsCommandError("User is not in a lobby")
+	}
+	// Acknowledgements are sent automatically and do not show that the user is active
+	if command.Type != wsCommandAck {
+		touchPresence(userID, true)
+	}
+
+	switch command.Type {
+	case wsCommandSendMessage:
+		var input MessageInput
+		if err := decodeWSPayload(command.Payload, &input); err != nil {
+			return nil, err
+		}
+		scope, err := loadLobbyChatScope(userID)
+		if err != nil {
+			return nil, wsCommandError("User is not in a lobby")
+		}
+		if isChatCommand(input.Content) {
+			response, err := runChatCommand(scope, input.Content)
+			var commandErr chatCommandError
+			switch {
+			case errors.As(err, &commandErr):
+				return nil, wsCommandError(commandErr)
+			case errors.Is(err, errNotLobbyManager):
+				return nil, wsCommandError("Only the host or a co-host can use this command")
+			case errors.Is(err, errNotInLobby):
+				return nil, wsCommandError("User is not in a lobby")
+			case chatGuardMessage(err) != "":
+				return nil, wsCommandError(chatGuardMessage(err))
+			}
+			return response, err
+		}
+		input.Content = unescapeChatText(input.Content)
+
+		message, err := createChatMessage(scope, input, EventNewMessage)
+		if errors.Is(err, errReplyNotFound) {
+			return nil, wsCommandError("Replied message not found in this chat")
+		}
+		if reason := chatGuardMessage(err); reason != "" {
+			return nil, wsCommandError(reason)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return newMessageResponse(message), nil
+
+	case wsCommandTyping:
+		broadcastTyping(user)
+		return nil, nil
+
+	case wsCommandReady:
+		var input ReadyInput
+		if err := decodeWSPayload(command.Payload, &input); err != nil {
+			return nil, err
+		}
+		if err := setLobbyReady(user, *input.Ready); err != nil {
+			return nil, err
+		}
+		return UserReadyPayload{UserID: user.ID, Ready: *input.Ready}, nil
+
+	case wsCommandAck:
+		var input WSAckInput
+		if err := decodeWSPayload(command.Payload, &input); err != nil {
+			return nil, err
+		}
+		wsAcks.Lock()
+		wsAcks.byUser[userID] = wsAck{LobbyID: *user.CurrentLobbyID, EventID: input.EventID}
+		wsAcks.Unlock()
+		return nil, nil
+	}
+
+	return nil, wsCommandError("Unknown command")
+}
+
+// endregion
+
+// SubscribeToLobbyWebSocket godoc
+// @Summary      Connect to my lobby over WebSocket
+// @Description  Upgrades to a WebSocket that delivers the same events as `/lobbies/me/events`, as JSON text frames with an `id`. Browsers that cannot set the Authorization header may pass the token as `access_token`.
+// @Description  Clients send commands as `{"id": "...", "type": "...", "payload": {...}}`: `send_message` (MessageInput, slash commands are answered with a ChatCommandResponse), `typing`, `ready` (ReadyInput) and `ack` (`{"event_id": N}`). Each command is answered with a `command_result` event carrying the same `id`, and an `error` if it failed.
+// @Description  A new connection resumes after `last_event_id` or, without it, after the last acknowledged event.
+// @Description  The connection is closed when the user leaves the lobby or is kicked or banned from it.
+// @Tags         lobbies-chat
+// @Security     BearerAuth
+// @Param        access_token  query string false "JWT, for clients that cannot set the Authorization header"
+// @Param        last_event_id query int    false "ID of the last event received before reconnecting"
+// @Success      101 {string} string "Switching Protocols"
+// @Failure      401 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse "User is not in a lobby"
+// @Router       /lobbies/me/ws [get]
+func SubscribeToLobbyWebSocket(c *gin.Context) {
+	userIDValue, _ := c.Get("userID")
+	userID := userIDValue.(uint)
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil || user.CurrentLobbyID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in a lobby"})
+		return
+	}
+	lobbyID := *user.CurrentLobbyID
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader already replied with an error
+		return
+	}
+	ws := &wsConnection{conn: conn}
+	defer conn.Close()
+
+	eventID, resume := lastEventID(c)
+	if !resume {
+		wsAcks.Lock()
+		ack, ok := wsAcks.byUser[userID]
+		wsAcks.Unlock()
+		if ok && ack.LobbyID == lobbyID {
+			eventID, resume = ack.EventID, true
+		}
+	}
+
+	clientChan := hub.GlobalHub.NewClient()
+	var missed []hub.Message
+	if resume {
+		missed = hub.GlobalHub.Resume(lobbyID, userID, clientChan, eventID)
+	} else {
+		hub.GlobalHub.Subscribe(lobbyID, userID, clientChan)
+	}
+	defer hub.GlobalHub.Unsubscribe(lobbyID, clientChan)
+
+	touchPresence(userID, false)
+
+	// A connection that misses two heartbeats in a row is considered dead
+	heartbeatInterval := hub.GlobalHub.HeartbeatInterval()
+	conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	})
+
+	// Forward hub events until the hub closes the client channel
+	go func() {
+		// Evicted clients are disconnected after their final resync event
+		defer conn.Close()
+
+		for _, message := range missed {
+			if err := ws.write(message.Data); err != nil {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case message, ok := <-clientChan:
+				if !ok {
+					return
+				}
+				if err := ws.write(message.Data); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if err := ws.ping(); err != nil {
+					return
+				}
+				touchPresence(userID, false)
+			}
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("ws: connection of user %d closed: %v", userID, err)
+			}
+			return
+		}
+
+		var command WSCommand
+		if err := json.Unmarshal(data, &command); err != nil {
+			ws.reply(WSCommandResult{Error: "Invalid command"})
+			continue
+		}
+
+		result, err := runWSCommand(userID, command)
+		var commandErr wsCommandError
+		if errors.As(err, &commandErr) {
+			ws.reply(WSCommandResult{ID: command.ID, Error: commandErr.Error()})
+			continue
+		}
+		if err != nil {
+			log.Printf("ws: command %q of user %d failed: %v", command.Type, userID, err)
+			ws.reply(WSCommandResult{ID: command.ID, Error: "Command failed"})
+			continue
+		}
+		ws.reply(WSCommandResult{ID: command.ID, OK: true, Data: result})
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"playmatch/backend/internal/config"
+	"testing"
+)
+
+func TestCheckWSOrigin(t *testing.T) {
+	previous := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed []string
+		want    bool
+	}{
+		{name: "no origin", origin: "", want: true},
+		{name: "same origin", origin: "https://api.example.com", want: true},
+		{name: "same origin, other case", origin: "https://API.example.com", want: true},
+		{name: "foreign origin", origin: "https://evil.example.org", want: false},
+		{name: "allowed origin", origin: "https://app.example.com", allowed: []string{"https://app.example.com"}, want: true},
+		{name: "other allowed origin", origin: "https://evil.example.org", allowed: []string{"https://app.example.com"}, want: false},
+		{name: "any origin allowed", origin: "https://evil.example.org", allowed: []string{"*"}, want: true},
+		{name: "malformed origin", origin: "://", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AppConfig = &config.Config{WSAllowedOrigins: tt.allowed}
+			r := httptest.NewRequest("GET", "https://api.example.com/lobbies/me/ws", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+
+			if got := checkWSOrigin(r); got != tt.want {
+				t.Errorf("checkWSOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForgetWSAck(t *testing.T) {
+	wsAcks.Lock()
+	wsAcks.byUser[1] = wsAck{LobbyID: 10, EventID: 5}
+	wsAcks.byUser[2] = wsAck{LobbyID: 20, EventID: 7}
+	wsAcks.Unlock()
+	t.Cleanup(func() {
+		wsAcks.Lock()
+		delete(wsAcks.byUser, 1)
+		delete(wsAcks.byUser, 2)
+		wsAcks.Unlock()
+	})
+
+	forgetWSAck(1, 10)
+	forgetWSAck(2, 99) // The ack belongs to another lobby
+
+	wsAcks.Lock()
+	defer wsAcks.Unlock()
+	if _, ok := wsAcks.byUser[1]; ok {
+		t.Error("ack of the left lobby is still remembered")
+	}
+	if _, ok := wsAcks.byUser[2]; !ok {
+		t.Error("ack of another lobby was dropped")
+	}
+}
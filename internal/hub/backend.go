@@ -0,0 +1,43 @@
+package hub
+
+import "sync"
+
+// Backend carries encoded events between the hubs of all server instances.
+// Every published event must be delivered to every hub sharing the backend, including the one that published it.
+type Backend interface {
+	// Publish sends an encoded event to all hubs.
+	Publish(data []byte) error
+	// Listen starts delivering published events to deliver. It is called once, when the hub starts using the backend.
+	Listen(deliver func(data []byte))
+}
+
+// MemoryBackend delivers events within the current process only. It suits a single server instance.
+type MemoryBackend struct {
+	mu      sync.RWMutex
+	deliver func(data []byte)
+}
+
+// NewMemoryBackend creates an in-process backend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{}
+}
+
+// Publish delivers the event synchronously.
+func (b *MemoryBackend) Publish(data []byte) error {
+	b.mu.RLock()
+	deliver := b.deliver
+	b.mu.RUnlock()
+
+	if deliver != nil {
+		deliver(data)
+	}
+	return nil
+}
+
+// Listen registers the function events are delivered to.
+func (b *MemoryBackend) Listen(deliver func(data []byte)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deliver = deliver
+}
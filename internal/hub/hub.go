@@ -2,44 +2,245 @@ package hub
 
 import (
 	"encoding/json"
+	"log"
 	"sync"
+	"time"
 )
 
+// EventType names a kind of real-time event. Each type has a fixed payload structure.
+type EventType string
+
+// EventVersion is the version of the event envelope and payload schema.
+// It is increased whenever an existing event changes incompatibly.
+const EventVersion = 1
+
 // Event represents a real-time event to be sent to clients.
+// Version, Timestamp and LobbyID are filled in by the hub when the event is published.
 type Event struct {
-	Type    string      `json:"type"`
-	Payload interface{} `json:"payload"`
+	ID        uint64      `json:"id,omitempty"` // Position in the lobby's event sequence, set on delivery
+	Version   int         `json:"version"`
+	Type      EventType   `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	LobbyID   *uint       `json:"lobby_id,omitempty"` // Set for lobby events
+	Payload   interface{} `json:"payload"`
+}
+
+// EventResync tells a resuming client that events were lost and it has to reload its state.
+const EventResync EventType = "resync"
+
+// ResyncPayload is the payload of a resync event.
+type ResyncPayload struct {
+	LastEventID uint64 `json:"last_event_id"` // Resume after this event once the state is reloaded
+}
+
+// lobbyHistorySize is the number of recent events kept per lobby for clients that reconnect.
+const lobbyHistorySize = 256
+
+// Message is an encoded event as delivered to a client.
+type Message struct {
+	ID   uint64 // Position in the lobby's event sequence, zero for user events
+	Data []byte
 }
 
 // Client represents a single client connection (a user in a lobby).
 // It's essentially a channel that the SSE handler will listen to.
-type Client chan []byte
+// Clients are created with NewClient so they can queue events while the connection is busy writing.
+type Client chan Message
+
+// Options tune how the hub treats its clients.
+type Options struct {
+	ClientBuffer      int           // Number of events queued per client
+	SlowClientTimeout time.Duration // How long a client may stay full before it is evicted
+	HeartbeatInterval time.Duration // How often idle streams are kept alive
+}
+
+// DefaultOptions are used until the hub is configured.
+var DefaultOptions = Options{
+	ClientBuffer:      64,
+	SlowClientTimeout: 10 * time.Second,
+	HeartbeatInterval: 25 * time.Second,
+}
+
+// Stats reports the connections of the hub and the events it had to drop.
+type Stats struct {
+	LobbyClients  int             `json:"lobby_clients"`
+	UserClients   int             `json:"user_clients"`
+	Evicted       uint64          `json:"evicted"`
+	DroppedByUser uint64          `json:"dropped_user_events"`
+	DroppedLobby  map[uint]uint64 `json:"dropped_lobby_events"` // Dropped events by lobby ID
+}
+
+// lobbyHistory numbers the events of a lobby and keeps the most recent ones.
+type lobbyHistory struct {
+	lastID   uint64
+	messages []Message
+}
+
+// Topics an envelope can be addressed to.
+const (
+	topicLobby      = "lobby"
+	topicUser       = "user"
+	topicForget     = "forget"     // Drops the buffered events of a lobby
+	topicDisconnect = "disconnect" // Closes the lobby clients of a user
+)
 
-// Hub manages all active lobbies and their clients.
+// envelope is an event on its way through the backend.
+type envelope struct {
+	Topic  string          `json:"topic"`
+	Key    uint            `json:"key"`               // Lobby or user ID
+	UserID uint            `json:"user_id,omitempty"` // User whose lobby clients are closed, for disconnects
+	Event  json.RawMessage `json:"event,omitempty"`
+}
+
+// Hub manages all active lobbies and their clients, as well as
+// user-scoped streams for events that target a single user.
+// Events are published through a Backend, so hubs of several server instances can share them.
 type Hub struct {
-	lobbies map[uint]map[Client]bool
-	mu      sync.RWMutex
+	lobbies     map[uint]map[Client]uint // Clients by lobby ID, with the ID of the user they belong to
+	users       map[uint]map[Client]uint // Clients by user ID
+	history     map[uint]*lobbyHistory
+	backend     Backend
+	options     Options
+	fullSince   map[Client]time.Time // When each full client first missed an event
+	dropped     map[uint]uint64      // Dropped events by lobby ID
+	droppedUser uint64
+	evicted     uint64
+	mu          sync.RWMutex
 }
 
 // GlobalHub is the singleton instance of our Hub.
 var GlobalHub = NewHub()
 
-// NewHub creates a new Hub.
+// NewHub creates a new Hub that delivers events within the current process.
 func NewHub() *Hub {
-	return &Hub{
-		lobbies: make(map[uint]map[Client]bool),
+	h := &Hub{
+		lobbies:   make(map[uint]map[Client]uint),
+		users:     make(map[uint]map[Client]uint),
+		history:   make(map[uint]*lobbyHistory),
+		options:   DefaultOptions,
+		fullSince: make(map[Client]time.Time),
+		dropped:   make(map[uint]uint64),
 	}
+	h.UseBackend(NewMemoryBackend())
+	return h
 }
 
-// Subscribe adds a new client to a specific lobby.
-func (h *Hub) Subscribe(lobbyID uint, client Client) {
+// UseBackend makes the hub publish and receive events through the backend.
+// It should be called at startup, before any client subscribes.
+func (h *Hub) UseBackend(backend Backend) {
+	h.mu.Lock()
+	h.backend = backend
+	h.mu.Unlock()
+
+	backend.Listen(h.deliver)
+}
+
+// Configure replaces the client options. It should be called at startup, before any client subscribes.
+func (h *Hub) Configure(options Options) {
+	if options.ClientBuffer < 1 {
+		options.ClientBuffer = DefaultOptions.ClientBuffer
+	}
+	if options.SlowClientTimeout <= 0 {
+		options.SlowClientTimeout = DefaultOptions.SlowClientTimeout
+	}
+	if options.HeartbeatInterval <= 0 {
+		options.HeartbeatInterval = DefaultOptions.HeartbeatInterval
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.options = options
+}
+
+// NewClient creates a client with a queue of the configured size.
+func (h *Hub) NewClient() Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return make(Client, h.options.ClientBuffer)
+}
+
+// HeartbeatInterval is how often streams should send a keepalive while idle.
+func (h *Hub) HeartbeatInterval() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.options.HeartbeatInterval
+}
+
+// Stats returns the current connection counts and dropped event counters.
+func (h *Hub) Stats() Stats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats := Stats{
+		Evicted:       h.evicted,
+		DroppedByUser: h.droppedUser,
+		DroppedLobby:  make(map[uint]uint64, len(h.dropped)),
+	}
+	for _, clients := range h.lobbies {
+		stats.LobbyClients += len(clients)
+	}
+	for _, clients := range h.users {
+		stats.UserClients += len(clients)
+	}
+	for lobbyID, count := range h.dropped {
+		stats.DroppedLobby[lobbyID] = count
+	}
+	return stats
+}
+
+// Subscribe adds a new client of a user to a specific lobby.
+func (h *Hub) Subscribe(lobbyID, userID uint, client Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	if _, ok := h.lobbies[lobbyID]; !ok {
-		h.lobbies[lobbyID] = make(map[Client]bool)
+		h.lobbies[lobbyID] = make(map[Client]uint)
 	}
-	h.lobbies[lobbyID][client] = true
+	h.lobbies[lobbyID][client] = userID
+}
+
+// Resume adds a new client of a user to a lobby and returns the events it missed after lastEventID.
+// When some of them are no longer buffered, a single resync event is returned instead.
+func (h *Hub) Resume(lobbyID, userID uint, client Client, lastEventID uint64) []Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.lobbies[lobbyID]; !ok {
+		h.lobbies[lobbyID] = make(map[Client]uint)
+	}
+	h.lobbies[lobbyID][client] = userID
+
+	history := h.history[lobbyID]
+	if history == nil {
+		history = &lobbyHistory{}
+		h.history[lobbyID] = history
+	}
+	if lastEventID == history.lastID {
+		return nil
+	}
+
+	// The sequence restarts with the server, so IDs from the future are stale as well
+	oldestID := history.lastID - uint64(len(history.messages)) + 1
+	if lastEventID > history.lastID || lastEventID+1 < oldestID {
+		return []Message{{ID: history.lastID, Data: resyncEvent(&lobbyID, history.lastID)}}
+	}
+
+	missed := history.messages[len(history.messages)-int(history.lastID-lastEventID):]
+	return append([]Message(nil), missed...)
+}
+
+// Forget drops the buffered events of a lobby on all instances once it no longer exists.
+func (h *Hub) Forget(lobbyID uint) {
+	h.publish(envelope{Topic: topicForget, Key: lobbyID})
+}
+
+// Disconnect closes the clients a user has subscribed to a lobby on all instances,
+// e.g. once the user left or was removed from it. Events already queued are still delivered.
+func (h *Hub) Disconnect(lobbyID, userID uint) {
+	h.publish(envelope{Topic: topicDisconnect, Key: lobbyID, UserID: userID})
 }
 
 // Unsubscribe removes a client from a lobby.
@@ -50,6 +251,7 @@ func (h *Hub) Unsubscribe(lobbyID uint, client Client) {
 	if clients, ok := h.lobbies[lobbyID]; ok {
 		if _, ok := clients[client]; ok {
 			delete(clients, client)
+			delete(h.fullSince, client)
 			close(client) // Close the channel to signal the SSE handler to stop.
 			if len(clients) == 0 {
 				delete(h.lobbies, lobbyID)
@@ -58,26 +260,207 @@ func (h *Hub) Unsubscribe(lobbyID uint, client Client) {
 	}
 }
 
+// SubscribeUser adds a new client to the stream of a specific user.
+func (h *Hub) SubscribeUser(userID uint, client Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.users[userID]; !ok {
+		h.users[userID] = make(map[Client]uint)
+	}
+	h.users[userID][client] = userID
+}
+
+// UnsubscribeUser removes a client from the stream of a user.
+func (h *Hub) UnsubscribeUser(userID uint, client Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if clients, ok := h.users[userID]; ok {
+		if _, ok := clients[client]; ok {
+			delete(clients, client)
+			delete(h.fullSince, client)
+			close(client)
+			if len(clients) == 0 {
+				delete(h.users, userID)
+			}
+		}
+	}
+}
+
 // Broadcast sends an event to all clients in a specific lobby.
+// The event is numbered and buffered so reconnecting clients can resume after it.
 func (h *Hub) Broadcast(lobbyID uint, event Event) {
+	h.publishEvent(topicLobby, lobbyID, event)
+}
+
+// SendToUser sends an event to all clients streaming the events of a specific user.
+func (h *Hub) SendToUser(userID uint, event Event) {
+	h.publishEvent(topicUser, userID, event)
+}
+
+// publishEvent encodes an event and publishes it to a topic.
+func (h *Hub) publishEvent(topic string, key uint, event Event) {
+	event.Version = EventVersion
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if topic == topicLobby {
+		event.LobbyID = &key
+	}
+
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("hub: failed to encode %s event: %v", event.Type, err)
+		return
+	}
+	h.publish(envelope{Topic: topic, Key: key, Event: eventBytes})
+}
+
+// publish hands an envelope to the backend.
+func (h *Hub) publish(env envelope) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return
+	}
+
 	h.mu.RLock()
-	defer h.mu.RUnlock()
+	backend := h.backend
+	h.mu.RUnlock()
 
-	if clients, ok := h.lobbies[lobbyID]; ok {
-		messageBytes, err := json.Marshal(event)
-		if err != nil {
-			// Handle error appropriately, maybe log it
-			return
-		}
-		
-		for client := range clients {
-			// Use a non-blocking send to prevent a slow client from blocking the hub.
-			select {
-			case client <- messageBytes:
-			default:
-				// Client channel is full, maybe they are disconnected or slow.
-				// The unsubscribe logic will handle cleaning this up eventually.
+	if err := backend.Publish(data); err != nil {
+		log.Printf("hub: failed to publish %s event: %v", env.Topic, err)
+	}
+}
+
+// deliver passes an envelope received from the backend to the local clients.
+func (h *Hub) deliver(data []byte) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		log.Printf("hub: dropped malformed envelope: %v", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch env.Topic {
+	case topicLobby:
+		h.deliverToLobby(env.Key, env.Event)
+	case topicUser:
+		resync := Message{Data: resyncEvent(nil, 0)}
+		h.droppedUser += uint64(h.send(h.users, env.Key, Message{Data: env.Event}, resync))
+	case topicForget:
+		delete(h.history, env.Key)
+		delete(h.dropped, env.Key)
+	case topicDisconnect:
+		for client, userID := range h.lobbies[env.Key] {
+			if userID == env.UserID {
+				h.remove(h.lobbies, env.Key, client)
 			}
 		}
 	}
 }
+
+// deliverToLobby numbers an event, buffers it and sends it to the lobby's clients. The caller must hold the lock.
+func (h *Hub) deliverToLobby(lobbyID uint, eventBytes json.RawMessage) {
+	// The payload is kept encoded, only the ID is added
+	var payload json.RawMessage
+	event := Event{Payload: &payload}
+	if err := json.Unmarshal(eventBytes, &event); err != nil {
+		return
+	}
+
+	history := h.history[lobbyID]
+	if history == nil {
+		history = &lobbyHistory{}
+		h.history[lobbyID] = history
+	}
+	event.ID = history.lastID + 1
+	messageBytes, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	history.lastID++
+
+	message := Message{ID: history.lastID, Data: messageBytes}
+	if len(history.messages) == lobbyHistorySize {
+		copy(history.messages, history.messages[1:])
+		history.messages = history.messages[:lobbyHistorySize-1]
+	}
+	history.messages = append(history.messages, message)
+
+	resync := Message{ID: history.lastID, Data: resyncEvent(&lobbyID, history.lastID)}
+	if dropped := h.send(h.lobbies, lobbyID, message, resync); dropped > 0 {
+		h.dropped[lobbyID] += uint64(dropped)
+	}
+}
+
+// send delivers a message to the clients of a lobby or user and returns how many of them missed it.
+// A client whose queue stays full for longer than the slow client timeout is evicted:
+// its queue is replaced by the resync message and closed. The caller must hold the lock.
+func (h *Hub) send(streams map[uint]map[Client]uint, key uint, message, resync Message) int {
+	dropped := 0
+	now := time.Now()
+	for client := range streams[key] {
+		// Use a non-blocking send to prevent a slow client from blocking the hub.
+		select {
+		case client <- message:
+			delete(h.fullSince, client)
+			continue
+		default:
+		}
+
+		dropped++
+		since, ok := h.fullSince[client]
+		if !ok {
+			h.fullSince[client] = now
+			continue
+		}
+		if now.Sub(since) >= h.options.SlowClientTimeout {
+			h.evict(streams, key, client, resync)
+		}
+	}
+	return dropped
+}
+
+// evict disconnects a client that stopped reading, leaving it a resync event as the last message.
+// The caller must hold the lock.
+func (h *Hub) evict(streams map[uint]map[Client]uint, key uint, client Client, resync Message) {
+	for len(client) > 0 {
+		select {
+		case <-client:
+		default:
+		}
+	}
+	select {
+	case client <- resync:
+	default:
+	}
+	h.remove(streams, key, client)
+	h.evicted++
+}
+
+// remove closes a client and drops it from its lobby or user. The caller must hold the lock.
+func (h *Hub) remove(streams map[uint]map[Client]uint, key uint, client Client) {
+	close(client)
+
+	delete(streams[key], client)
+	if len(streams[key]) == 0 {
+		delete(streams, key)
+	}
+	delete(h.fullSince, client)
+}
+
+// resyncEvent encodes the event telling a client to reload its state.
+func resyncEvent(lobbyID *uint, lastID uint64) []byte {
+	data, _ := json.Marshal(Event{
+		ID:        lastID,
+		Version:   EventVersion,
+		Type:      EventResync,
+		Timestamp: time.Now().UTC(),
+		LobbyID:   lobbyID,
+		Payload:   ResyncPayload{LastEventID: lastID},
+	})
+	return data
+}
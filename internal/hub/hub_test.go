@@ -0,0 +1,208 @@
+package hub
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// decodeEvent decodes a delivered message, failing the test when it is not an event.
+func decodeEvent(t *testing.T, message Message) Event {
+	t.Helper()
+
+	var event Event
+	if err := json.Unmarshal(message.Data, &event); err != nil {
+		t.Fatalf("decode message %d: %v", message.ID, err)
+	}
+	return event
+}
+
+func TestResume(t *testing.T) {
+	const lobbyID = 1
+
+	tests := []struct {
+		name        string
+		published   int
+		lastEventID uint64
+		wantIDs     []uint64 // Replayed event IDs, nil when nothing is missed
+		wantResync  bool
+	}{
+		{name: "nothing published", published: 0, lastEventID: 0},
+		{name: "up to date", published: 5, lastEventID: 5},
+		{name: "missed some", published: 5, lastEventID: 3, wantIDs: []uint64{4, 5}},
+		{name: "missed all", published: 3, lastEventID: 0, wantIDs: []uint64{1, 2, 3}},
+		{name: "oldest buffered", published: lobbyHistorySize + 10, lastEventID: 10, wantIDs: idRange(11, lobbyHistorySize+10)},
+		{name: "no longer buffered", published: lobbyHistorySize + 10, lastEventID: 9, wantResync: true},
+		{name: "ID from before a restart", published: 5, lastEventID: 42, wantResync: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub()
+			for i := 0; i < tt.published; i++ {
+				h.Broadcast(lobbyID, Event{Type: "test", Payload: i})
+			}
+
+			client := h.NewClient()
+			missed := h.Resume(lobbyID, 7, client, tt.lastEventID)
+
+			if tt.wantResync {
+				if len(missed) != 1 {
+					t.Fatalf("got %d messages, want a single resync", len(missed))
+				}
+				event := decodeEvent(t, missed[0])
+				if event.Type != EventResync {
+					t.Fatalf("got %q event, want %q", event.Type, EventResync)
+				}
+				if missed[0].ID != uint64(tt.published) {
+					t.Errorf("resync ID = %d, want %d", missed[0].ID, tt.published)
+				}
+				return
+			}
+
+			if len(missed) != len(tt.wantIDs) {
+				t.Fatalf("got %d missed messages, want %d", len(missed), len(tt.wantIDs))
+			}
+			for i, message := range missed {
+				event := decodeEvent(t, message)
+				if message.ID != tt.wantIDs[i] || event.ID != tt.wantIDs[i] {
+					t.Errorf("message %d has ID %d (event %d), want %d", i, message.ID, event.ID, tt.wantIDs[i])
+				}
+				if event.LobbyID == nil || *event.LobbyID != lobbyID {
+					t.Errorf("message %d has lobby %v, want %d", i, event.LobbyID, lobbyID)
+				}
+			}
+
+			// The resumed client is subscribed and receives the next event
+			h.Broadcast(lobbyID, Event{Type: "test"})
+			select {
+			case message := <-client:
+				if message.ID != uint64(tt.published)+1 {
+					t.Errorf("next event ID = %d, want %d", message.ID, tt.published+1)
+				}
+			default:
+				t.Error("resumed client did not receive the next event")
+			}
+		})
+	}
+}
+
+func TestSlowClientEviction(t *testing.T) {
+	const lobbyID = 1
+
+	tests := []struct {
+		name        string
+		timeout     time.Duration
+		read        bool // Whether the client drains its queue after every event
+		published   int
+		wantEvicted bool
+		wantDropped uint64
+	}{
+		{name: "reading client", timeout: time.Nanosecond, read: true, published: 5},
+		{name: "full client within the timeout", timeout: time.Hour, published: 5, wantDropped: 4},
+		{name: "full client past the timeout", timeout: time.Nanosecond, published: 3, wantEvicted: true, wantDropped: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub()
+			h.Configure(Options{ClientBuffer: 1, SlowClientTimeout: tt.timeout})
+
+			client := h.NewClient()
+			h.Subscribe(lobbyID, 7, client)
+			for i := 0; i < tt.published; i++ {
+				h.Broadcast(lobbyID, Event{Type: "test", Payload: i})
+				if tt.read {
+					<-client
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			stats := h.Stats()
+			if stats.DroppedLobby[lobbyID] != tt.wantDropped {
+				t.Errorf("dropped = %d, want %d", stats.DroppedLobby[lobbyID], tt.wantDropped)
+			}
+
+			if !tt.wantEvicted {
+				if stats.Evicted != 0 || stats.LobbyClients != 1 {
+					t.Errorf("evicted = %d with %d clients left, want the client kept", stats.Evicted, stats.LobbyClients)
+				}
+				return
+			}
+
+			if stats.Evicted != 1 || stats.LobbyClients != 0 {
+				t.Fatalf("evicted = %d with %d clients left, want the client evicted", stats.Evicted, stats.LobbyClients)
+			}
+
+			// The queue is replaced by a resync telling the client to reload after the last event
+			message, ok := <-client
+			if !ok {
+				t.Fatal("evicted client got no resync event")
+			}
+			if event := decodeEvent(t, message); event.Type != EventResync {
+				t.Errorf("got %q event, want %q", event.Type, EventResync)
+			}
+			if message.ID != uint64(tt.published) {
+				t.Errorf("resync ID = %d, want %d", message.ID, tt.published)
+			}
+			if _, ok := <-client; ok {
+				t.Error("evicted client was not closed")
+			}
+		})
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	const lobbyID, kickedID, memberID = 1, 7, 8
+
+	h := NewHub()
+	kicked := []Client{h.NewClient(), h.NewClient()} // E.g. an SSE stream and a WebSocket
+	for _, client := range kicked {
+		h.Subscribe(lobbyID, kickedID, client)
+	}
+	member := h.NewClient()
+	h.Subscribe(lobbyID, memberID, member)
+	other := h.NewClient()
+	h.Subscribe(lobbyID+1, kickedID, other)
+
+	h.Broadcast(lobbyID, Event{Type: "kicked"})
+	h.Disconnect(lobbyID, kickedID)
+	h.Broadcast(lobbyID, Event{Type: "after"})
+
+	// Events queued before the disconnect are still delivered, then the client is closed
+	for i, client := range kicked {
+		if message, ok := <-client; !ok || decodeEvent(t, message).Type != "kicked" {
+			t.Errorf("kicked client %d did not get the queued event", i)
+		}
+		if _, ok := <-client; ok {
+			t.Errorf("kicked client %d was not closed", i)
+		}
+	}
+
+	for _, want := range []EventType{"kicked", "after"} {
+		select {
+		case message := <-member:
+			if event := decodeEvent(t, message); event.Type != want {
+				t.Errorf("member got %q event, want %q", event.Type, want)
+			}
+		default:
+			t.Errorf("member did not get the %q event", want)
+		}
+	}
+
+	if stats := h.Stats(); stats.LobbyClients != 2 {
+		t.Errorf("got %d lobby clients, want the member and the other lobby's client", stats.LobbyClients)
+	}
+
+	// Unsubscribing a disconnected client is a no-op
+	h.Unsubscribe(lobbyID, kicked[0])
+}
+
+// idRange returns the IDs from first to last.
+func idRange(first, last uint64) []uint64 {
+	ids := make([]uint64, 0, last-first+1)
+	for id := first; id <= last; id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
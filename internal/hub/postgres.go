@@ -0,0 +1,109 @@
+package hub
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// postgresChannel is the LISTEN/NOTIFY channel events travel on.
+	postgresChannel = "playmatch_hub"
+
+	// postgresPayloadLimit keeps notifications under the 8000 byte limit of NOTIFY.
+	// Larger events are stored in a table and only their row ID is notified.
+	postgresPayloadLimit = 7900
+
+	// postgresPayloadTTL is how long stored events are kept for slow listeners.
+	postgresPayloadTTL = time.Minute
+
+	// postgresReconnectDelay is how long to wait before listening again after the connection dropped.
+	postgresReconnectDelay = 2 * time.Second
+
+	// postgresStoredPrefix marks a notification that carries the row ID of a stored event.
+	postgresStoredPrefix = "#"
+)
+
+// PostgresBackend fans events out to all server instances with Postgres LISTEN/NOTIFY.
+// Events published while an instance is reconnecting are lost for its clients, who get a resync on their next reconnect.
+type PostgresBackend struct {
+	dsn string
+	db  *sql.DB
+}
+
+// NewPostgresBackend creates a backend that notifies through db and listens on a dedicated connection to dsn.
+func NewPostgresBackend(dsn string, db *sql.DB) (*PostgresBackend, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS hub_event_payloads (
+		id BIGSERIAL PRIMARY KEY,
+		payload TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("create hub payload table: %w", err)
+	}
+	return &PostgresBackend{dsn: dsn, db: db}, nil
+}
+
+// Publish notifies all listening instances of the event.
+func (b *PostgresBackend) Publish(data []byte) error {
+	payload := string(data)
+	if len(data) > postgresPayloadLimit {
+		var id int64
+		if err := b.db.QueryRow("INSERT INTO hub_event_payloads (payload) VALUES ($1) RETURNING id", payload).Scan(&id); err != nil {
+			return err
+		}
+		payload = postgresStoredPrefix + strconv.FormatInt(id, 10)
+
+		b.db.Exec("DELETE FROM hub_event_payloads WHERE created_at < $1", time.Now().Add(-postgresPayloadTTL))
+	}
+
+	_, err := b.db.Exec("SELECT pg_notify($1, $2)", postgresChannel, payload)
+	return err
+}
+
+// Listen starts delivering notifications in the background, reconnecting whenever the connection drops.
+func (b *PostgresBackend) Listen(deliver func(data []byte)) {
+	go func() {
+		for {
+			if err := b.listen(context.Background(), deliver); err != nil {
+				log.Printf("hub: postgres listener stopped: %v", err)
+			}
+			time.Sleep(postgresReconnectDelay)
+		}
+	}()
+}
+
+// listen delivers notifications until the connection fails.
+func (b *PostgresBackend) listen(ctx context.Context, deliver func(data []byte)) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	if _, err := conn.Exec(ctx, "LISTEN "+postgresChannel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		payload := notification.Payload
+		if id, ok := strings.CutPrefix(payload, postgresStoredPrefix); ok {
+			if err := b.db.QueryRow("SELECT payload FROM hub_event_payloads WHERE id = $1", id).Scan(&payload); err != nil {
+				log.Printf("hub: failed to load stored event %s: %v", id, err)
+				continue
+			}
+		}
+		deliver([]byte(payload))
+	}
+}
@@ -0,0 +1,115 @@
+package hub
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// postgresTestHub starts a hub on a Postgres backend for the database named by TEST_DATABASE_URL.
+// The test is skipped when the variable is not set.
+func postgresTestHub(t *testing.T) *Hub {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	backend, err := NewPostgresBackend(dsn, db)
+	if err != nil {
+		t.Fatalf("create backend: %v", err)
+	}
+	h := NewHub()
+	h.UseBackend(backend)
+	return h
+}
+
+// receive waits for the next message of a client.
+func receive(t *testing.T, client Client, timeout time.Duration) (Message, bool) {
+	t.Helper()
+
+	select {
+	case message, ok := <-client:
+		if !ok {
+			t.Fatal("client was closed")
+		}
+		return message, true
+	case <-time.After(timeout):
+		return Message{}, false
+	}
+}
+
+func TestPostgresBackendAcrossHubs(t *testing.T) {
+	hubA := postgresTestHub(t)
+	hubB := postgresTestHub(t)
+
+	// A lobby of its own, so other instances listening on the channel do not interfere
+	lobbyID := uint(time.Now().UnixNano() % 1_000_000_000)
+	client := hubB.NewClient()
+	hubB.Subscribe(lobbyID, 7, client)
+
+	// The listeners connect in the background, so wait until an event makes it through
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		hubA.Broadcast(lobbyID, Event{Type: "probe"})
+		if _, ok := receive(t, client, 200*time.Millisecond); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("hub B never received an event from hub A")
+		}
+	}
+	for len(client) > 0 {
+		<-client
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{name: "notification payload", payload: "hello"},
+		{name: "stored payload", payload: strings.Repeat("x", postgresPayloadLimit+100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hubA.Broadcast(lobbyID, Event{Type: "test", Payload: tt.payload})
+
+			for {
+				message, ok := receive(t, client, 5*time.Second)
+				if !ok {
+					t.Fatal("event from hub A did not reach hub B")
+				}
+
+				var payload string
+				event := Event{Payload: &payload}
+				if err := json.Unmarshal(message.Data, &event); err != nil {
+					t.Fatalf("decode event: %v", err)
+				}
+				// Probes published while waiting for the listener may still arrive
+				if event.Type == "probe" {
+					continue
+				}
+				if event.Type != "test" || payload != tt.payload {
+					t.Fatalf("got %q event with a %d byte payload, want the %d byte test payload", event.Type, len(payload), len(tt.payload))
+				}
+				if event.LobbyID == nil || *event.LobbyID != lobbyID {
+					t.Errorf("event lobby = %v, want %d", event.LobbyID, lobbyID)
+				}
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// BlockedWord is a word the chat filter masks or rejects.
+type BlockedWord struct {
+	ID        uint   `gorm:"primarykey"`
+	Word      string `gorm:"size:100;not null;uniqueIndex:idx_blocked_word_language"`           // Stored in lower case
+	Language  string `gorm:"size:10;not null;default:'';uniqueIndex:idx_blocked_word_language"` // Empty applies to every chat
+	CreatedAt time.Time
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Conversation is a direct message thread between two users.
+// UserLowID is always the smaller of the two user IDs so each pair has a single conversation.
+type Conversation struct {
+	ID            uint       `gorm:"primarykey"`
+	UserLowID     uint       `gorm:"not null;uniqueIndex:idx_conversation_pair"`
+	UserHighID    uint       `gorm:"not null;uniqueIndex:idx_conversation_pair"`
+	LastMessageAt *time.Time `gorm:"index"`
+	CreatedAt     time.Time
+
+	Members []ConversationMember `gorm:"foreignKey:ConversationID"`
+}
+
+// ConversationMember tracks how far a user has read a conversation.
+// The primary key is a composite of (ConversationID, UserID).
+type ConversationMember struct {
+	ConversationID    uint  `gorm:"primaryKey"`
+	UserID            uint  `gorm:"primaryKey;index"`
+	LastReadMessageID *uint // Latest message the user has read, nil when nothing was read yet
+	LastReadAt        *time.Time
+
+	User User `gorm:"foreignKey:UserID"`
+}
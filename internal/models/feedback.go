@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// EndorsementBadge is a quick compliment a participant can give a teammate after a session.
+type EndorsementBadge string
+
+const (
+	BadgeGoodComms EndorsementBadge = "good_comms"
+	BadgeCarried   EndorsementBadge = "carried"
+	BadgeFriendly  EndorsementBadge = "friendly"
+)
+
+// Endorsement is a badge one participant of a lobby session gave another.
+// A user can give each badge to each teammate once per session.
+type Endorsement struct {
+	ID         uint             `gorm:"primarykey"`
+	SessionID  uint             `gorm:"not null;uniqueIndex:idx_endorsement"`
+	FromUserID uint             `gorm:"not null;uniqueIndex:idx_endorsement"`
+	ToUserID   uint             `gorm:"not null;uniqueIndex:idx_endorsement;index"`
+	Badge      EndorsementBadge `gorm:"type:varchar(30);not null;uniqueIndex:idx_endorsement"`
+	CreatedAt  time.Time
+}
+
+// ReportReason categorizes a report.
+type ReportReason string
+
+const (
+	ReportReasonToxicity   ReportReason = "toxicity"
+	ReportReasonHarassment ReportReason = "harassment"
+	ReportReasonSpam       ReportReason = "spam"
+	ReportReasonOffensive  ReportReason = "offensive_content" // Offensive nickname, lobby description or message
+	ReportReasonCheating   ReportReason = "cheating"
+	ReportReasonGriefing   ReportReason = "griefing"
+	ReportReasonNoShow     ReportReason = "no_show"
+	ReportReasonOther      ReportReason = "other"
+)
+
+// ReportTarget defines what a report is about.
+type ReportTarget string
+
+const (
+	ReportTargetUser    ReportTarget = "user"
+	ReportTargetMessage ReportTarget = "message"
+	ReportTargetLobby   ReportTarget = "lobby"
+)
+
+// ReportStatus is the state of a report in the moderation queue.
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusInReview  ReportStatus = "in_review" // Assigned to a moderator
+	ReportStatusResolved  ReportStatus = "resolved"  // Action was taken
+	ReportStatusDismissed ReportStatus = "dismissed"
+)
+
+// Report is a complaint about a user, one of their chat messages or a lobby they host.
+// Reports filed after a lobby session against a teammate carry the session; a user can report each teammate once per session.
+type Report struct {
+	ID             uint         `gorm:"primarykey"`
+	SessionID      *uint        `gorm:"uniqueIndex:idx_report"`
+	ReporterID     uint         `gorm:"not null;uniqueIndex:idx_report"`
+	ReportedUserID uint         `gorm:"not null;uniqueIndex:idx_report;index"` // The user, the message author or the lobby host
+	TargetType     ReportTarget `gorm:"size:20;not null;default:'user'"`
+	MessageID      *uint        `gorm:"index"` // Set for message reports
+	LobbyID        *uint        `gorm:"index"` // Set for lobby reports
+	Reason         ReportReason `gorm:"type:varchar(30);not null"`
+	Comment        string
+	Snapshot       string `gorm:"type:jsonb"` // JSON-encoded content of the target and surrounding chat at the time of the report
+
+	// Moderation
+	Status       ReportStatus `gorm:"size:20;not null;default:'open';index"`
+	AssigneeID   *uint        `gorm:"index"`
+	ResolvedByID *uint
+	ResolvedAt   *time.Time
+	Resolution   string // Moderator's note
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	Reporter     User  `gorm:"foreignKey:ReporterID"`
+	ReportedUser User  `gorm:"foreignKey:ReportedUserID"`
+	Assignee     *User `gorm:"foreignKey:AssigneeID"`
+}
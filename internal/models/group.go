@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GroupRole defines the role of a member inside a group.
+type GroupRole string
+
+const (
+	// GroupRoleOwner has full control over the group. Every group has exactly one owner.
+	GroupRoleOwner GroupRole = "owner"
+
+	// GroupRoleOfficer can manage members, favourite games, sessions and group lobbies.
+	GroupRoleOfficer GroupRole = "officer"
+
+	// GroupRoleMember can chat, join group-only lobbies and see the group's plans.
+	GroupRoleMember GroupRole = "member"
+)
+
+// Group represents a persistent squad or clan that outlives single lobbies.
+type Group struct {
+	gorm.Model
+	Name          string `gorm:"size:100;unique;not null"`
+	Description   string
+	OwnerID       uint    `gorm:"not null"`
+	IsOpen        bool    `gorm:"not null;default:false"` // Anyone can join an open group without being added
+	FavoriteGames []*Game `gorm:"many2many:group_favorite_games;"`
+
+	Owner   User          `gorm:"foreignKey:OwnerID"`
+	Members []GroupMember `gorm:"foreignKey:GroupID"`
+}
+
+// GroupMember represents the membership of a user in a group.
+// The primary key is a composite of (GroupID, UserID) to ensure uniqueness.
+type GroupMember struct {
+	GroupID   uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"primaryKey;index"`
+	Role      GroupRole `gorm:"type:varchar(20);not null;default:'member'"`
+	CreatedAt time.Time // When the user joined the group
+	UpdatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// GroupSession is a play session a group has scheduled ahead of time.
+type GroupSession struct {
+	ID          uint      `gorm:"primarykey"`
+	GroupID     uint      `gorm:"not null;index"`
+	GameID      uint      `gorm:"not null"`
+	CreatedByID uint      `gorm:"not null"`
+	Title       string    `gorm:"size:100;not null"`
+	StartsAt    time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Game Game `gorm:"foreignKey:GameID"`
+}
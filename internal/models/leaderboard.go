@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// LeaderboardEntry is a user's position on a leaderboard in the latest snapshot.
+// Board identifies the leaderboard, e.g. "reputation", "hosted_month", "scale:3", "game:7" or "tag:2".
+type LeaderboardEntry struct {
+	ID         uint      `gorm:"primarykey"`
+	Board      string    `gorm:"size:50;not null;uniqueIndex:idx_leaderboard_user;index:idx_leaderboard_rank,priority:1"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_leaderboard_user"`
+	Rank       int       `gorm:"not null;index:idx_leaderboard_rank,priority:2"`
+	Score      float64   `gorm:"not null"`
+	ComputedAt time.Time `gorm:"not null"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
@@ -2,13 +2,31 @@ package models
 
 import "gorm.io/gorm"
 
+// LobbyRole defines the role of a member inside their current lobby.
+// The host is tracked separately by Lobby.HostID.
+type LobbyRole string
+
+const (
+	// LobbyRoleMember is the default role of anyone who joins a lobby.
+	LobbyRoleMember LobbyRole = "member"
+
+	// LobbyRoleCoHost can edit the lobby and kick regular members on behalf of the host.
+	LobbyRoleCoHost LobbyRole = "co_host"
+)
+
 // Lobby represents a game lobby where users can gather.
 type Lobby struct {
 	gorm.Model
-	GameID      uint   `gorm:"not null"`
-	HostID      uint   `gorm:"not null"`
-	Description string
-	MaxPlayers  int `gorm:"not null;default:5"`
+	GameID          uint `gorm:"not null"`
+	HostID          uint `gorm:"not null"`
+	Description     string
+	MaxPlayers      int     `gorm:"not null;default:5"`
+	Region          string  `gorm:"size:20;index"`      // Optional, e.g. "eu", empty means any region
+	Language        string  `gorm:"size:10"`            // Optional, e.g. "en", empty means any language
+	GroupID         *uint   `gorm:"index"`              // Set for group-only lobbies that only group members can join
+	MinReputation   float64 `gorm:"not null;default:0"` // Users with a lower reputation score cannot join
+	SlowModeSeconds int     `gorm:"not null;default:0"` // Minimum seconds between chat messages of a regular member, 0 disables slow mode
+	Announcement    string  `gorm:"size:500"`           // Set by the host, shown to everyone who opens the lobby
 
 	Game    Game   `gorm:"foreignKey:GameID"`
 	Host    User   `gorm:"foreignKey:HostID"`
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// LobbyBan prevents a user from rejoining a lobby they were kicked from.
+// The primary key is a composite of (LobbyID, UserID), so a user has at most one ban per lobby.
+type LobbyBan struct {
+	LobbyID    uint `gorm:"primaryKey"`
+	UserID     uint `gorm:"primaryKey"`
+	BannedByID uint `gorm:"not null"`
+	Reason     string
+	ExpiresAt  *time.Time // Nil means the ban lasts for the lifetime of the lobby
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	User     User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	BannedBy User `gorm:"foreignKey:BannedByID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// IsActive reports whether the ban is still in effect at the given time.
+func (b LobbyBan) IsActive(now time.Time) bool {
+	return b.ExpiresAt == nil || b.ExpiresAt.After(now)
+}
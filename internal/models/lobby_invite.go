@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// InviteStatus defines the state of a lobby invite.
+type InviteStatus string
+
+const (
+	InviteStatusPending  InviteStatus = "pending"
+	InviteStatusAccepted InviteStatus = "accepted"
+	InviteStatusDeclined InviteStatus = "declined"
+)
+
+// LobbyInvite represents an invitation for a user to join a lobby.
+type LobbyInvite struct {
+	ID        uint         `gorm:"primarykey"`
+	LobbyID   uint         `gorm:"not null;index"`
+	InviterID uint         `gorm:"not null"`
+	InviteeID uint         `gorm:"not null;index"`
+	Status    InviteStatus `gorm:"type:varchar(20);not null;default:'pending'"`
+	ExpiresAt time.Time    `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Lobby   Lobby `gorm:"foreignKey:LobbyID"`
+	Inviter User  `gorm:"foreignKey:InviterID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Invitee User  `gorm:"foreignKey:InviteeID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
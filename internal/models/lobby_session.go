@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// LobbySession records the lifetime of a lobby: which game was played, when, and by whom.
+// Unlike Lobby, sessions are kept after the lobby is deleted.
+type LobbySession struct {
+	ID        uint       `gorm:"primarykey"`
+	LobbyID   uint       `gorm:"not null;index"`
+	GameID    uint       `gorm:"not null;index"`
+	HostID    uint       `gorm:"not null"` // The host who created the lobby
+	StartedAt time.Time  `gorm:"not null"`
+	EndedAt   *time.Time // Nil while the lobby still exists
+
+	Game         Game                      `gorm:"foreignKey:GameID"`
+	Participants []LobbySessionParticipant `gorm:"foreignKey:SessionID"`
+}
+
+// LobbySessionParticipant records one stay of a user in a session.
+// A user who leaves and rejoins the lobby has several participant records.
+type LobbySessionParticipant struct {
+	ID        uint       `gorm:"primarykey"`
+	SessionID uint       `gorm:"not null;index"`
+	UserID    uint       `gorm:"not null;index"`
+	JoinedAt  time.Time  `gorm:"not null"`
+	LeftAt    *time.Time // Nil while the user is still in the lobby
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
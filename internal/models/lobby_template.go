@@ -0,0 +1,18 @@
+package models
+
+import "gorm.io/gorm"
+
+// LobbyTemplate stores lobby settings a user can reuse to create the same lobby again.
+type LobbyTemplate struct {
+	gorm.Model
+	OwnerID       uint   `gorm:"not null;index"`
+	Name          string `gorm:"size:100;not null"`
+	GameID        uint   `gorm:"not null"`
+	Description   string
+	MaxPlayers    int     `gorm:"not null;default:5"`
+	Region        string  `gorm:"size:20"`
+	Language      string  `gorm:"size:10"`
+	MinReputation float64 `gorm:"not null;default:0"`
+
+	Game Game `gorm:"foreignKey:GameID"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// WaitlistStatus defines the state of a waitlist entry.
+type WaitlistStatus string
+
+const (
+	// WaitlistStatusWaiting means the user is queued for a free slot.
+	WaitlistStatusWaiting WaitlistStatus = "waiting"
+
+	// WaitlistStatusOffered means a slot is reserved for the user until OfferExpiresAt.
+	WaitlistStatusOffered WaitlistStatus = "offered"
+)
+
+// LobbyWaitlistEntry represents a user queued for a slot in a full lobby.
+// Being on a waitlist does not count as being in the lobby, so CurrentLobbyID is not touched.
+type LobbyWaitlistEntry struct {
+	ID             uint           `gorm:"primarykey"`
+	LobbyID        uint           `gorm:"not null;uniqueIndex:idx_waitlist_lobby_user"`
+	UserID         uint           `gorm:"not null;uniqueIndex:idx_waitlist_lobby_user;index"`
+	Status         WaitlistStatus `gorm:"type:varchar(20);not null;default:'waiting'"`
+	OfferExpiresAt *time.Time
+	CreatedAt      time.Time // Queue order
+	UpdatedAt      time.Time
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// MatchmakingTicket represents a user queued for a group in one game.
+// A user who queues for several games has one ticket per game; all of them
+// are removed as soon as the user is placed into a lobby.
+type MatchmakingTicket struct {
+	ID        uint   `gorm:"primarykey"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_ticket_user_game"`
+	GameID    uint   `gorm:"not null;uniqueIndex:idx_ticket_user_game;index"`
+	PartySize int    `gorm:"not null"` // Desired lobby size, matched against Lobby.MaxPlayers
+	Region    string `gorm:"size:20"`  // Empty means any region
+	Language  string `gorm:"size:10"`  // Empty means any language
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Game Game `gorm:"foreignKey:GameID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type MessageType string
 
@@ -9,13 +13,55 @@ const (
 	MessageTypeSystem MessageType = "system"
 )
 
-// Message represents a chat message within a lobby.
+// Message represents a chat message within a lobby, a group or a direct conversation.
+// Exactly one of LobbyID, GroupID and ConversationID is set.
 type Message struct {
 	gorm.Model
-	LobbyID uint   `gorm:"not null;index"`
-	UserID  *uint  // Nullable for system messages
-	Type    MessageType `gorm:"size:50;not null;default:'text'"`
-	Content string `gorm:"not null"`
+	LobbyID        *uint       `gorm:"index"` // Set for lobby chat messages
+	GroupID        *uint       `gorm:"index"` // Set for group chat messages
+	ConversationID *uint       `gorm:"index"` // Set for direct messages
+	UserID         *uint       // Nullable for system messages
+	Type           MessageType `gorm:"size:50;not null;default:'text'"`
+	Content        string      `gorm:"not null"`
+	EditedAt       *time.Time  // Set when the author edited the message
+	RemovedByID    *uint       // Set when a moderator removed the message; removed messages are soft-deleted
+	ReplyToID      *uint       `gorm:"index"` // Set when the message replies to an earlier message of the same chat
+	PinnedAt       *time.Time  `gorm:"index"` // Set while the message is pinned in its lobby chat
+	PinnedByID     *uint
+
+	User      User              `gorm:"foreignKey:UserID"` // Belongs to User
+	ReplyTo   *Message          `gorm:"foreignKey:ReplyToID"`
+	Reactions []MessageReaction `gorm:"foreignKey:MessageID"`
+	Mentions  []MessageMention  `gorm:"foreignKey:MessageID"`
+}
+
+// MessageEdit keeps the content a message had before one of its edits.
+type MessageEdit struct {
+	ID              uint   `gorm:"primarykey"`
+	MessageID       uint   `gorm:"not null;index"`
+	PreviousContent string `gorm:"not null"`
+	CreatedAt       time.Time
+}
+
+// MessageReaction is an emoji reaction of a user to a message.
+// The primary key is a composite of (MessageID, UserID, Emoji) so a user can add each emoji once.
+type MessageReaction struct {
+	MessageID uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"primaryKey"`
+	Emoji     string `gorm:"primaryKey;size:32"`
+	CreatedAt time.Time
+}
+
+// MessageMention is an @nickname mention of a user inside a message.
+// Offset and Length are counted in characters of the message content.
+type MessageMention struct {
+	ID        uint       `gorm:"primarykey"`
+	MessageID uint       `gorm:"not null;index"`
+	UserID    uint       `gorm:"not null;index"`
+	Offset    int        `gorm:"not null"`
+	Length    int        `gorm:"not null"`
+	ReadAt    *time.Time // Set once the mentioned user has seen the mention
+	CreatedAt time.Time
 
-	User User `gorm:"foreignKey:UserID"` // Belongs to User
+	User User `gorm:"foreignKey:UserID"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// NotificationType defines what a notification is about.
+type NotificationType string
+
+const (
+	NotificationFriendRequest  NotificationType = "friend_request"
+	NotificationFriendAccepted NotificationType = "friend_accepted"
+	NotificationLobbyInvite    NotificationType = "lobby_invite"
+	NotificationLobbyKick      NotificationType = "lobby_kick"
+	NotificationReportClosed   NotificationType = "report_closed"
+)
+
+// Notification is an entry in a user's notification inbox.
+// Payload holds the JSON-encoded details, whose shape depends on Type.
+type Notification struct {
+	ID        uint             `gorm:"primarykey"`
+	UserID    uint             `gorm:"not null;index"`
+	Type      NotificationType `gorm:"size:50;not null"`
+	Payload   string           `gorm:"type:jsonb;not null"`
+	ReadAt    *time.Time       `gorm:"index"` // Set once the user has read the notification
+	CreatedAt time.Time
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RatingScaleKind separates game skill from behaviour.
+type RatingScaleKind string
+
+const (
+	// RatingScaleHard is a genre-dependent skill scale, e.g. "Aim" for shooters.
+	RatingScaleHard RatingScaleKind = "hard"
+
+	// RatingScaleSoft is a behavioural scale, e.g. "Communication" or "Friendliness".
+	RatingScaleSoft RatingScaleKind = "soft"
+)
+
+// RatingScale is an admin-defined scale users rate each other on.
+// A scale with a TagID only applies to games carrying that tag.
+type RatingScale struct {
+	gorm.Model
+	Name        string `gorm:"size:100;unique;not null"`
+	Description string
+	Kind        RatingScaleKind `gorm:"type:varchar(10);not null"`
+	TagID       *uint           `gorm:"index"`
+
+	Tag *Tag `gorm:"foreignKey:TagID"`
+}
+
+// UserRating is the score one user gave another on a scale. There is at most one per rater, ratee and scale.
+type UserRating struct {
+	ID        uint `gorm:"primarykey"`
+	RaterID   uint `gorm:"not null;uniqueIndex:idx_user_rating"`
+	RateeID   uint `gorm:"not null;uniqueIndex:idx_user_rating;index"`
+	ScaleID   uint `gorm:"not null;uniqueIndex:idx_user_rating"`
+	Score     int  `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Rater User        `gorm:"foreignKey:RaterID;constraint:OnDelete:CASCADE;"`
+	Ratee User        `gorm:"foreignKey:RateeID;constraint:OnDelete:CASCADE;"`
+	Scale RatingScale `gorm:"foreignKey:ScaleID;constraint:OnDelete:CASCADE;"`
+}
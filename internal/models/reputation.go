@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ReputationEventType defines what a user earned reputation for.
+type ReputationEventType string
+
+const (
+	// ReputationLobbyStay is credited for joining an existing lobby and staying in it for a minimum time.
+	ReputationLobbyStay ReputationEventType = "lobby_stay"
+
+	// ReputationSessionCompleted is credited for staying in a lobby until its session ended.
+	ReputationSessionCompleted ReputationEventType = "session_completed"
+
+	// ReputationPositiveRating is credited the first time a teammate rates the user positively.
+	ReputationPositiveRating ReputationEventType = "positive_rating"
+)
+
+// ReputationEvent is an entry of the reputation ledger. Points decay with the age of the entry.
+type ReputationEvent struct {
+	ID        uint                `gorm:"primarykey"`
+	UserID    uint                `gorm:"not null;index"`
+	Type      ReputationEventType `gorm:"type:varchar(30);not null"`
+	Points    float64             `gorm:"not null"`
+	SourceKey string              `gorm:"size:100;not null;uniqueIndex"` // Identifies what was credited so it is only credited once
+	CreatedAt time.Time           `gorm:"index"`
+}
@@ -1,6 +1,38 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DMPolicy defines who can start a direct conversation with a user.
+type DMPolicy string
+
+const (
+	DMPolicyFriends  DMPolicy = "friends"  // Only accepted friends
+	DMPolicyEveryone DMPolicy = "everyone" // Any user who is not blocked
+)
+
+// PresenceStatus describes what a user is doing right now.
+type PresenceStatus string
+
+const (
+	PresenceOnline  PresenceStatus = "online"
+	PresenceInLobby PresenceStatus = "in_lobby"
+	PresenceInGame  PresenceStatus = "in_game" // In a lobby where every member is ready
+	PresenceAway    PresenceStatus = "away"    // Connected, but inactive for a while
+	PresenceOffline PresenceStatus = "offline"
+)
+
+// PresenceVisibility defines who can see a user's presence.
+type PresenceVisibility string
+
+const (
+	PresenceVisibleEveryone PresenceVisibility = "everyone"
+	PresenceVisibleFriends  PresenceVisibility = "friends" // Only accepted friends
+	PresenceVisibleNobody   PresenceVisibility = "nobody"
+)
 
 // User represents a user in the system.
 type User struct {
@@ -12,6 +44,30 @@ type User struct {
 	FavoriteGames []*Game `gorm:"many2many:user_favorite_games;"`
 
 	// A user can only be in one lobby at a time.
-	CurrentLobbyID *uint  `gorm:"index"`
-	CurrentLobby   *Lobby `gorm:"foreignKey:CurrentLobbyID"`
+	CurrentLobbyID *uint      `gorm:"index"`
+	CurrentLobby   *Lobby     `gorm:"foreignKey:CurrentLobbyID"`
+	LobbyRole      LobbyRole  `gorm:"size:20"` // Role in CurrentLobby, empty when not in a lobby
+	LobbyJoinedAt  *time.Time // When the user joined CurrentLobby, used for host succession
+	LobbyReady     bool       // Whether the user marked themselves ready in CurrentLobby
+
+	// ReputationScore caches the decayed sum of the user's reputation ledger.
+	ReputationScore float64 `gorm:"not null;default:0;index"`
+
+	DMPolicy DMPolicy `gorm:"size:20;not null;default:'friends'"`
+
+	// Presence
+	LastSeenAt         *time.Time         // Last API request or stream heartbeat
+	LastActiveAt       *time.Time         // Last API request or command, streams alone do not count
+	PresenceStatus     PresenceStatus     `gorm:"size:20;not null;default:'offline';index"` // Last status announced to friends
+	PresenceVisibility PresenceVisibility `gorm:"size:20;not null;default:'friends'"`
+
+	// Platform ban, set by moderators
+	BannedAt    *time.Time
+	BannedUntil *time.Time // Nil for a permanent ban
+	BanReason   string
+}
+
+// IsBanned reports whether the user is banned from the platform at the given time.
+func (u User) IsBanned(now time.Time) bool {
+	return u.BannedAt != nil && (u.BannedUntil == nil || u.BannedUntil.After(now))
 }
@@ -12,6 +12,9 @@ const (
 
 	// StatusAccepted means the friend request was accepted, and the users are now friends.
 	StatusAccepted FriendshipStatus = "accepted"
+
+	// StatusBlocked means FromUser blocked ToUser. It replaces any other relation between the two.
+	StatusBlocked FriendshipStatus = "blocked"
 )
 
 // UserRelation represents the relationship between two users.